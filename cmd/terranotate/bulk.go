@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/toozej/terranotate/internal/app"
+	"github.com/toozej/terranotate/internal/fixer"
+)
+
+var (
+	bulkPrefix string
+	bulkType   string
+	bulkName   string
+	bulkPath   string
+	bulkWhere  []string
+	bulkDryRun bool
+)
+
+const bulkSelectorHelp = `
+Resources are selected with --type, --name and --path globs and --where
+clauses on existing annotation values (field=glob or @prefix:field=glob).
+Use --dry-run to print a diff without writing any files. Changes are
+recorded in the fix journal and can be undone with 'terranotate fix --revert'.`
+
+var setCmd = &cobra.Command{
+	Use:   "set [terraform-file-or-dir] [field:value]...",
+	Short: "Set annotation fields on every selected resource",
+	Long: `Set annotation fields on every selected resource, replacing existing values.
+Values containing whitespace are written quoted.
+
+Example:
+  terranotate set modules/network team:platform-core --type 'aws_*'
+  terranotate set . 'description:Core network VPC'
+` + bulkSelectorHelp,
+	Args: cobra.MinimumNArgs(2),
+	Run:  runSetCommand,
+}
+
+var unsetCmd = &cobra.Command{
+	Use:   "unset [terraform-file-or-dir] [field]...",
+	Short: "Remove annotation fields from every selected resource",
+	Long: `Remove annotation fields (and any nested fields below them) from every selected resource.
+
+Example:
+  terranotate unset . legacy_id --where team=platform-*
+` + bulkSelectorHelp,
+	Args: cobra.MinimumNArgs(2),
+	Run:  runUnsetCommand,
+}
+
+var renameFieldCmd = &cobra.Command{
+	Use:   "rename-field [terraform-file-or-dir] [old-field] [new-field]",
+	Short: "Rename an annotation field on every selected resource",
+	Long: `Rename an annotation field on every selected resource, keeping its value.
+Resources that already set the new field are left unchanged and reported.
+
+Example:
+  terranotate rename-field . cost_center costcenter
+` + bulkSelectorHelp,
+	Args: cobra.ExactArgs(3),
+	Run:  runRenameFieldCommand,
+}
+
+func init() {
+	for _, c := range []*cobra.Command{setCmd, unsetCmd, renameFieldCmd} {
+		rootCmd.AddCommand(c)
+		c.Flags().StringVar(&bulkPrefix, "prefix", "@metadata", "Comment prefix holding the field")
+		c.Flags().StringVar(&bulkType, "type", "", "Only resources whose type matches this glob (e.g. 'aws_*')")
+		c.Flags().StringVar(&bulkName, "name", "", "Only resources whose name matches this glob")
+		c.Flags().StringVar(&bulkPath, "path", "", "Only files whose path matches this glob or lies under this directory")
+		c.Flags().StringArrayVar(&bulkWhere, "where", nil, "Only resources whose annotation matches field=glob or @prefix:field=glob (repeatable)")
+		c.Flags().BoolVar(&bulkDryRun, "dry-run", false, "Print a diff of the changes without writing files")
+	}
+}
+
+func runSetCommand(cmd *cobra.Command, args []string) {
+	var edits []fixer.BulkEdit
+	for _, arg := range args[1:] {
+		parts := strings.SplitN(arg, ":", 2)
+		if len(parts) != 2 {
+			fmt.Printf("Error: invalid assignment '%s' (expected field:value)\n", arg)
+			os.Exit(1)
+		}
+		edits = append(edits, fixer.BulkEdit{Op: fixer.OpSet, Prefix: bulkPrefix, Field: parts[0], Value: parts[1]})
+	}
+	runBulkEdit(args[0], edits)
+}
+
+func runUnsetCommand(cmd *cobra.Command, args []string) {
+	var edits []fixer.BulkEdit
+	for _, field := range args[1:] {
+		edits = append(edits, fixer.BulkEdit{Op: fixer.OpUnset, Prefix: bulkPrefix, Field: field})
+	}
+	runBulkEdit(args[0], edits)
+}
+
+func runRenameFieldCommand(cmd *cobra.Command, args []string) {
+	runBulkEdit(args[0], []fixer.BulkEdit{{Op: fixer.OpRename, Prefix: bulkPrefix, Field: args[1], Value: args[2]}})
+}
+
+func runBulkEdit(path string, edits []fixer.BulkEdit) {
+	selector := fixer.Selector{Type: bulkType, Name: bulkName, Path: bulkPath, Where: map[string]string{}}
+	for _, clause := range bulkWhere {
+		parts := strings.SplitN(clause, "=", 2)
+		if len(parts) != 2 {
+			fmt.Printf("Error: invalid --where clause '%s' (expected field=glob)\n", clause)
+			os.Exit(1)
+		}
+		selector.Where[parts[0]] = parts[1]
+	}
+
+	if err := app.BulkEdit(afero.NewOsFs(), path, selector, edits, bulkDryRun); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/toozej/terranotate/internal/app"
+)
+
+var (
+	coverageOutput   string
+	coverageBaseline string
+)
+
+var coverageCmd = &cobra.Command{
+	Use:   "coverage [path] [schema-file]",
+	Short: "Report annotation coverage per prefix and field",
+	Long: `Report how completely resources are annotated.
+
+For every prefix and field the schema declares, shows how many resources
+have it, and how many have a value that is not a placeholder (e.g. CHANGEME).
+Directories are scanned the same way 'validate' detects workspaces and modules.
+
+Use --output to save the report as JSON, and --baseline with a previously
+saved report to fail only when coverage drops, e.g. in CI:
+
+  terranotate coverage . schema.yaml --baseline coverage.json --output coverage.json`,
+	Args: cobra.ExactArgs(2),
+	Run:  runCoverageCommand,
+}
+
+func init() {
+	rootCmd.AddCommand(coverageCmd)
+	coverageCmd.Flags().StringVarP(&coverageOutput, "output", "o", "", "Write the coverage report as JSON to this file")
+	coverageCmd.Flags().StringVar(&coverageBaseline, "baseline", "", "Fail if coverage is lower than in this JSON coverage report")
+}
+
+func runCoverageCommand(cmd *cobra.Command, args []string) {
+	opts := app.CoverageOptions{
+		Output:   coverageOutput,
+		Baseline: coverageBaseline,
+	}
+	if err := app.Coverage(afero.NewOsFs(), args[0], args[1], opts); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
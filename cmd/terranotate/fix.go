@@ -9,7 +9,12 @@ import (
 	"github.com/toozej/terranotate/internal/app"
 )
 
-var fixRevert bool
+var (
+	fixRevert      bool
+	fixRunID       string
+	fixInteractive bool
+	fixTarget      string
+)
 
 var fixCmd = &cobra.Command{
 	Use:   "fix [terraform-file-or-dir] [schema-file]",
@@ -20,7 +25,10 @@ var fixCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(fixCmd)
-	fixCmd.Flags().BoolVar(&fixRevert, "revert", false, "Revert to backup files (restore .bak files)")
+	fixCmd.Flags().BoolVar(&fixRevert, "revert", false, "Revert the files changed by a fix run recorded in .terranotate/journal")
+	fixCmd.Flags().StringVar(&fixRunID, "run", "", "Fix run ID to revert (default: most recent run)")
+	fixCmd.Flags().BoolVarP(&fixInteractive, "interactive", "i", false, "Prompt for each missing field value instead of inserting placeholders")
+	fixCmd.Flags().StringVar(&fixTarget, "target", "", "Where to write missing annotations: comments or sidecar (default: the schema's fix.target, else comments)")
 }
 
 func runFixCommand(cmd *cobra.Command, args []string) {
@@ -28,7 +36,7 @@ func runFixCommand(cmd *cobra.Command, args []string) {
 
 	// Handle revert mode
 	if fixRevert {
-		if err := app.RevertFix(afero.NewOsFs(), path); err != nil {
+		if err := app.RevertFix(afero.NewOsFs(), path, fixRunID); err != nil {
 			fmt.Println(err)
 			os.Exit(1)
 		}
@@ -39,13 +47,14 @@ func runFixCommand(cmd *cobra.Command, args []string) {
 	if len(args) < 2 {
 		fmt.Println("Error: schema-file argument is required for fix mode")
 		fmt.Println("Usage: terranotate fix [terraform-file-or-dir] [schema-file]")
-		fmt.Println("   or: terranotate fix --revert [terraform-file-or-dir]")
+		fmt.Println("   or: terranotate fix --revert [--run <id>] [terraform-file-or-dir]")
 		os.Exit(1)
 	}
 
 	schemaFile := args[1]
 
-	if err := app.Fix(afero.NewOsFs(), path, schemaFile); err != nil {
+	opts := app.FixOptions{Interactive: fixInteractive, Target: fixTarget}
+	if err := app.FixWithOptions(afero.NewOsFs(), path, schemaFile, opts); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
@@ -9,19 +9,39 @@ import (
 	"github.com/toozej/terranotate/internal/app"
 )
 
-var generateOutput string
+var (
+	generateOutput    string
+	generateFormat    string
+	generateTemplate  string
+	generateInject    string
+	generateCheck     bool
+	generateOutputDir string
+	generateValidate  bool
+)
 
 var generateCmd = &cobra.Command{
 	Use:   "generate [path] [schema-file]",
-	Short: "Generate markdown documentation from Terraform resources and their annotations",
-	Long: `Generate markdown documentation tables from Terraform resources.
+	Short: "Generate documentation from Terraform resources and their annotations",
+	Long: `Generate documentation tables from Terraform resources.
 
-Creates a markdown document with a table per module showing:
+Creates a document with a table per resource type showing:
   - Resource type and name
   - All required metadata fields from schema
   - Actual values from resource annotations
 
-Output is written to stdout by default, or to a file with --output flag.`,
+Supported formats (--format): markdown (default), html (self-contained page
+with sortable, filterable tables), json, csv (one row per resource) and asciidoc.
+Use --template to render a custom text/template instead (see docs/advanced-usage.md).
+
+Output is written to stdout by default, or to a file with --output flag.
+Use --output-dir to write one document per module directory (detected the
+same way as 'validate' detects workspaces and modules) plus an index page.
+Use --inject README.md to replace only the section between the
+<!-- BEGIN_TERRANOTATE --> and <!-- END_TERRANOTATE --> markers (appended if
+missing), and --check to fail when the output or injected section is stale.
+Use --validate to add each resource's validation status and missing fields,
+plus annotation coverage per resource type, module and (with --output-dir) in
+the index page.`,
 	Args: cobra.ExactArgs(2),
 	Run:  runGenerateCommand,
 }
@@ -29,13 +49,27 @@ Output is written to stdout by default, or to a file with --output flag.`,
 func init() {
 	rootCmd.AddCommand(generateCmd)
 	generateCmd.Flags().StringVarP(&generateOutput, "output", "o", "", "Output file (default: stdout)")
+	generateCmd.Flags().StringVarP(&generateFormat, "format", "f", "markdown", "Output format: markdown, html, json, csv or asciidoc")
+	generateCmd.Flags().StringVarP(&generateTemplate, "template", "t", "", "Render a custom text/template file instead of a built-in format")
+	generateCmd.Flags().StringVar(&generateInject, "inject", "", "Inject documentation into a file between BEGIN_TERRANOTATE/END_TERRANOTATE markers")
+	generateCmd.Flags().BoolVar(&generateCheck, "check", false, "Exit non-zero if the --output, --inject or --output-dir files are out of date instead of writing them")
+	generateCmd.Flags().StringVar(&generateOutputDir, "output-dir", "", "Write one document per module directory plus an index page into this directory")
+	generateCmd.Flags().BoolVar(&generateValidate, "validate", false, "Add validation status, missing fields and annotation coverage to the documentation")
 }
 
 func runGenerateCommand(cmd *cobra.Command, args []string) {
 	path := args[0]
 	schemaFile := args[1]
 
-	if err := app.Generate(afero.NewOsFs(), path, schemaFile, generateOutput); err != nil {
+	opts := app.GenerateOptions{
+		Format:    generateFormat,
+		Template:  generateTemplate,
+		Inject:    generateInject,
+		Check:     generateCheck,
+		OutputDir: generateOutputDir,
+		Validate:  generateValidate,
+	}
+	if err := app.GenerateWithOptions(afero.NewOsFs(), path, schemaFile, generateOutput, opts); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/toozej/terranotate/internal/app"
+)
+
+var (
+	migrateFrom   int
+	migrateDryRun bool
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate [terraform-file-or-dir] [schema-file]",
+	Short: "Rewrite annotations to the current schema version",
+	Long: `Rewrite annotations written for an older schema version using the
+schema's migrations section (rename, move, split and map_values).
+
+Migrations newer than --from are applied in version order. Annotations that
+cannot be migrated (e.g. a rename whose target field is already set) are
+reported and left unchanged. Annotations in sidecar files are migrated there,
+and file headers in place; inherited module call and directory defaults
+annotations are reported for manual migration.
+A complete migration records the schema version as schema_version in the
+directory's .terranotate.yaml, so running it again is a no-op. Changes are
+recorded in the fix journal and can be undone with 'terranotate fix --revert'.`,
+	Args: cobra.ExactArgs(2),
+	Run:  runMigrateCommand,
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+	migrateCmd.Flags().IntVar(&migrateFrom, "from", 0, "Schema version the annotations were written for (default: the recorded version, or apply all migrations)")
+	migrateCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "Print a diff of the changes without writing files")
+}
+
+func runMigrateCommand(cmd *cobra.Command, args []string) {
+	if err := app.Migrate(afero.NewOsFs(), args[0], args[1], migrateFrom, migrateDryRun); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
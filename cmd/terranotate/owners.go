@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/toozej/terranotate/internal/app"
+)
+
+var ownersOpts app.OwnersOptions
+
+var ownersCmd = &cobra.Command{
+	Use:   "owners [terraform-file-or-dir] [schema-file]",
+	Short: "Export @metadata ownership as CODEOWNERS or an ownership map",
+	Long: `Aggregate @metadata team, owner and contact annotations per directory or file
+and write them as a CODEOWNERS file or a JSON/YAML ownership map.
+
+When the resources of a directory belong to different teams, the team owning
+the most resources wins (--strategy majority) or every team is listed
+(--strategy all). Paths are relative to the enclosing git repository.
+
+The schema's owners section sets defaults and module-level overrides:
+
+  owners:
+    field: team
+    org: my-org
+    strategy: majority
+    overrides:
+      modules/network: ["@my-org/network"]
+
+Example:
+
+  terranotate owners . schema.yaml --org my-org --output .github/CODEOWNERS`,
+	Args: cobra.RangeArgs(1, 2),
+	Run:  runOwnersCommand,
+}
+
+func init() {
+	rootCmd.AddCommand(ownersCmd)
+	ownersCmd.Flags().StringVarP(&ownersOpts.Format, "format", "f", "codeowners", "Output format: codeowners, json or yaml")
+	ownersCmd.Flags().StringVarP(&ownersOpts.Output, "output", "o", "", "Write to this file instead of stdout")
+	ownersCmd.Flags().StringVar(&ownersOpts.By, "by", "directory", "Aggregate ownership per 'directory' or 'file'")
+	ownersCmd.Flags().StringVar(&ownersOpts.Strategy, "strategy", "", "Resolve conflicting owners with 'majority' or 'all' (default: schema or majority)")
+	ownersCmd.Flags().StringVar(&ownersOpts.Org, "org", "", "GitHub organization teams belong to, e.g. team becomes @org/team")
+	ownersCmd.Flags().StringVar(&ownersOpts.Field, "field", "", "@metadata field naming the owning team (default: schema or team)")
+}
+
+func runOwnersCommand(cmd *cobra.Command, args []string) {
+	path := args[0]
+
+	schemaFile := ""
+	if len(args) > 1 {
+		schemaFile = args[1]
+	}
+
+	if err := app.Owners(afero.NewOsFs(), path, schemaFile, ownersOpts); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
@@ -9,21 +9,29 @@ import (
 	"github.com/toozej/terranotate/internal/app"
 )
 
+var parseOpts app.ParseOptions
+
 var parseCmd = &cobra.Command{
 	Use:   "parse [terraform-file]",
 	Short: "Parse and display Terraform file comments",
-	Args:  cobra.ExactArgs(1),
-	Run:   runParseCommand,
+	Long: `Parse and display the annotations of a Terraform file.
+
+Annotations inherited from the file header (# @metadata(file) ...), module calls
+and .terranotate.yaml directory defaults are listed with their source. --explain
+also shows every effective value and where it comes from.`,
+	Args: cobra.ExactArgs(1),
+	Run:  runParseCommand,
 }
 
 func init() {
 	rootCmd.AddCommand(parseCmd)
+	parseCmd.Flags().BoolVar(&parseOpts.Explain, "explain", false, "Show every effective annotation value and where it comes from")
 }
 
 func runParseCommand(cmd *cobra.Command, args []string) {
 	filename := args[0]
 
-	if err := app.Parse(afero.NewOsFs(), filename); err != nil {
+	if err := app.ParseWithOptions(afero.NewOsFs(), filename, parseOpts); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
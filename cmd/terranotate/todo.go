@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/toozej/terranotate/internal/app"
+)
+
+var todoGroupBy string
+
+var todoCmd = &cobra.Command{
+	Use:   "todo [terraform-file-or-dir] [schema-file]",
+	Short: "List placeholder annotation values that still need real values",
+	Long: `List every placeholder value (e.g. CHANGEME) still present in annotations.
+
+Placeholder values and patterns are read from the schema's placeholders
+section when a schema file is given, otherwise built-in defaults are used.
+Results are grouped by directory (default) or by @metadata owner.`,
+	Args: cobra.RangeArgs(1, 2),
+	Run:  runTodoCommand,
+}
+
+func init() {
+	rootCmd.AddCommand(todoCmd)
+	todoCmd.Flags().StringVar(&todoGroupBy, "group-by", "directory", "Group placeholders by 'directory' or 'owner'")
+}
+
+func runTodoCommand(cmd *cobra.Command, args []string) {
+	path := args[0]
+
+	schemaFile := ""
+	if len(args) > 1 {
+		schemaFile = args[1]
+	}
+
+	if err := app.Todo(afero.NewOsFs(), path, schemaFile, todoGroupBy); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
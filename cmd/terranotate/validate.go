@@ -9,6 +9,12 @@ import (
 	"github.com/toozej/terranotate/internal/app"
 )
 
+var (
+	validateBaseline       string
+	validateUpdateBaseline bool
+	validateExplain        bool
+)
+
 var validateCmd = &cobra.Command{
 	Use:   "validate [path] [schema-file]",
 	Short: "Validate Terraform files, modules, or workspaces against schema",
@@ -23,12 +29,24 @@ A workspace is detected if:
   - Multiple subdirectories or environment directories are found
   - Or if it's explicitly a large multi-module setup
 
-Otherwise, it validates as a single file or directory.`,
+Otherwise, it validates as a single file or directory.
+
+With --baseline, only violations not recorded in the baseline file fail validation.
+--update-baseline creates the baseline from the current violations if it does not
+exist yet, and otherwise removes violations that have since been fixed. A missing
+baseline file is an error without it.
+
+Resources inherit annotations from file headers, module calls and .terranotate.yaml
+directory defaults. --explain shows where an invalid inherited value comes from and
+which inherited annotations were searched for a missing field.`,
 	Args: cobra.ExactArgs(2),
 	Run:  runValidateCommand,
 }
 
 func init() {
+	validateCmd.Flags().StringVar(&validateBaseline, "baseline", "", "Baseline file of known violations (e.g. terranotate-baseline.json)")
+	validateCmd.Flags().BoolVar(&validateUpdateBaseline, "update-baseline", false, "Create the baseline file, or remove fixed violations from it")
+	validateCmd.Flags().BoolVar(&validateExplain, "explain", false, "Show where inherited annotation values come from")
 	rootCmd.AddCommand(validateCmd)
 }
 
@@ -36,7 +54,12 @@ func runValidateCommand(cmd *cobra.Command, args []string) {
 	path := args[0]
 	schemaFile := args[1]
 
-	if err := app.ValidateAuto(afero.NewOsFs(), path, schemaFile); err != nil {
+	opts := app.ValidateOptions{
+		Baseline:       validateBaseline,
+		UpdateBaseline: validateUpdateBaseline,
+		Explain:        validateExplain,
+	}
+	if err := app.ValidateAutoWithOptions(afero.NewOsFs(), path, schemaFile, opts); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
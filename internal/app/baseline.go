@@ -0,0 +1,73 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+	"github.com/toozej/terranotate/internal/validator"
+)
+
+// applyBaseline filters known violations out of result according to opts. A missing baseline
+// file is an error, unless opts.UpdateBaseline creates it from the current violations, which
+// then all pass.
+func applyBaseline(fs afero.Fs, result validator.ValidationResult, opts ValidateOptions) (validator.ValidationResult, error) {
+	if opts.Baseline == "" {
+		return result, nil
+	}
+
+	// Paths in the baseline are relative to its directory so it can be committed
+	root, err := filepath.Abs(filepath.Dir(opts.Baseline))
+	if err != nil {
+		return result, fmt.Errorf("failed to resolve baseline directory: %w", err)
+	}
+
+	data, err := afero.ReadFile(fs, opts.Baseline)
+	if os.IsNotExist(err) {
+		if !opts.UpdateBaseline {
+			return result, fmt.Errorf("baseline file %s not found (create it with --update-baseline)", opts.Baseline)
+		}
+		baseline := validator.NewBaseline(root, result.Errors)
+		if err := writeBaseline(fs, opts.Baseline, baseline); err != nil {
+			return result, err
+		}
+		fmt.Printf("📝 Baseline written to %s with %d known violation(s)\n\n", opts.Baseline, baseline.Size())
+		return validator.ValidationResult{Passed: true, Warnings: result.Warnings}, nil
+	}
+	if err != nil {
+		return result, fmt.Errorf("failed to read baseline: %w", err)
+	}
+
+	var baseline validator.Baseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return result, fmt.Errorf("failed to parse baseline %s: %w", opts.Baseline, err)
+	}
+
+	filtered, suppressed := baseline.Filter(root, result)
+	fmt.Printf("📋 Baseline: %d known violation(s) suppressed, %d new\n", suppressed, len(filtered.Errors))
+
+	if opts.UpdateBaseline {
+		pruned, removed := baseline.Prune(root, result)
+		if err := writeBaseline(fs, opts.Baseline, pruned); err != nil {
+			return filtered, err
+		}
+		fmt.Printf("📝 Baseline updated: %d fixed violation(s) removed, %d remaining\n", removed, pruned.Size())
+	}
+	fmt.Println()
+
+	return filtered, nil
+}
+
+// writeBaseline writes a baseline as indented JSON
+func writeBaseline(fs afero.Fs, path string, baseline validator.Baseline) error {
+	data, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode baseline: %w", err)
+	}
+	if err := afero.WriteFile(fs, path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	return nil
+}
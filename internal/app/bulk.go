@@ -0,0 +1,191 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/toozej/terranotate/internal/diff"
+	"github.com/toozej/terranotate/internal/fixer"
+	"github.com/toozej/terranotate/internal/journal"
+	"github.com/toozej/terranotate/internal/parser"
+	"github.com/toozej/terranotate/internal/validator"
+)
+
+// BulkEdit implements the set, unset and rename-field commands, applying edits to every
+// resource under path matched by the selector. Annotations in sidecar files are edited in the
+// sidecar file. Resources an edit conflicts with, such as a rename to a field they already set,
+// are left unchanged and reported as an error. In dry-run mode a unified diff is printed and no
+// files are written.
+func BulkEdit(fs afero.Fs, path string, selector fixer.Selector, edits []fixer.BulkEdit, dryRun bool) error {
+	if len(edits) == 0 {
+		return fmt.Errorf("no edits given")
+	}
+	for _, edit := range edits {
+		if err := edit.Validate(); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println("=================================================")
+	fmt.Println("Terranotate - Bulk Annotation Edit")
+	fmt.Println("=================================================")
+	fmt.Printf("Path: %s\n", path)
+	for _, edit := range edits {
+		fmt.Printf("Edit: %s\n", describeBulkEdit(edit))
+	}
+	if dryRun {
+		fmt.Println("Mode: dry-run (no files will be written)")
+	}
+	fmt.Println()
+
+	info, err := fs.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to stat path: %w", err)
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		files, err = findTerraformFiles(fs, path)
+		if err != nil {
+			return fmt.Errorf("failed to find terraform files: %w", err)
+		}
+	}
+
+	j, err := journal.New(fs, path)
+	if err != nil {
+		return fmt.Errorf("failed to open fix journal: %w", err)
+	}
+	run := j.NewRun(edits[0].Op)
+
+	// Select by every annotation resources have, including inherited and sidecar ones, and
+	// address them as validation does
+	p := newParser(fs, validator.ValidationSchema{}, repositoryFiles(fs, path))
+	f := fixer.NewCommentFixer(fs, validator.ValidationSchema{})
+	// Sidecar files are edited in memory and parsed from there until they are written
+	sidecars := newSidecarEdits(fs)
+	p.SetSidecarResolver(sidecars.resolver(p))
+
+	totalMatched, totalChanged, filesChanged, conflicts := 0, 0, 0, 0
+	for _, file := range files {
+		original, err := afero.ReadFile(fs, file)
+		if err != nil {
+			log.Printf("Warning: Failed to read %s: %v", file, err)
+			continue
+		}
+
+		resources, err := p.ParseSource(file, original)
+		if err != nil {
+			log.Printf("Warning: Failed to parse %s: %v", file, err)
+			continue
+		}
+
+		// Select resources once so edits to matched fields don't change the selection
+		selected := make(map[string]bool)
+		for _, resource := range resources {
+			if selector.Matches(file, resource) {
+				selected[resource.Address()] = true
+			}
+		}
+		if len(selected) == 0 {
+			continue
+		}
+		totalMatched += len(selected)
+
+		content := string(original)
+		changedResources := make(map[string]bool)
+		for _, edit := range edits {
+			// Re-parse after each edit so comment line numbers are current
+			current, err := p.ParseSource(file, []byte(content))
+			if err != nil {
+				return fmt.Errorf("failed to re-parse %s after edit: %w", file, err)
+			}
+
+			var targets []parser.TerraformResource
+			for _, resource := range current {
+				if !selected[resource.Address()] {
+					continue
+				}
+				if conflict := edit.Conflict(resource); conflict != "" {
+					fmt.Printf("  ⚠️  %s:%d %s: %s\n", file, resource.StartLine, resource.Address(), conflict)
+					conflicts++
+					continue
+				}
+				targets = append(targets, resource)
+			}
+
+			updated, changed := f.ApplyBulkEdit(content, targets, edit)
+			for _, address := range changed {
+				changedResources[address] = true
+			}
+			content = updated
+
+			// Annotations kept in sidecar files are edited there
+			for _, sc := range sidecars.load(filepath.Dir(file)) {
+				for _, address := range f.ApplySidecarBulkEdit(sc.file, targets, edit) {
+					changedResources[address] = true
+				}
+			}
+		}
+		totalChanged += len(changedResources)
+
+		if content == string(original) {
+			continue
+		}
+		filesChanged++
+
+		if dryRun {
+			fmt.Print(diff.Unified(file, string(original), content))
+			continue
+		}
+
+		// #nosec G306 - Writing source code (Terraform), 0644 is appropriate
+		if err := afero.WriteFile(fs, file, []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file, err)
+		}
+		if err := j.Record(run, file, original, []byte(content)); err != nil {
+			return fmt.Errorf("failed to record edit in journal: %w", err)
+		}
+		fmt.Printf("  ✅ Updated %s\n", file)
+	}
+
+	written, err := sidecars.write(dryRun, j, run)
+	if err != nil {
+		return err
+	}
+	filesChanged += written
+
+	fmt.Println("\n" + strings.Repeat("=", 50))
+	fmt.Printf("Bulk Edit Summary: %d resource(s) matched, %d changed in %d file(s), %d conflict(s)\n", totalMatched, totalChanged, filesChanged, conflicts)
+	fmt.Println(strings.Repeat("=", 50))
+
+	if !dryRun && len(run.Files) > 0 {
+		journalFile, err := j.Save(run)
+		if err != nil {
+			return fmt.Errorf("failed to save fix journal: %w", err)
+		}
+		fmt.Printf("\n📒 Journal saved as: %s\n", journalFile)
+		fmt.Printf("💡 Undo with: terranotate fix --revert --run %s %s\n", run.ID, path)
+	}
+
+	if conflicts > 0 {
+		return fmt.Errorf("%d resource(s) were left unchanged because of conflicts", conflicts)
+	}
+
+	return nil
+}
+
+// describeBulkEdit returns a human-readable description of an edit
+func describeBulkEdit(edit fixer.BulkEdit) string {
+	switch edit.Op {
+	case fixer.OpSet:
+		return fmt.Sprintf("set %s %s:%s", edit.Prefix, edit.Field, edit.Value)
+	case fixer.OpUnset:
+		return fmt.Sprintf("unset %s %s", edit.Prefix, edit.Field)
+	case fixer.OpRename:
+		return fmt.Sprintf("rename %s %s -> %s", edit.Prefix, edit.Field, edit.Value)
+	}
+	return edit.Op
+}
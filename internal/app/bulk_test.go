@@ -0,0 +1,182 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/toozej/terranotate/internal/fixer"
+	"github.com/toozej/terranotate/internal/journal"
+)
+
+func TestBulkEdit(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	for _, dir := range []string{"/repo/.git", "/repo/modules/network", "/repo/modules/compute"} {
+		if err := fs.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+	}
+
+	networkContent := `# @metadata owner:alice cost_center:cc-1
+resource "aws_vpc" "main" {
+  cidr_block = "10.0.0.0/16"
+}
+`
+	computeContent := `# @metadata owner:bob cost_center:cc-2
+resource "aws_instance" "web" {
+  ami = "ami-123"
+}
+`
+	if err := afero.WriteFile(fs, "/repo/modules/network/main.tf", []byte(networkContent), 0644); err != nil {
+		t.Fatalf("failed to write network file: %v", err)
+	}
+	if err := afero.WriteFile(fs, "/repo/modules/compute/main.tf", []byte(computeContent), 0644); err != nil {
+		t.Fatalf("failed to write compute file: %v", err)
+	}
+
+	setTeam := []fixer.BulkEdit{{Op: fixer.OpSet, Prefix: "@metadata", Field: "team", Value: "platform-core"}}
+	selector := fixer.Selector{Path: "/repo/modules/network"}
+
+	// Dry run leaves files untouched
+	if err := BulkEdit(fs, "/repo", selector, setTeam, true); err != nil {
+		t.Fatalf("BulkEdit() dry run failed: %v", err)
+	}
+	content, _ := afero.ReadFile(fs, "/repo/modules/network/main.tf")
+	if string(content) != networkContent {
+		t.Errorf("Dry run modified file:\n%s", content)
+	}
+
+	// Set only touches selected resources and is journaled
+	if err := BulkEdit(fs, "/repo", selector, setTeam, false); err != nil {
+		t.Fatalf("BulkEdit() failed: %v", err)
+	}
+	content, _ = afero.ReadFile(fs, "/repo/modules/network/main.tf")
+	if !strings.Contains(string(content), "# @metadata owner:alice cost_center:cc-1 team:platform-core") {
+		t.Errorf("Expected team to be set on network resource, got:\n%s", content)
+	}
+	content, _ = afero.ReadFile(fs, "/repo/modules/compute/main.tf")
+	if string(content) != computeContent {
+		t.Errorf("Unselected file was modified:\n%s", content)
+	}
+
+	j, _ := journal.New(fs, "/repo")
+	runs, err := j.List()
+	if err != nil || len(runs) != 1 || runs[0].Command != fixer.OpSet {
+		t.Fatalf("Expected one journaled set run, got %v (err: %v)", runs, err)
+	}
+
+	// Rename across all files, selected by an existing value
+	rename := []fixer.BulkEdit{{Op: fixer.OpRename, Prefix: "@metadata", Field: "cost_center", Value: "costcenter"}}
+	where := fixer.Selector{Where: map[string]string{"owner": "bob"}}
+	if err := BulkEdit(fs, "/repo", where, rename, false); err != nil {
+		t.Fatalf("BulkEdit() rename failed: %v", err)
+	}
+	content, _ = afero.ReadFile(fs, "/repo/modules/compute/main.tf")
+	if !strings.Contains(string(content), "costcenter:cc-2") {
+		t.Errorf("Expected cost_center to be renamed, got:\n%s", content)
+	}
+	content, _ = afero.ReadFile(fs, "/repo/modules/network/main.tf")
+	if !strings.Contains(string(content), "cost_center:cc-1") {
+		t.Errorf("Expected unselected resource to keep cost_center, got:\n%s", content)
+	}
+
+	// A rename to a field a resource already sets is refused there and reported
+	renameOwner := []fixer.BulkEdit{{Op: fixer.OpRename, Prefix: "@metadata", Field: "owner", Value: "team"}}
+	if err := BulkEdit(fs, "/repo", fixer.Selector{}, renameOwner, false); err == nil {
+		t.Error("Expected error for a rename conflict")
+	}
+	content, _ = afero.ReadFile(fs, "/repo/modules/network/main.tf")
+	if !strings.Contains(string(content), "owner:alice") || strings.Count(string(content), "team:") != 1 {
+		t.Errorf("Expected conflicting resource to be left unchanged, got:\n%s", content)
+	}
+	content, _ = afero.ReadFile(fs, "/repo/modules/compute/main.tf")
+	if !strings.Contains(string(content), "team:bob") {
+		t.Errorf("Expected owner to be renamed where there is no conflict, got:\n%s", content)
+	}
+
+	// Invalid edits are rejected before any file is touched
+	invalid := []fixer.BulkEdit{{Op: fixer.OpSet, Prefix: "@metadata", Field: "team", Value: `it's "two" words`}}
+	if err := BulkEdit(fs, "/repo", fixer.Selector{}, invalid, false); err == nil {
+		t.Error("Expected error for invalid value")
+	}
+}
+
+func TestBulkEditSidecar(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	if err := fs.MkdirAll("/repo/.git", 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	tfContent := `# @metadata owner:alice
+resource "aws_vpc" "main" {}
+
+resource "aws_subnet" "private" {}
+`
+	sidecarContent := `resources:
+  aws_vpc.main:
+    "@metadata":
+      team: network
+      cost_center: cc-1
+  aws_subnet.private:
+    "@metadata":
+      team: network
+`
+	files := map[string]string{
+		"/repo/vpc/main.tf":          tfContent,
+		"/repo/vpc/terranotate.yaml": sidecarContent,
+	}
+	for name, content := range files {
+		if err := afero.WriteFile(fs, name, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	// Resources are selected by their sidecar values, which are edited in the sidecar file
+	where := fixer.Selector{Where: map[string]string{"team": "network"}}
+	edits := []fixer.BulkEdit{
+		{Op: fixer.OpSet, Prefix: "@metadata", Field: "team", Value: "platform"},
+		{Op: fixer.OpRename, Prefix: "@metadata", Field: "cost_center", Value: "costcenter"},
+		{Op: fixer.OpSet, Prefix: "@metadata", Field: "env", Value: "prod"},
+	}
+	if err := BulkEdit(fs, "/repo/vpc", where, edits, false); err != nil {
+		t.Fatalf("BulkEdit() failed: %v", err)
+	}
+
+	content, _ := afero.ReadFile(fs, "/repo/vpc/terranotate.yaml")
+	expected := `resources:
+  aws_vpc.main:
+    "@metadata":
+      team: platform
+      costcenter: cc-1
+  aws_subnet.private:
+    "@metadata":
+      team: platform
+`
+	if string(content) != expected {
+		t.Errorf("Unexpected sidecar file:\n%s", content)
+	}
+
+	// Values set nowhere yet still go to comments
+	content, _ = afero.ReadFile(fs, "/repo/vpc/main.tf")
+	if !strings.Contains(string(content), "# @metadata owner:alice env:prod") || strings.Contains(string(content), "team:") {
+		t.Errorf("Expected only env to be added to comments, got:\n%s", content)
+	}
+
+	// Unset removes the sidecar value, and reverting restores the sidecar file
+	unset := []fixer.BulkEdit{{Op: fixer.OpUnset, Prefix: "@metadata", Field: "team"}}
+	if err := BulkEdit(fs, "/repo/vpc", fixer.Selector{}, unset, false); err != nil {
+		t.Fatalf("BulkEdit() unset failed: %v", err)
+	}
+	content, _ = afero.ReadFile(fs, "/repo/vpc/terranotate.yaml")
+	if strings.Contains(string(content), "team:") {
+		t.Errorf("Expected team to be removed, got:\n%s", content)
+	}
+	if err := RevertFix(fs, "/repo/vpc", ""); err != nil {
+		t.Fatalf("RevertFix() failed: %v", err)
+	}
+	content, _ = afero.ReadFile(fs, "/repo/vpc/terranotate.yaml")
+	if !strings.Contains(string(content), "team: platform") {
+		t.Errorf("Expected revert to restore the sidecar file, got:\n%s", content)
+	}
+}
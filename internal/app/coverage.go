@@ -0,0 +1,141 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/toozej/terranotate/internal/parser"
+	"github.com/toozej/terranotate/internal/validator"
+)
+
+// CoverageOptions configures the coverage report
+type CoverageOptions struct {
+	Output   string // Write the JSON report to this file
+	Baseline string // Fail if coverage is lower than in this JSON report
+}
+
+// Coverage implements the coverage command logic, reporting per-prefix and per-field annotation
+// coverage and optionally comparing it against a baseline report
+func Coverage(fs afero.Fs, path, schemaFile string, opts CoverageOptions) error {
+	fmt.Println("=================================================")
+	fmt.Println("Terranotate - Annotation Coverage")
+	fmt.Println("=================================================")
+	fmt.Printf("Path: %s\n", path)
+	fmt.Printf("Schema file: %s\n", schemaFile)
+	if opts.Baseline != "" {
+		fmt.Printf("Baseline: %s\n", opts.Baseline)
+	}
+	fmt.Println()
+
+	report, err := CollectCoverage(fs, path, schemaFile)
+	if err != nil {
+		return err
+	}
+
+	printCoverageReport(report)
+
+	if opts.Output != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode coverage report: %w", err)
+		}
+		if err := afero.WriteFile(fs, opts.Output, append(data, '\n'), 0644); err != nil {
+			return fmt.Errorf("failed to write coverage report: %w", err)
+		}
+		fmt.Printf("\n✅ Coverage report written to: %s\n", opts.Output)
+	}
+
+	if opts.Baseline == "" {
+		return nil
+	}
+
+	data, err := afero.ReadFile(fs, opts.Baseline)
+	if err != nil {
+		return fmt.Errorf("failed to read baseline: %w", err)
+	}
+	var baseline validator.CoverageReport
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return fmt.Errorf("failed to parse baseline: %w", err)
+	}
+
+	drops := validator.CompareCoverage(baseline, report)
+	if len(drops) == 0 {
+		fmt.Println("\n✅ Coverage has not dropped below the baseline")
+		return nil
+	}
+
+	fmt.Println("\n📉 Coverage dropped below the baseline:")
+	for _, drop := range drops {
+		fmt.Printf("  - %s\n", drop)
+	}
+	return fmt.Errorf("coverage dropped below the baseline in %d place(s)", len(drops))
+}
+
+// CollectCoverage computes annotation coverage for the Terraform files under path. Directories
+// are scanned the same way validation detects workspaces and modules.
+func CollectCoverage(fs afero.Fs, path, schemaFile string) (validator.CoverageReport, error) {
+	v, err := validator.NewSchemaValidator(fs, schemaFile)
+	if err != nil {
+		return validator.CoverageReport{}, fmt.Errorf("failed to load schema: %w", err)
+	}
+
+	info, err := fs.Stat(path)
+	if err != nil {
+		return validator.CoverageReport{}, fmt.Errorf("failed to stat path: %w", err)
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		files, err = findDetectedTerraformFiles(fs, path)
+		if err != nil {
+			return validator.CoverageReport{}, fmt.Errorf("failed to find Terraform files: %w", err)
+		}
+	}
+	if len(files) == 0 {
+		return validator.CoverageReport{}, fmt.Errorf("no Terraform files found in: %s", path)
+	}
+
+	// Resolve inherited annotations from the whole repository, as validation does
+	p := newParser(fs, v.Schema(), repositoryFiles(fs, path))
+
+	var resources []parser.TerraformResource
+	for _, file := range files {
+		fileResources, err := p.ParseFile(file)
+		if err != nil {
+			log.Printf("Warning: Failed to parse %s: %v", file, err)
+			continue
+		}
+		resources = append(resources, fileResources...)
+	}
+
+	fmt.Printf("Parsed %d resource(s) in %d file(s)\n", len(resources), len(files))
+
+	return v.Coverage(resources), nil
+}
+
+// printCoverageReport prints a text summary of a coverage report
+func printCoverageReport(report validator.CoverageReport) {
+	fmt.Println()
+	fmt.Println(strings.Repeat("=", 80))
+	fmt.Printf("📊 Annotation coverage: %.1f%% (%d/%d resources have every required prefix)\n", report.Percent, report.Annotated, report.Resources)
+	fmt.Println(strings.Repeat("=", 80))
+
+	for _, pc := range report.Prefixes {
+		fmt.Printf("\n%-40s %5d/%-5d %6.1f%%\n", pc.Prefix+requiredLabel(pc.Required), pc.Present, pc.Resources, pc.Percent)
+		for _, fc := range pc.Fields {
+			fmt.Printf("  %-38s %5d/%-5d %6.1f%%   non-placeholder %d/%d %.1f%%\n",
+				fc.Field+requiredLabel(fc.Required), fc.Present, fc.Resources, fc.Percent, fc.Filled, fc.Resources, fc.FilledPercent)
+		}
+	}
+}
+
+// requiredLabel marks required prefixes and fields in the coverage summary
+func requiredLabel(required bool) string {
+	if required {
+		return " (required)"
+	}
+	return ""
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/toozej/terranotate/internal/validator"
+)
+
+func TestCoverage(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	schema := `global:
+  required_prefixes: ["@metadata"]
+  prefix_rules:
+    "@metadata":
+      required_fields: [owner]
+`
+	if err := afero.WriteFile(fs, "/schema.yaml", []byte(schema), 0644); err != nil {
+		t.Fatalf("failed to write schema: %v", err)
+	}
+	files := map[string]string{
+		"/ws/prod/main.tf": `
+# @metadata owner:team-a
+resource "aws_vpc" "main" {}
+`,
+		"/ws/staging/main.tf": `
+# @metadata owner:CHANGEME
+resource "aws_vpc" "main" {}
+`,
+	}
+	for file, content := range files {
+		if err := afero.WriteFile(fs, file, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", file, err)
+		}
+	}
+
+	if err := Coverage(fs, "/ws", "/schema.yaml", CoverageOptions{Output: "/coverage.json"}); err != nil {
+		t.Fatalf("Coverage() failed: %v", err)
+	}
+
+	data, err := afero.ReadFile(fs, "/coverage.json")
+	if err != nil {
+		t.Fatalf("Expected coverage report to be written: %v", err)
+	}
+	var report validator.CoverageReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Coverage report is invalid JSON: %v", err)
+	}
+	if report.Resources != 2 || report.Annotated != 2 || report.Prefixes[0].Fields[0].Filled != 1 {
+		t.Errorf("Unexpected coverage report: %+v", report)
+	}
+
+	// Unchanged coverage passes the baseline
+	if err := Coverage(fs, "/ws", "/schema.yaml", CoverageOptions{Baseline: "/coverage.json"}); err != nil {
+		t.Errorf("Expected coverage to match the baseline: %v", err)
+	}
+
+	// An unannotated resource lowers coverage below the baseline
+	if err := afero.WriteFile(fs, "/ws/prod/extra.tf", []byte(`resource "aws_s3_bucket" "logs" {}`), 0644); err != nil {
+		t.Fatalf("failed to write extra.tf: %v", err)
+	}
+	err = Coverage(fs, "/ws", "/schema.yaml", CoverageOptions{Baseline: "/coverage.json"})
+	if err == nil || !strings.Contains(err.Error(), "coverage dropped below the baseline") {
+		t.Errorf("Expected coverage drop error, got %v", err)
+	}
+
+	if err := Coverage(fs, "/ws", "/schema.yaml", CoverageOptions{Baseline: "/missing.json"}); err == nil {
+		t.Error("Expected error for a missing baseline")
+	}
+}
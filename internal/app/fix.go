@@ -1,7 +1,9 @@
 package app
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
@@ -9,13 +11,39 @@ import (
 
 	"github.com/spf13/afero"
 	"github.com/toozej/terranotate/internal/fixer"
+	"github.com/toozej/terranotate/internal/inherit"
+	"github.com/toozej/terranotate/internal/journal"
+	"github.com/toozej/terranotate/internal/modules"
 	"github.com/toozej/terranotate/internal/parser"
+	"github.com/toozej/terranotate/internal/repo"
+	"github.com/toozej/terranotate/internal/sidecar"
 	"github.com/toozej/terranotate/internal/validator"
 	"gopkg.in/yaml.v3"
 )
 
+// FixOptions configures how the fix command applies changes
+type FixOptions struct {
+	Interactive bool      // Prompt for missing values instead of inserting placeholders
+	In          io.Reader // Source of interactive answers (defaults to os.Stdin)
+	Out         io.Writer // Destination of interactive prompts (defaults to os.Stdout)
+	// Target is where missing annotations are written: "comments" or "sidecar". When empty, the
+	// schema's fix.target applies.
+	Target string
+}
+
+// fileFixer applies fixes to a single Terraform file or its sidecar file
+type fileFixer interface {
+	FixFile(filename string, resources []parser.TerraformResource, errors []validator.ValidationError) (string, int, error)
+	FixSidecar(filename string, resources []parser.TerraformResource, errors []validator.ValidationError) (*sidecar.File, int, error)
+}
+
 // Fix implements the fix command logic
 func Fix(fs afero.Fs, path, schemaFile string) error {
+	return FixWithOptions(fs, path, schemaFile, FixOptions{})
+}
+
+// FixWithOptions implements the fix command logic with the given options
+func FixWithOptions(fs afero.Fs, path, schemaFile string, opts FixOptions) error {
 	fmt.Println("=================================================")
 	fmt.Println("Terranotate - Auto-Fix Validation Issues")
 	fmt.Println("=================================================")
@@ -41,12 +69,38 @@ func Fix(fs afero.Fs, path, schemaFile string) error {
 		return fmt.Errorf("no Terraform files found in: %s", path)
 	}
 
+	if err := checkFixTarget(opts.Target); err != nil {
+		return err
+	}
+
+	// Share one buffered reader across files so interactive answers are not lost
+	if opts.Interactive {
+		if opts.In == nil {
+			opts.In = os.Stdin
+		}
+		opts.In = bufio.NewReader(opts.In)
+	}
+
+	// Parse every file with one parser so module addresses and inherited annotations are
+	// resolved from the whole repository, not just the file being fixed
+	ctx, err := newFixContext(fs, path, schemaFile)
+	if err != nil {
+		return err
+	}
+
+	// Record every changed file in a single journal run
+	j, err := journal.New(fs, path)
+	if err != nil {
+		return fmt.Errorf("failed to open fix journal: %w", err)
+	}
+	run := j.NewRun("fix")
+
 	totalFixed := 0
 	totalFilesFixed := 0
 
 	for _, file := range files {
 		fmt.Printf("\nProcessing: %s\n", file)
-		fixed, count, err := fixSingleFile(fs, file, schemaFile)
+		fixed, count, err := fixSingleFile(fs, file, ctx, opts, j, run)
 		if err != nil {
 			log.Printf("Warning: Failed to fix %s: %v", file, err)
 			continue
@@ -61,23 +115,52 @@ func Fix(fs afero.Fs, path, schemaFile string) error {
 	fmt.Printf("Fix Summary: %d files processed, %d files fixed, %d total fixes applied\n", len(files), totalFilesFixed, totalFixed)
 	fmt.Println(strings.Repeat("=", 50))
 
+	if len(run.Files) > 0 {
+		journalFile, err := j.Save(run)
+		if err != nil {
+			return fmt.Errorf("failed to save fix journal: %w", err)
+		}
+		fmt.Printf("\n📒 Journal saved as: %s\n", journalFile)
+		fmt.Printf("💡 Undo with: terranotate fix --revert --run %s %s\n", run.ID, path)
+	}
+
 	return nil
 }
 
-func fixSingleFile(fs afero.Fs, terraformFile, schemaFile string) (bool, int, error) {
-	// Parse the Terraform file
-	prefixes := []string{"@metadata", "@docs", "@validation", "@config"}
-	p := parser.NewCommentParser(fs, prefixes)
+// fixContext is what the files of a fix run share: the schema, its validator and the parser
+type fixContext struct {
+	schema    validator.ValidationSchema
+	validator *validator.SchemaValidator
+	parser    *parser.CommentParser
+}
 
-	resources, err := p.ParseFile(terraformFile)
+// newFixContext loads the schema and creates a parser for the repository containing path
+func newFixContext(fs afero.Fs, path, schemaFile string) (*fixContext, error) {
+	// Load the schema once for the validator and the fixer; it also declares how values are parsed
+	schema, err := loadSchema(fs, schemaFile)
 	if err != nil {
-		return false, 0, fmt.Errorf("failed to parse Terraform file: %w", err)
+		return nil, fmt.Errorf("failed to load schema: %w", err)
 	}
 
-	// Load and validate against schema
-	v, err := validator.NewSchemaValidator(fs, schemaFile)
+	v, err := validator.NewSchemaValidatorFromSchema(fs, schema)
 	if err != nil {
-		return false, 0, fmt.Errorf("failed to load schema: %w", err)
+		return nil, fmt.Errorf("failed to load schema: %w", err)
+	}
+
+	return &fixContext{
+		schema:    schema,
+		validator: v,
+		parser:    newParser(fs, v.Schema(), repositoryFiles(fs, path)),
+	}, nil
+}
+
+func fixSingleFile(fs afero.Fs, terraformFile string, ctx *fixContext, opts FixOptions, j *journal.Journal, run *journal.Run) (bool, int, error) {
+	v, p, schema := ctx.validator, ctx.parser, ctx.schema
+
+	// Parse the Terraform file
+	resources, err := p.ParseFile(terraformFile)
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to parse Terraform file: %w", err)
 	}
 
 	fmt.Println("  Analyzing validation errors...")
@@ -91,37 +174,47 @@ func fixSingleFile(fs afero.Fs, terraformFile, schemaFile string) (bool, int, er
 	fmt.Printf("  Found %d validation errors\n", len(result.Errors))
 	fmt.Println("  Attempting to fix issues...")
 
-	// Create backup
-	backupFile := terraformFile + ".bak"
-	if err := fixer.CopyFile(fs, terraformFile, backupFile); err != nil {
-		return false, 0, fmt.Errorf("failed to create backup: %w", err)
+	target := opts.Target
+	if target == "" {
+		target = schema.Fix.Target
 	}
-	fmt.Printf("  ✅ Created backup: %s\n", backupFile)
-
-	// Load schema for fixer
-	schema, err := loadSchema(fs, schemaFile)
-	if err != nil {
-		return false, 0, fmt.Errorf("failed to parse schema for fixer: %w", err)
+	if err := checkFixTarget(target); err != nil {
+		return false, 0, fmt.Errorf("invalid schema: %w", err)
 	}
 
 	// Fix the file
-	f := fixer.NewCommentFixer(fs, schema)
-	fixedContent, fixCount, err := f.FixFile(terraformFile, resources, result.Errors)
-	if err != nil {
-		return false, 0, fmt.Errorf("failed to fix file: %w", err)
+	var f fileFixer = fixer.NewCommentFixer(fs, schema)
+	if opts.Interactive {
+		in, out := opts.In, opts.Out
+		if in == nil {
+			in = os.Stdin
+		}
+		if out == nil {
+			out = os.Stdout
+		}
+		f = fixer.NewInteractiveFixer(fs, schema, in, out)
 	}
 
-	// Write fixed content
-	// #nosec G306 - Writing source code (Terraform), 0644 is appropriate
-	// Using afero abstraction
-	if err := afero.WriteFile(fs, terraformFile, []byte(fixedContent), 0644); err != nil {
-		return false, 0, fmt.Errorf("failed to write fixed file: %w", err)
+	var fixedFile string
+	var fixCount int
+	if target == validator.FixTargetSidecar {
+		fixedFile, fixCount, err = writeSidecarFixes(fs, f, terraformFile, resources, result.Errors, j, run)
+		// Sidecar files are cached, so reload them before re-validating
+		p.SetSidecarResolver(sidecar.New(fs, p).Annotations)
+	} else {
+		fixedFile, fixCount, err = writeCommentFixes(fs, f, terraformFile, resources, result.Errors, j, run)
+	}
+	if err != nil {
+		return false, 0, err
+	}
+	if fixCount == 0 {
+		fmt.Printf("  ⚠️  No automatic fixes available - %d issues need manual changes\n", len(result.Errors))
+		return false, 0, nil
 	}
 
-	fmt.Printf("  ✅ Applied %d fixes to %s\n", fixCount, terraformFile)
+	fmt.Printf("  ✅ Applied %d fixes to %s\n", fixCount, fixedFile)
 	fmt.Println("  Re-validating fixed file...")
 
-	// Re-validate
 	resources, _ = p.ParseFile(terraformFile)
 	newResult := v.ValidateResources(resources)
 
@@ -131,11 +224,100 @@ func fixSingleFile(fs afero.Fs, terraformFile, schemaFile string) (bool, int, er
 		fmt.Printf("  ⚠️  %d issues remain (may require manual intervention)\n", len(newResult.Errors))
 		// Optional: print detailed remaining errors
 	}
+	placeholderCount := 0
+	for _, warning := range append(newResult.Errors, newResult.Warnings...) {
+		if warning.Rule == validator.RulePlaceholder {
+			placeholderCount++
+		}
+	}
+	if placeholderCount > 0 {
+		fmt.Printf("  📝 %d placeholder value(s) still need real values (run 'terranotate todo %s')\n", placeholderCount, terraformFile)
+	}
 
-	fmt.Printf("  💡 Backup saved as: %s\n", backupFile)
 	return true, fixCount, nil
 }
 
+// checkFixTarget reports an error for an unknown fix target
+func checkFixTarget(target string) error {
+	switch target {
+	case "", validator.FixTargetComments, validator.FixTargetSidecar:
+		return nil
+	}
+	return fmt.Errorf("invalid fix target '%s' (expected '%s' or '%s')", target, validator.FixTargetComments, validator.FixTargetSidecar)
+}
+
+// writeCommentFixes adds the missing annotations as comments to the Terraform file and returns
+// the path of the changed file. An unchanged file is neither written nor journaled.
+func writeCommentFixes(fs afero.Fs, f fileFixer, terraformFile string, resources []parser.TerraformResource, errors []validator.ValidationError, j *journal.Journal, run *journal.Run) (string, int, error) {
+	// Keep the original content for the journal
+	// #nosec G304 - File provided by user via CLI, using afero abstraction
+	original, err := afero.ReadFile(fs, terraformFile)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read Terraform file: %w", err)
+	}
+
+	fixedContent, fixCount, err := f.FixFile(terraformFile, resources, errors)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to fix file: %w", err)
+	}
+	if fixedContent == string(original) {
+		return terraformFile, 0, nil
+	}
+
+	// Write fixed content
+	// #nosec G306 - Writing source code (Terraform), 0644 is appropriate
+	// Using afero abstraction
+	if err := afero.WriteFile(fs, terraformFile, []byte(fixedContent), 0644); err != nil {
+		return "", 0, fmt.Errorf("failed to write fixed file: %w", err)
+	}
+
+	if j != nil && run != nil {
+		if err := j.Record(run, terraformFile, original, []byte(fixedContent)); err != nil {
+			return "", 0, fmt.Errorf("failed to record fix in journal: %w", err)
+		}
+	}
+
+	return terraformFile, fixCount, nil
+}
+
+// writeSidecarFixes adds the missing annotations to the sidecar file of the Terraform file's
+// directory, creating it if needed, and returns the path of the sidecar file
+func writeSidecarFixes(fs afero.Fs, f fileFixer, terraformFile string, resources []parser.TerraformResource, errors []validator.ValidationError, j *journal.Journal, run *journal.Run) (string, int, error) {
+	file, fixCount, err := f.FixSidecar(terraformFile, resources, errors)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to fix sidecar file: %w", err)
+	}
+	if fixCount == 0 {
+		return file.Path, 0, nil
+	}
+
+	// #nosec G304 - Sidecar file next to a file provided by user via CLI
+	original, readErr := afero.ReadFile(fs, file.Path)
+	created := readErr != nil
+
+	content, err := file.Bytes()
+	if err != nil {
+		return "", 0, err
+	}
+	// #nosec G306 - Sidecar files are committed alongside Terraform source, 0644 is appropriate
+	if err := afero.WriteFile(fs, file.Path, content, 0644); err != nil {
+		return "", 0, fmt.Errorf("failed to write sidecar file: %w", err)
+	}
+
+	if j != nil && run != nil {
+		if created {
+			err = j.RecordCreated(run, file.Path, content)
+		} else {
+			err = j.Record(run, file.Path, original, content)
+		}
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to record fix in journal: %w", err)
+		}
+	}
+
+	return file.Path, fixCount, nil
+}
+
 func loadSchema(fs afero.Fs, schemaFile string) (validator.ValidationSchema, error) {
 	var schema validator.ValidationSchema
 	// #nosec G304 - Schema file provided by user
@@ -151,6 +333,92 @@ func loadSchema(fs afero.Fs, schemaFile string) (validator.ValidationSchema, err
 	return schema, nil
 }
 
+// newParser creates a parser for the standard annotation prefixes that coerces values to the
+// types declared in the schema, addresses resources by the module calls between files and
+// resolves the annotations resources inherit from module calls and directory defaults or take
+// from sidecar files
+func newParser(fs afero.Fs, schema validator.ValidationSchema, files []string) *parser.CommentParser {
+	p := parser.NewCommentParser(fs, []string{"@metadata", "@docs", "@validation", "@config"})
+	p.SetTypeResolver(schema.FieldType)
+	index := modules.Resolve(fs, files)
+	p.SetModuleResolver(index.Address)
+	p.SetInheritanceResolver(inherit.New(fs, p, index).Annotations)
+	p.SetSidecarResolver(sidecar.New(fs, p).Annotations)
+	return p
+}
+
+// repositoryFiles returns the Terraform files under path together with those directly in each
+// of its ancestor directories up to the root of the enclosing repository, so that resources are
+// addressed and inherit annotations through module calls made from above path. Modules called
+// from those directories are followed by the module index without walking the rest of the
+// repository. Paths keep the form of path, relative or absolute, so they match the files that
+// are parsed.
+func repositoryFiles(fs afero.Fs, path string) []string {
+	start := filepath.Clean(path)
+	if info, err := fs.Stat(start); err == nil && !info.IsDir() {
+		start = filepath.Dir(start)
+	}
+
+	// Outside a repository only the files under path are used
+	var ancestors []string
+	if root, ok := repo.Root(fs, start); ok {
+		for dir := start; !sameDir(dir, root); {
+			dir = repo.Parent(dir)
+			ancestors = append(ancestors, dir)
+		}
+	}
+
+	var files []string
+	_ = afero.Walk(fs, start, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			name := info.Name()
+			if file != start && (strings.HasPrefix(name, ".") || name == "node_modules" || name == "terraform.tfstate.d") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(info.Name(), ".tf") {
+			files = append(files, workingDirPath(file))
+		}
+		return nil
+	})
+	for _, dir := range ancestors {
+		matches, _ := afero.Glob(fs, filepath.Join(dir, "*.tf"))
+		for _, file := range matches {
+			files = append(files, workingDirPath(file))
+		}
+	}
+	return files
+}
+
+// sameDir reports whether two directories are the same once made absolute
+func sameDir(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
+
+// workingDirPath shortens a relative path that leaves and re-enters the working directory, e.g.
+// "../app/main.tf" when working in app, to the form the file has when named from the working
+// directory, e.g. "main.tf"
+func workingDirPath(file string) string {
+	if filepath.IsAbs(file) || !strings.HasPrefix(file, "..") {
+		return file
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return file
+	}
+	rel, err := filepath.Rel(wd, filepath.Join(wd, file))
+	if err != nil {
+		return file
+	}
+	return rel
+}
+
 func findTerraformFiles(fs afero.Fs, root string) ([]string, error) {
 	var files []string
 	err := afero.Walk(fs, root, func(path string, info os.FileInfo, err error) error {
@@ -172,76 +440,58 @@ func findTerraformFiles(fs afero.Fs, root string) ([]string, error) {
 	return files, err
 }
 
-// RevertFix reverts files to their backup versions
-func RevertFix(fs afero.Fs, path string) error {
+// RevertFix restores the files changed by a fix run recorded in the journal.
+// An empty runID selects the most recent run that changed files under path.
+func RevertFix(fs afero.Fs, path, runID string) error {
 	fmt.Println("=================================================")
-	fmt.Println("Terranotate - Revert to Backup Files")
+	fmt.Println("Terranotate - Revert Fix Run")
 	fmt.Println("=================================================")
 	fmt.Printf("Path: %s\n\n", path)
 
-	info, err := fs.Stat(path)
-	if err != nil {
+	if _, err := fs.Stat(path); err != nil {
 		return fmt.Errorf("failed to stat path: %w", err)
 	}
 
-	var filesToRevert []string
-	if info.IsDir() {
-		// Find all .bak files in the directory
-		err := afero.Walk(fs, path, func(file string, info os.FileInfo, err error) error {
-			if err != nil {
-				return err
-			}
-			if !info.IsDir() && strings.HasSuffix(file, ".bak") {
-				filesToRevert = append(filesToRevert, file)
-			}
-			return nil
-		})
-		if err != nil {
-			return fmt.Errorf("failed to find backup files: %w", err)
-		}
-	} else {
-		// Single file - check if corresponding .bak exists
-		backupFile := path + ".bak"
-		exists, err := afero.Exists(fs, backupFile)
-		if err != nil {
-			return fmt.Errorf("failed to check for backup file: %w", err)
-		}
-		if exists {
-			filesToRevert = append(filesToRevert, backupFile)
-		}
+	j, err := journal.New(fs, path)
+	if err != nil {
+		return fmt.Errorf("failed to open fix journal: %w", err)
 	}
 
-	if len(filesToRevert) == 0 {
-		fmt.Println("No backup files found to revert.")
-		return nil
+	var run *journal.Run
+	if runID != "" {
+		run, err = j.Load(runID)
+	} else {
+		run, err = j.Latest(path)
+	}
+	if err != nil {
+		return err
 	}
 
-	fmt.Printf("Found %d backup file(s) to revert.\n\n", len(filesToRevert))
+	if run.Reverted() {
+		return fmt.Errorf("fix run %s has already been reverted", run.ID)
+	}
 
-	revertCount := 0
-	for _, backupFile := range filesToRevert {
-		originalFile := strings.TrimSuffix(backupFile, ".bak")
-		fmt.Printf("Reverting: %s\n", originalFile)
+	fmt.Printf("Reverting fix run %s (%s, %d file(s))\n\n", run.ID, run.CreatedAt.Local().Format("2006-01-02 15:04:05"), len(run.Files))
 
-		// Copy backup to original
-		if err := fixer.CopyFile(fs, backupFile, originalFile); err != nil {
-			log.Printf("  ⚠️  Warning: Failed to revert %s: %v", originalFile, err)
-			continue
-		}
-
-		// Remove backup file
-		if err := fs.Remove(backupFile); err != nil {
-			log.Printf("  ⚠️  Warning: Failed to remove backup %s: %v", backupFile, err)
-			continue
-		}
+	result, err := j.Revert(run, path)
+	if err != nil {
+		return err
+	}
 
-		fmt.Printf("  ✅ Reverted %s\n", originalFile)
-		revertCount++
+	for _, file := range result.Reverted {
+		fmt.Printf("  ✅ Reverted %s\n", file)
+	}
+	for _, file := range result.Skipped {
+		fmt.Printf("  ⚠️  Skipped %s (modified since the fix run)\n", file)
 	}
 
 	fmt.Println("\n" + strings.Repeat("=", 50))
-	fmt.Printf("Revert Summary: %d file(s) reverted successfully\n", revertCount)
+	fmt.Printf("Revert Summary: %d file(s) reverted, %d skipped\n", len(result.Reverted), len(result.Skipped))
 	fmt.Println(strings.Repeat("=", 50))
 
+	if len(result.Skipped) > 0 {
+		return fmt.Errorf("refused to revert %d file(s) modified since fix run %s", len(result.Skipped), run.ID)
+	}
+
 	return nil
 }
@@ -1,10 +1,13 @@
 package app
 
 import (
+	"sort"
 	"strings"
 	"testing"
 
 	"github.com/spf13/afero"
+	"github.com/toozej/terranotate/internal/journal"
+	"github.com/toozej/terranotate/internal/validator"
 )
 
 func TestFix(t *testing.T) {
@@ -46,10 +49,14 @@ global:
 		t.Errorf("Fix() directory failed: %v", err)
 	}
 
-	// Verify backups were created
+	// Verify a journal run was recorded instead of .bak files
 	exists, _ := afero.Exists(fs, "/infra/main.tf.bak")
-	if !exists {
-		t.Error("Expected backup main.tf.bak to exist")
+	if exists {
+		t.Error("Expected no backup main.tf.bak file")
+	}
+	entries, _ := afero.ReadDir(fs, "/infra/.terranotate/journal")
+	if len(entries) != 1 {
+		t.Errorf("Expected 1 journal run, got %d", len(entries))
 	}
 
 	// Test Fix on single file
@@ -88,8 +95,13 @@ global:
 		t.Fatalf("failed to write vpc.tf: %v", err)
 	}
 
+	ctx, err := newFixContext(fs, "/vpc.tf", "/schema.yaml")
+	if err != nil {
+		t.Fatalf("newFixContext() failed: %v", err)
+	}
+
 	// Test fixSingleFile
-	fixed, count, err := fixSingleFile(fs, "/vpc.tf", "/schema.yaml")
+	fixed, count, err := fixSingleFile(fs, "/vpc.tf", ctx, FixOptions{}, nil, nil)
 	if err != nil {
 		t.Fatalf("fixSingleFile() failed: %v", err)
 	}
@@ -109,7 +121,7 @@ global:
 	}
 
 	// Test fixSingleFile on already valid file
-	fixed, _, err = fixSingleFile(fs, "/vpc.tf", "/schema.yaml")
+	fixed, _, err = fixSingleFile(fs, "/vpc.tf", ctx, FixOptions{}, nil, nil)
 	if err != nil {
 		t.Fatalf("fixSingleFile() failed on valid file: %v", err)
 	}
@@ -118,6 +130,96 @@ global:
 	}
 }
 
+func TestFixSingleFileWithoutFixes(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	// An invalid value can't be fixed automatically
+	schemaContent := `
+global:
+  required_prefixes: ["@metadata"]
+field_validations:
+  owner:
+    pattern: "^[a-z]+\\.[a-z]+$"
+`
+	if err := afero.WriteFile(fs, "/schema.yaml", []byte(schemaContent), 0644); err != nil {
+		t.Fatalf("failed to write schema: %v", err)
+	}
+	tfContent := "# @metadata owner:alice\nresource \"aws_vpc\" \"main\" {}\n"
+	if err := afero.WriteFile(fs, "/vpc.tf", []byte(tfContent), 0644); err != nil {
+		t.Fatalf("failed to write vpc.tf: %v", err)
+	}
+	before, _ := fs.Stat("/vpc.tf")
+
+	ctx, err := newFixContext(fs, "/vpc.tf", "/schema.yaml")
+	if err != nil {
+		t.Fatalf("newFixContext() failed: %v", err)
+	}
+	j, err := journal.New(fs, "/vpc.tf")
+	if err != nil {
+		t.Fatalf("journal.New() failed: %v", err)
+	}
+	run := j.NewRun("fix")
+
+	fixed, count, err := fixSingleFile(fs, "/vpc.tf", ctx, FixOptions{}, j, run)
+	if err != nil {
+		t.Fatalf("fixSingleFile() failed: %v", err)
+	}
+	if fixed || count != 0 {
+		t.Errorf("Expected no fixes, got fixed=%v count=%d", fixed, count)
+	}
+	if len(run.Files) != 0 {
+		t.Errorf("Expected an unchanged file not to be journaled, got %+v", run.Files)
+	}
+	if after, _ := fs.Stat("/vpc.tf"); !after.ModTime().Equal(before.ModTime()) {
+		t.Error("Expected an unchanged file not to be rewritten")
+	}
+}
+
+func TestFixInteractive(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	schemaContent := `
+global:
+  required_prefixes: ["@metadata"]
+  prefix_rules:
+    "@metadata":
+      required_fields: ["owner"]
+`
+	err := afero.WriteFile(fs, "/schema.yaml", []byte(schemaContent), 0644)
+	if err != nil {
+		t.Fatalf("failed to write schema: %v", err)
+	}
+
+	err = fs.MkdirAll("/infra", 0755)
+	if err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	tfContent := `resource "aws_vpc" "main" { cidr_block = "10.0.0.0/16" }`
+	for _, name := range []string{"/infra/a.tf", "/infra/b.tf"} {
+		if err := afero.WriteFile(fs, name, []byte(tfContent), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	var out strings.Builder
+	opts := FixOptions{
+		Interactive: true,
+		In:          strings.NewReader("team-a\nteam-b\n"),
+		Out:         &out,
+	}
+	if err := FixWithOptions(fs, "/infra", "/schema.yaml", opts); err != nil {
+		t.Fatalf("FixWithOptions() failed: %v", err)
+	}
+
+	// Answers are consumed in order across files
+	for name, owner := range map[string]string{"/infra/a.tf": "team-a", "/infra/b.tf": "team-b"} {
+		content, _ := afero.ReadFile(fs, name)
+		if !contains(string(content), "# @metadata owner:"+owner) {
+			t.Errorf("Expected %s to have owner %s, got:\n%s", name, owner, content)
+		}
+	}
+}
+
 func TestLoadSchema(t *testing.T) {
 	fs := afero.NewMemMapFs()
 	schemaContent := `
@@ -191,61 +293,248 @@ func TestFindTerraformFiles(t *testing.T) {
 	}
 }
 
+func TestRepositoryFiles(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	files := []string{
+		"/repo/main.tf",
+		"/repo/modules/main.tf",
+		"/repo/modules/vpc/main.tf",
+		"/repo/modules/vpc/subnets/main.tf",
+		"/repo/modules/dns/main.tf",
+		"/repo/envs/prod/main.tf",
+	}
+	for _, file := range append(files, "/repo/.git/HEAD", "/outside.tf") {
+		if err := afero.WriteFile(fs, file, []byte(""), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", file, err)
+		}
+	}
+
+	got := repositoryFiles(fs, "/repo/modules/vpc/main.tf")
+	sort.Strings(got)
+	want := []string{
+		"/repo/main.tf",
+		"/repo/modules/main.tf",
+		"/repo/modules/vpc/main.tf",
+		"/repo/modules/vpc/subnets/main.tf",
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected the module, its subdirectories and its ancestors only, got %v", got)
+	}
+
+	got = repositoryFiles(fs, "/repo")
+	if len(got) != len(files) {
+		t.Errorf("Expected every file of the repository, got %v", got)
+	}
+}
+
 func TestRevertFix(t *testing.T) {
 	fs := afero.NewMemMapFs()
 
-	// Setup: File and backup
-	err := afero.WriteFile(fs, "/main.tf", []byte("new content"), 0644)
+	schemaContent := `
+global:
+  required_prefixes: ["@metadata"]
+  prefix_rules:
+    "@metadata":
+      required_fields: ["owner"]
+`
+	err := afero.WriteFile(fs, "/schema.yaml", []byte(schemaContent), 0644)
 	if err != nil {
-		t.Fatalf("failed to write file: %v", err)
+		t.Fatalf("failed to write schema: %v", err)
 	}
-	err = afero.WriteFile(fs, "/main.tf.bak", []byte("old content"), 0644)
+
+	// Journal is rooted at the enclosing git repository
+	err = fs.MkdirAll("/repo/.git", 0755)
+	if err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	err = fs.MkdirAll("/repo/infra", 0755)
+	if err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+
+	original := `resource "aws_vpc" "main" { cidr_block = "10.0.0.0/16" }`
+	for _, name := range []string{"/repo/infra/a.tf", "/repo/infra/b.tf"} {
+		if err := afero.WriteFile(fs, name, []byte(original), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	// A stray .bak file that terranotate did not create must be left alone
+	err = afero.WriteFile(fs, "/repo/infra/c.tf.bak", []byte("unrelated"), 0644)
 	if err != nil {
 		t.Fatalf("failed to write backup: %v", err)
 	}
 
-	// Test RevertFix on file
-	err = RevertFix(fs, "/main.tf")
+	if err := Fix(fs, "/repo/infra", "/schema.yaml"); err != nil {
+		t.Fatalf("Fix() failed: %v", err)
+	}
+
+	entries, _ := afero.ReadDir(fs, "/repo/.terranotate/journal")
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 journal run at repository root, got %d", len(entries))
+	}
+	runID := strings.TrimSuffix(entries[0].Name(), ".json")
+
+	// Modify one file after the fix; revert must refuse to clobber it
+	err = afero.WriteFile(fs, "/repo/infra/b.tf", []byte("hand edited"), 0644)
 	if err != nil {
-		t.Errorf("RevertFix() failed: %v", err)
+		t.Fatalf("failed to write file: %v", err)
 	}
 
-	// Verify reversion
-	content, _ := afero.ReadFile(fs, "/main.tf")
-	if string(content) != "old content" {
-		t.Errorf("Revert failed, got %q, want %q", string(content), "old content")
+	err = RevertFix(fs, "/repo/infra", runID)
+	if err == nil {
+		t.Error("RevertFix() should report files modified since the run")
 	}
 
-	// Verify backup removal
-	exists, _ := afero.Exists(fs, "/main.tf.bak")
+	content, _ := afero.ReadFile(fs, "/repo/infra/a.tf")
+	if string(content) != original {
+		t.Errorf("Revert failed, got %q, want %q", string(content), original)
+	}
+	content, _ = afero.ReadFile(fs, "/repo/infra/b.tf")
+	if string(content) != "hand edited" {
+		t.Errorf("Modified file was clobbered, got %q", string(content))
+	}
+	exists, _ := afero.Exists(fs, "/repo/infra/c.tf")
 	if exists {
-		t.Error("Backup file should have been removed")
+		t.Error("Unrelated .bak file should not have been restored")
 	}
 
-	// Setup for directory revert
-	err = fs.MkdirAll("/dir", 0755)
-	if err != nil {
+	// Nothing left to revert in the latest run for a.tf
+	err = RevertFix(fs, "/repo/infra/a.tf", "")
+	if err == nil {
+		t.Error("RevertFix() should have failed with nothing to revert")
+	}
+
+	// Unknown run ID
+	err = RevertFix(fs, "/repo/infra", "19700101T000000Z")
+	if err == nil {
+		t.Error("RevertFix() should have failed for unknown run")
+	}
+
+	// Test RevertFix on non-existent path
+	err = RevertFix(fs, "/non-existent", "")
+	if err == nil {
+		t.Error("RevertFix() should have failed for non-existent path")
+	}
+}
+
+func TestFixSidecarTarget(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	schemaContent := `
+global:
+  required_prefixes: ["@metadata"]
+  prefix_rules:
+    "@metadata":
+      required_fields: ["owner"]
+fix:
+  target: sidecar
+`
+	if err := afero.WriteFile(fs, "/schema.yaml", []byte(schemaContent), 0644); err != nil {
+		t.Fatalf("failed to write schema: %v", err)
+	}
+	if err := fs.MkdirAll("/repo/.git", 0755); err != nil {
 		t.Fatalf("failed to create directory: %v", err)
 	}
-	err = afero.WriteFile(fs, "/dir/a.tf.bak", []byte("backup a"), 0644)
+
+	files := map[string]string{
+		"/repo/infra/a.tf": `resource "aws_vpc" "main" { cidr_block = "10.0.0.0/16" }`,
+		"/repo/infra/b.tf": `resource "aws_subnet" "private" { vpc_id = "x" }`,
+	}
+	for name, content := range files {
+		if err := afero.WriteFile(fs, name, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	if err := Fix(fs, "/repo/infra", "/schema.yaml"); err != nil {
+		t.Fatalf("Fix() failed: %v", err)
+	}
+
+	for name, content := range files {
+		if got, _ := afero.ReadFile(fs, name); string(got) != content {
+			t.Errorf("Expected %s to be unchanged, got %q", name, string(got))
+		}
+	}
+	sidecarFile, err := afero.ReadFile(fs, "/repo/infra/terranotate.yaml")
 	if err != nil {
-		t.Fatalf("failed to write backup: %v", err)
+		t.Fatalf("Expected a sidecar file to be created: %v", err)
+	}
+	for _, address := range []string{"aws_vpc.main:", "aws_subnet.private:"} {
+		if !contains(string(sidecarFile), address) {
+			t.Errorf("Expected the sidecar file to annotate %s, got:\n%s", address, sidecarFile)
+		}
 	}
 
-	err = RevertFix(fs, "/dir")
+	// The sidecar annotations satisfy the schema
+	ctx, err := newFixContext(fs, "/repo/infra/a.tf", "/schema.yaml")
 	if err != nil {
-		t.Errorf("RevertFix() on directory failed: %v", err)
+		t.Fatalf("newFixContext() failed: %v", err)
+	}
+	fixed, _, err := fixSingleFile(fs, "/repo/infra/a.tf", ctx, FixOptions{}, nil, nil)
+	if err != nil || fixed {
+		t.Errorf("Expected a.tf to pass validation after the fix, fixed=%v err=%v", fixed, err)
 	}
 
-	content, _ = afero.ReadFile(fs, "/dir/a.tf")
-	if string(content) != "backup a" {
-		t.Error("Directory revert failed")
+	// Reverting removes the created sidecar file
+	if err := RevertFix(fs, "/repo/infra", ""); err != nil {
+		t.Fatalf("RevertFix() failed: %v", err)
+	}
+	if exists, _ := afero.Exists(fs, "/repo/infra/terranotate.yaml"); exists {
+		t.Error("Expected the created sidecar file to be removed")
 	}
 
-	// Test RevertFix on non-existent path
-	err = RevertFix(fs, "/non-existent")
-	if err == nil {
-		t.Error("RevertFix() should have failed for non-existent path")
+	// An unknown target is rejected
+	if err := FixWithOptions(fs, "/repo/infra", "/schema.yaml", FixOptions{Target: "elsewhere"}); err == nil {
+		t.Error("FixWithOptions() should reject an unknown target")
+	}
+}
+
+func TestFixKeepsInheritedFields(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	schemaContent := `
+global:
+  required_prefixes: ["@metadata"]
+  prefix_rules:
+    "@metadata":
+      required_fields: ["owner", "team"]
+`
+	if err := afero.WriteFile(fs, "/schema.yaml", []byte(schemaContent), 0644); err != nil {
+		t.Fatalf("failed to write schema: %v", err)
+	}
+	if err := fs.MkdirAll("/repo/.git", 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+
+	files := map[string]string{
+		"/repo/main.tf": `
+# @metadata team:network
+module "net" {
+  source = "./modules/net"
+}
+`,
+		"/repo/modules/net/main.tf": `resource "aws_vpc" "main" { cidr_block = "10.0.0.0/16" }
+`,
+	}
+	for name, content := range files {
+		if err := afero.WriteFile(fs, name, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	// Fixing the child module alone still sees the team its caller passes down
+	if err := Fix(fs, "/repo/modules/net", "/schema.yaml"); err != nil {
+		t.Fatalf("Fix() failed: %v", err)
+	}
+
+	content, _ := afero.ReadFile(fs, "/repo/modules/net/main.tf")
+	if !contains(string(content), "owner:") {
+		t.Errorf("Expected the missing owner to be added, got:\n%s", content)
+	}
+	if contains(string(content), "team:") {
+		t.Errorf("Expected the inherited team to be left alone, got:\n%s", content)
 	}
 }
 
@@ -253,3 +542,43 @@ func TestRevertFix(t *testing.T) {
 func contains(s, substr string) bool {
 	return strings.Contains(s, substr)
 }
+
+func TestFixLeavesPlaceholders(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	schemaContent := `
+global:
+  required_prefixes: ["@metadata"]
+  prefix_rules:
+    "@metadata":
+      required_fields: [owner, priority, environment, phone, emergency_contact]
+field_validations:
+  priority:
+    type: enum
+    allowed_values: [low, medium, high]
+`
+	if err := afero.WriteFile(fs, "/schema.yaml", []byte(schemaContent), 0644); err != nil {
+		t.Fatalf("failed to write schema: %v", err)
+	}
+	if err := afero.WriteFile(fs, "/infra/main.tf", []byte(`resource "aws_vpc" "main" {}`+"\n"), 0644); err != nil {
+		t.Fatalf("failed to write main.tf: %v", err)
+	}
+
+	if err := Fix(fs, "/infra", "/schema.yaml"); err != nil {
+		t.Fatalf("Fix() failed: %v", err)
+	}
+
+	// Every value the fixer made up is reported until it is filled in
+	result := validateTerraformFiles(fs, "/infra", []string{"/infra/main.tf"}, "/schema.yaml")
+	placeholders := make(map[string]bool)
+	for _, err := range append(append([]validator.ValidationError{}, result.Errors...), result.Warnings...) {
+		if err.Rule == validator.RulePlaceholder {
+			placeholders[err.Field] = true
+		}
+	}
+	for _, field := range []string{"owner", "priority", "environment", "phone", "emergency_contact"} {
+		if !placeholders["@metadata:"+field] {
+			t.Errorf("Expected %s to be reported as a placeholder, got %+v", field, result)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/spf13/afero"
@@ -12,33 +13,117 @@ import (
 	"github.com/toozej/terranotate/internal/validator"
 )
 
+// GenerateOptions configures how documentation is generated
+type GenerateOptions struct {
+	Format    string // Output format, one of generator.Formats (default: markdown)
+	Template  string // Path to a text/template file; overrides Format
+	Inject    string // Document to inject into between the BEGIN/END_TERRANOTATE markers
+	Check     bool   // Fail if the output or injected section is stale instead of writing it
+	OutputDir string // Write one document per module directory plus an index page
+	Validate  bool   // Add validation status, missing fields and annotation coverage
+}
+
+// renderFunc renders documentation for the resources and interface of one module
+type renderFunc func(moduleName string, resources []parser.TerraformResource, iface parser.ModuleInterface) (string, error)
+
 // Generate creates markdown documentation from Terraform resources
 func Generate(fs afero.Fs, path, schemaFile, outputFile string) error {
+	return GenerateWithOptions(fs, path, schemaFile, outputFile, GenerateOptions{})
+}
+
+// GenerateWithOptions creates documentation from Terraform resources with the given options
+func GenerateWithOptions(fs afero.Fs, path, schemaFile, outputFile string, opts GenerateOptions) error {
 	fmt.Println("=================================================")
 	fmt.Println("Terranotate - Generate Documentation")
 	fmt.Println("=================================================")
 	fmt.Printf("Path: %s\n", path)
 	fmt.Printf("Schema: %s\n", schemaFile)
-	if outputFile != "" {
+	if opts.Template != "" {
+		fmt.Printf("Template: %s\n", opts.Template)
+	} else if opts.Format != "" {
+		fmt.Printf("Format: %s\n", opts.Format)
+	}
+	if opts.OutputDir != "" {
+		fmt.Printf("Output directory: %s\n", opts.OutputDir)
+	} else if opts.Inject != "" {
+		fmt.Printf("Inject into: %s\n", opts.Inject)
+	} else if outputFile != "" {
 		fmt.Printf("Output: %s\n", outputFile)
 	} else {
 		fmt.Println("Output: stdout")
 	}
+	if opts.Validate {
+		fmt.Println("Validation: enabled")
+	}
+	if opts.Check {
+		fmt.Println("Mode: check (no files will be written)")
+	}
 	fmt.Println()
 
+	if opts.Inject != "" && outputFile != "" {
+		return fmt.Errorf("--inject and --output cannot be used together")
+	}
+	if opts.OutputDir != "" && (opts.Inject != "" || outputFile != "") {
+		return fmt.Errorf("--output-dir cannot be used with --inject or --output")
+	}
+	if opts.Check && opts.Inject == "" && outputFile == "" && opts.OutputDir == "" {
+		return fmt.Errorf("--check requires --inject, --output or --output-dir")
+	}
+
 	// Get schema for documentation
 	schema, err := loadSchemaForGenerator(fs, schemaFile)
 	if err != nil {
 		return fmt.Errorf("failed to load schema for generator: %w", err)
 	}
 
+	var renderer generator.DocumentationRenderer
+	var tmplGen *generator.TemplateGenerator
+	if opts.Template != "" {
+		// #nosec G304 - Template file provided by user via CLI
+		text, err := afero.ReadFile(fs, opts.Template)
+		if err != nil {
+			return fmt.Errorf("failed to read template: %w", err)
+		}
+		tmplGen, err = generator.NewTemplateGenerator(schema, filepath.Base(opts.Template), string(text))
+		if err != nil {
+			return err
+		}
+	} else {
+		renderer, err = generator.NewRenderer(opts.Format, schema)
+		if err != nil {
+			return err
+		}
+		if opts.Validate {
+			if err := renderer.EnableValidation(); err != nil {
+				return fmt.Errorf("failed to enable validation: %w", err)
+			}
+		}
+	}
+
+	render := func(moduleName string, resources []parser.TerraformResource, iface parser.ModuleInterface) (string, error) {
+		if tmplGen != nil {
+			tmplGen.SetModuleInterface(iface)
+			return tmplGen.Render(moduleName, resources)
+		}
+		renderer.SetModuleInterface(iface)
+		return renderer.GenerateDocumentation(moduleName, resources), nil
+	}
+
 	// Determine if path is a file or directory
 	info, err := fs.Stat(path)
 	if err != nil {
 		return fmt.Errorf("failed to stat path: %w", err)
 	}
 
+	if opts.OutputDir != "" {
+		if !info.IsDir() {
+			return fmt.Errorf("--output-dir requires a directory, got file: %s", path)
+		}
+		return generateModuleDocs(fs, path, schema, render, opts)
+	}
+
 	var allResources []parser.TerraformResource
+	var iface parser.ModuleInterface
 	var moduleName string
 
 	if info.IsDir() {
@@ -55,8 +140,7 @@ func Generate(fs afero.Fs, path, schemaFile, outputFile string) error {
 		fmt.Printf("Found %d Terraform file(s)\n", len(tfFiles))
 
 		// Parse all files
-		prefixes := []string{"@metadata", "@docs", "@validation", "@config"}
-		p := parser.NewCommentParser(fs, prefixes)
+		p := newParser(fs, schema, tfFiles)
 
 		for _, file := range tfFiles {
 			resources, err := p.ParseFile(file)
@@ -67,11 +151,19 @@ func Generate(fs afero.Fs, path, schemaFile, outputFile string) error {
 			allResources = append(allResources, resources...)
 		}
 
+		// The interface is that of the module in the directory itself, not of its subdirectories
+		var moduleFiles []string
+		for _, file := range tfFiles {
+			if filepath.Dir(file) == filepath.Clean(path) {
+				moduleFiles = append(moduleFiles, file)
+			}
+		}
+		iface = parseModuleInterface(p, moduleFiles)
+
 		moduleName = filepath.Base(path)
 	} else {
 		// Single file
-		prefixes := []string{"@metadata", "@docs", "@validation", "@config"}
-		p := parser.NewCommentParser(fs, prefixes)
+		p := newParser(fs, schema, []string{path})
 
 		resources, err := p.ParseFile(path)
 		if err != nil {
@@ -79,32 +171,233 @@ func Generate(fs afero.Fs, path, schemaFile, outputFile string) error {
 		}
 
 		allResources = resources
+		iface = parseModuleInterface(p, []string{path})
 		moduleName = strings.TrimSuffix(filepath.Base(path), ".tf")
 	}
 
-	fmt.Printf("Parsed %d resource(s)\n\n", len(allResources))
+	fmt.Printf("Parsed %d resource(s), %d variable(s) and %d output(s)\n\n", len(allResources), len(iface.Variables), len(iface.Outputs))
 
-	if len(allResources) == 0 {
+	if len(allResources) == 0 && iface.Empty() {
 		return fmt.Errorf("no resources found to document")
 	}
 
-	// Generate markdown
-	gen := generator.NewMarkdownGenerator(schema)
-	markdown := gen.GenerateDocumentation(moduleName, allResources)
+	// Generate documentation
+	output, err := render(moduleName, allResources, iface)
+	if err != nil {
+		return err
+	}
+
+	// Output documentation
+	if opts.Inject != "" || opts.Check {
+		target := outputFile
+		if opts.Inject != "" {
+			target = opts.Inject
+		}
+		return writeOrCheckDocumentation(fs, target, output, opts)
+	}
 
-	// Output markdown
 	if outputFile != "" {
 		// Write to file
-		if err := afero.WriteFile(fs, outputFile, []byte(markdown), 0644); err != nil {
+		if err := afero.WriteFile(fs, outputFile, []byte(output), 0644); err != nil {
 			return fmt.Errorf("failed to write output file: %w", err)
 		}
 		fmt.Printf("✅ Documentation written to: %s\n", outputFile)
 	} else {
 		// Write to stdout
 		fmt.Println(strings.Repeat("=", 50))
-		fmt.Println(markdown)
+		fmt.Println(output)
+	}
+
+	return nil
+}
+
+// generateModuleDocs writes one document per module directory plus an index page linking
+// them. Module boundaries follow the same detection used by workspace and module validation.
+func generateModuleDocs(fs afero.Fs, path string, schema validator.ValidationSchema, render renderFunc, opts GenerateOptions) error {
+	tfFiles, err := findDetectedTerraformFiles(fs, path)
+	if err != nil {
+		return fmt.Errorf("failed to find Terraform files: %w", err)
 	}
 
+	var docFiles []string
+	for _, file := range tfFiles {
+		if !strings.HasSuffix(file, "_test.tf") {
+			docFiles = append(docFiles, file)
+		}
+	}
+	if len(docFiles) == 0 {
+		return fmt.Errorf("no Terraform files found in: %s", path)
+	}
+
+	filesByDir := groupFilesByDirectory(docFiles, path)
+	dirs := make([]string, 0, len(filesByDir))
+	for dir := range filesByDir {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	fmt.Printf("Found %d Terraform file(s) in %d module(s)\n\n", len(docFiles), len(dirs))
+
+	if !opts.Check {
+		if err := fs.MkdirAll(opts.OutputDir, 0750); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+
+	format, ext := opts.Format, generator.FileExtension(opts.Format)
+	if opts.Template != "" {
+		ext = templateExtension(opts.Template)
+		format = formatForExtension(ext)
+	}
+
+	p := newParser(fs, schema, docFiles)
+
+	var entries []generator.IndexEntry
+	var stale []string
+	for _, dir := range dirs {
+		var resources []parser.TerraformResource
+		types := make(map[string]bool)
+		for _, file := range filesByDir[dir] {
+			fileResources, err := p.ParseFile(file)
+			if err != nil {
+				fmt.Printf("Warning: Failed to parse %s: %v\n", file, err)
+				continue
+			}
+			for _, resource := range fileResources {
+				types[resource.Type] = true
+			}
+			resources = append(resources, fileResources...)
+		}
+		iface := parseModuleInterface(p, filesByDir[dir])
+		if len(resources) == 0 && iface.Empty() {
+			continue
+		}
+
+		title := dir
+		if dir == "root" {
+			title = filepath.Base(path)
+		}
+		output, err := render(title, resources, iface)
+		if err != nil {
+			return fmt.Errorf("failed to render %s: %w", dir, err)
+		}
+
+		document := strings.ReplaceAll(filepath.ToSlash(dir), "/", "-") + ext
+		if err := writeOrCheckDocumentation(fs, filepath.Join(opts.OutputDir, document), output, GenerateOptions{Check: opts.Check}); err != nil {
+			if !opts.Check {
+				return err
+			}
+			stale = append(stale, document)
+		}
+
+		entry := generator.IndexEntry{Module: dir, Document: document, Resources: len(resources), ResourceTypes: len(types)}
+		if opts.Validate {
+			coverage, err := generator.ComputeCoverage(schema, resources)
+			if err != nil {
+				return fmt.Errorf("failed to compute coverage for %s: %w", dir, err)
+			}
+			entry.Coverage = &coverage
+		}
+		entries = append(entries, entry)
+	}
+
+	if len(entries) == 0 {
+		return fmt.Errorf("no resources found to document")
+	}
+
+	index, err := generator.GenerateIndex(format, filepath.Base(path), entries)
+	if err != nil {
+		return fmt.Errorf("failed to render index: %w", err)
+	}
+	indexFile := "index" + ext
+	if err := writeOrCheckDocumentation(fs, filepath.Join(opts.OutputDir, indexFile), index, GenerateOptions{Check: opts.Check}); err != nil {
+		if !opts.Check {
+			return err
+		}
+		stale = append(stale, indexFile)
+	}
+
+	if len(stale) > 0 {
+		return fmt.Errorf("%d document(s) in %s are out of date: %s", len(stale), opts.OutputDir, strings.Join(stale, ", "))
+	}
+
+	return nil
+}
+
+// parseModuleInterface parses and merges the interface declared by the files of a module.
+// Files that fail to parse are reported when their resources are parsed.
+func parseModuleInterface(p *parser.CommentParser, files []string) parser.ModuleInterface {
+	var iface parser.ModuleInterface
+	for _, file := range files {
+		fileInterface, err := p.ParseInterface(file)
+		if err != nil {
+			continue
+		}
+		iface.Merge(fileInterface)
+	}
+	return iface
+}
+
+// templateExtension derives the output extension from a template name, e.g. "readme.md.tmpl" -> ".md"
+func templateExtension(templateFile string) string {
+	name := filepath.Base(templateFile)
+	for _, suffix := range []string{".tmpl", ".tpl", ".gotmpl"} {
+		name = strings.TrimSuffix(name, suffix)
+	}
+	if ext := filepath.Ext(name); ext != "" {
+		return ext
+	}
+	return ".md"
+}
+
+// formatForExtension returns the built-in format matching a file extension, defaulting to markdown
+func formatForExtension(ext string) string {
+	for _, format := range generator.Formats {
+		if generator.FileExtension(format) == ext {
+			return format
+		}
+	}
+	return generator.FormatMarkdown
+}
+
+// writeOrCheckDocumentation writes generated documentation to target, injecting it between
+// markers when opts.Inject is set. In check mode it only reports whether target is up to date.
+func writeOrCheckDocumentation(fs afero.Fs, target, output string, opts GenerateOptions) error {
+	existing, err := afero.ReadFile(fs, target)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", target, err)
+	}
+
+	expected := output
+	if opts.Inject != "" {
+		expected, err = generator.InjectDocumentation(string(existing), output)
+		if err != nil {
+			return fmt.Errorf("failed to inject documentation into %s: %w", target, err)
+		}
+	}
+
+	if opts.Check {
+		if string(existing) != expected {
+			return fmt.Errorf("%s is out of date; run 'terranotate generate' to update it", target)
+		}
+		fmt.Printf("✅ %s is up to date\n", target)
+		return nil
+	}
+
+	if string(existing) == expected {
+		fmt.Printf("✅ %s is already up to date\n", target)
+		return nil
+	}
+
+	// #nosec G306 - Documentation file, 0644 is appropriate
+	if err := afero.WriteFile(fs, target, []byte(expected), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", target, err)
+	}
+	if opts.Inject != "" {
+		fmt.Printf("✅ Documentation injected into: %s\n", target)
+	} else {
+		fmt.Printf("✅ Documentation written to: %s\n", target)
+	}
 	return nil
 }
 
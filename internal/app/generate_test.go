@@ -125,3 +125,307 @@ func TestFindTerraformFilesForGeneration(t *testing.T) {
 		}
 	}
 }
+
+func TestGenerateFormats(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	schemaContent := `
+global:
+  prefix_rules:
+    "@metadata":
+      required_fields: ["owner"]
+`
+	if err := afero.WriteFile(fs, "/schema.yaml", []byte(schemaContent), 0644); err != nil {
+		t.Fatalf("failed to write schema: %v", err)
+	}
+	tfContent := `
+# @metadata owner:team-a
+resource "aws_vpc" "main" { cidr_block = "10.0.0.0/16" }
+`
+	if err := afero.WriteFile(fs, "/main.tf", []byte(tfContent), 0644); err != nil {
+		t.Fatalf("failed to write main.tf: %v", err)
+	}
+
+	tests := map[string]string{
+		"html":     "<!DOCTYPE html>",
+		"json":     `"module": "main"`,
+		"csv":      "main,aws_vpc,main,aws_vpc.main,,team-a",
+		"asciidoc": "= main - Resource Documentation",
+	}
+	for format, want := range tests {
+		if err := GenerateWithOptions(fs, "/main.tf", "/schema.yaml", "/out", GenerateOptions{Format: format}); err != nil {
+			t.Fatalf("GenerateWithOptions(%s) failed: %v", format, err)
+		}
+		content, _ := afero.ReadFile(fs, "/out")
+		if !strings.Contains(string(content), want) {
+			t.Errorf("%s output should contain %q, got:\n%s", format, want, content)
+		}
+	}
+
+	if err := GenerateWithOptions(fs, "/main.tf", "/schema.yaml", "", GenerateOptions{Format: "pdf"}); err == nil {
+		t.Error("GenerateWithOptions() should fail for unsupported format")
+	}
+}
+
+func TestGenerateModuleInterface(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	if err := afero.WriteFile(fs, "/schema.yaml", []byte("global: {}\n"), 0644); err != nil {
+		t.Fatalf("failed to write schema: %v", err)
+	}
+	files := map[string]string{
+		"/mod/variables.tf": `
+# @metadata owner:platform
+variable "name" {
+  description = "Name prefix"
+  type        = string
+}
+`,
+		"/mod/outputs.tf":         `output "id" { value = "x" }`,
+		"/mod/child/variables.tf": `variable "child_only" {}`,
+	}
+	for name, content := range files {
+		if err := afero.WriteFile(fs, name, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	// A module without resources is documented by its interface alone
+	if err := Generate(fs, "/mod", "/schema.yaml", "/README.md"); err != nil {
+		t.Fatalf("Generate() failed: %v", err)
+	}
+	content, _ := afero.ReadFile(fs, "/README.md")
+	for _, want := range []string{"## Inputs", "| `name` | Name prefix | `string` | - | yes | no | platform |", "## Outputs", "| `id` | - | no |"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Expected documentation to contain %q, got:\n%s", want, content)
+		}
+	}
+	if strings.Contains(string(content), "child_only") {
+		t.Error("Expected variables of child directories to be left out of the module interface")
+	}
+}
+
+func TestGenerateTemplate(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	if err := afero.WriteFile(fs, "/schema.yaml", []byte("global:\n  required_prefixes: [\"@metadata\"]\n"), 0644); err != nil {
+		t.Fatalf("failed to write schema: %v", err)
+	}
+	tfContent := `
+# @metadata owner:team-a
+resource "aws_vpc" "main" { cidr_block = "10.0.0.0/16" }
+`
+	if err := afero.WriteFile(fs, "/infra/main.tf", []byte(tfContent), 0644); err != nil {
+		t.Fatalf("failed to write main.tf: %v", err)
+	}
+	tmpl := `{{range .Files}}{{.Name}}:{{range .Resources}} {{.Name}}={{field . "owner"}}{{end}}{{end}}`
+	if err := afero.WriteFile(fs, "/doc.tmpl", []byte(tmpl), 0644); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+
+	opts := GenerateOptions{Template: "/doc.tmpl"}
+	if err := GenerateWithOptions(fs, "/infra", "/schema.yaml", "/out.md", opts); err != nil {
+		t.Fatalf("GenerateWithOptions() with template failed: %v", err)
+	}
+	content, _ := afero.ReadFile(fs, "/out.md")
+	if string(content) != "main.tf: main=team-a" {
+		t.Errorf("Unexpected template output: %q", content)
+	}
+
+	if err := afero.WriteFile(fs, "/bad.tmpl", []byte("{{.Nope}}"), 0644); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+	if err := GenerateWithOptions(fs, "/infra", "/schema.yaml", "", GenerateOptions{Template: "/bad.tmpl"}); err == nil {
+		t.Error("GenerateWithOptions() should fail when the template fails to execute")
+	}
+	if err := GenerateWithOptions(fs, "/infra", "/schema.yaml", "", GenerateOptions{Template: "/missing.tmpl"}); err == nil {
+		t.Error("GenerateWithOptions() should fail for a missing template")
+	}
+}
+
+func TestGenerateInject(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	if err := afero.WriteFile(fs, "/schema.yaml", []byte("global:\n  required_prefixes: [\"@metadata\"]\n"), 0644); err != nil {
+		t.Fatalf("failed to write schema: %v", err)
+	}
+	tfContent := `
+# @metadata owner:team-a
+resource "aws_vpc" "main" { cidr_block = "10.0.0.0/16" }
+`
+	if err := afero.WriteFile(fs, "/vpc/main.tf", []byte(tfContent), 0644); err != nil {
+		t.Fatalf("failed to write main.tf: %v", err)
+	}
+	readme := "# VPC\n\nHand-written intro.\n\n<!-- BEGIN_TERRANOTATE -->\n<!-- END_TERRANOTATE -->\n\nHand-written footer.\n"
+	if err := afero.WriteFile(fs, "/vpc/README.md", []byte(readme), 0644); err != nil {
+		t.Fatalf("failed to write README: %v", err)
+	}
+
+	// A README with an empty section is stale
+	check := GenerateOptions{Inject: "/vpc/README.md", Check: true}
+	if err := GenerateWithOptions(fs, "/vpc", "/schema.yaml", "", check); err == nil {
+		t.Error("Expected --check to fail for a stale README")
+	}
+
+	if err := GenerateWithOptions(fs, "/vpc", "/schema.yaml", "", GenerateOptions{Inject: "/vpc/README.md"}); err != nil {
+		t.Fatalf("GenerateWithOptions() with inject failed: %v", err)
+	}
+	content, _ := afero.ReadFile(fs, "/vpc/README.md")
+	for _, want := range []string{"Hand-written intro.", "Hand-written footer.", "## aws_vpc", "`main`"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("README should contain %q, got:\n%s", want, content)
+		}
+	}
+
+	if err := GenerateWithOptions(fs, "/vpc", "/schema.yaml", "", check); err != nil {
+		t.Errorf("Expected --check to pass after injecting: %v", err)
+	}
+
+	// Changing the resources makes the section stale again
+	if err := afero.WriteFile(fs, "/vpc/main.tf", []byte(strings.Replace(tfContent, `"main"`, `"primary"`, 1)), 0644); err != nil {
+		t.Fatalf("failed to write main.tf: %v", err)
+	}
+	if err := GenerateWithOptions(fs, "/vpc", "/schema.yaml", "", check); err == nil {
+		t.Error("Expected --check to fail after resources changed")
+	}
+
+	// --check also works for --output files
+	if err := GenerateWithOptions(fs, "/vpc", "/schema.yaml", "/docs.md", GenerateOptions{}); err != nil {
+		t.Fatalf("GenerateWithOptions() failed: %v", err)
+	}
+	if err := GenerateWithOptions(fs, "/vpc", "/schema.yaml", "/docs.md", GenerateOptions{Check: true}); err != nil {
+		t.Errorf("Expected --check to pass for a fresh output file: %v", err)
+	}
+
+	if err := GenerateWithOptions(fs, "/vpc", "/schema.yaml", "/docs.md", GenerateOptions{Inject: "/vpc/README.md"}); err == nil {
+		t.Error("Expected error when combining --inject and --output")
+	}
+	if err := GenerateWithOptions(fs, "/vpc", "/schema.yaml", "", GenerateOptions{Check: true}); err == nil {
+		t.Error("Expected error for --check without a target file")
+	}
+}
+
+func TestGenerateOutputDir(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	if err := afero.WriteFile(fs, "/schema.yaml", []byte("global:\n  required_prefixes: [\"@metadata\"]\n"), 0644); err != nil {
+		t.Fatalf("failed to write schema: %v", err)
+	}
+	tfContent := `
+# @metadata owner:team-a
+resource "aws_vpc" "main" { cidr_block = "10.0.0.0/16" }
+`
+	for _, file := range []string{
+		"/mono/environments/prod/main.tf",
+		"/mono/environments/staging/main.tf",
+		"/mono/environments/staging/network.tf",
+	} {
+		if err := afero.WriteFile(fs, file, []byte(tfContent), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", file, err)
+		}
+	}
+
+	opts := GenerateOptions{OutputDir: "/docs"}
+	if err := GenerateWithOptions(fs, "/mono", "/schema.yaml", "", opts); err != nil {
+		t.Fatalf("GenerateWithOptions() with output dir failed: %v", err)
+	}
+
+	for _, file := range []string{"/docs/environments-prod.md", "/docs/environments-staging.md", "/docs/index.md"} {
+		if exists, _ := afero.Exists(fs, file); !exists {
+			t.Errorf("Expected %s to exist", file)
+		}
+	}
+
+	index, _ := afero.ReadFile(fs, "/docs/index.md")
+	if !strings.Contains(string(index), "| [environments/staging](environments-staging.md) | 2 | 1 |") {
+		t.Errorf("Unexpected index:\n%s", index)
+	}
+	staging, _ := afero.ReadFile(fs, "/docs/environments-staging.md")
+	if !strings.Contains(string(staging), "# environments/staging - Resource Documentation") {
+		t.Errorf("Unexpected module document:\n%s", staging)
+	}
+
+	// Check mode passes while docs are fresh and fails once a module changes
+	check := GenerateOptions{OutputDir: "/docs", Check: true}
+	if err := GenerateWithOptions(fs, "/mono", "/schema.yaml", "", check); err != nil {
+		t.Errorf("Expected --check to pass: %v", err)
+	}
+	if err := afero.WriteFile(fs, "/mono/environments/prod/extra.tf", []byte(`resource "aws_s3_bucket" "logs" {}`), 0644); err != nil {
+		t.Fatalf("failed to write extra.tf: %v", err)
+	}
+	if err := GenerateWithOptions(fs, "/mono", "/schema.yaml", "", check); err == nil {
+		t.Error("Expected --check to fail after a module changed")
+	}
+
+	// Templates choose the document extension from their name
+	if err := afero.WriteFile(fs, "/page.html.tmpl", []byte("<p>{{.Title}}</p>"), 0644); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+	if err := GenerateWithOptions(fs, "/mono", "/schema.yaml", "", GenerateOptions{OutputDir: "/site", Template: "/page.html.tmpl"}); err != nil {
+		t.Fatalf("GenerateWithOptions() with template failed: %v", err)
+	}
+	for _, file := range []string{"/site/environments-prod.html", "/site/index.html"} {
+		if exists, _ := afero.Exists(fs, file); !exists {
+			t.Errorf("Expected %s to exist", file)
+		}
+	}
+
+	if err := GenerateWithOptions(fs, "/mono/environments/prod/main.tf", "/schema.yaml", "", opts); err == nil {
+		t.Error("Expected error for --output-dir with a single file")
+	}
+	if err := GenerateWithOptions(fs, "/mono", "/schema.yaml", "/out.md", opts); err == nil {
+		t.Error("Expected error when combining --output-dir and --output")
+	}
+}
+
+func TestGenerateValidate(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	schema := `global:
+  required_prefixes: ["@metadata"]
+  prefix_rules:
+    "@metadata":
+      required_fields: [owner]
+`
+	if err := afero.WriteFile(fs, "/schema.yaml", []byte(schema), 0644); err != nil {
+		t.Fatalf("failed to write schema: %v", err)
+	}
+	files := map[string]string{
+		"/mono/environments/prod/main.tf": `
+# @metadata owner:team-a
+resource "aws_vpc" "main" {}
+`,
+		"/mono/environments/staging/main.tf": `
+resource "aws_vpc" "main" {}
+`,
+	}
+	for file, content := range files {
+		if err := afero.WriteFile(fs, file, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", file, err)
+		}
+	}
+
+	if err := GenerateWithOptions(fs, "/mono/environments/staging/main.tf", "/schema.yaml", "/out.md", GenerateOptions{Validate: true}); err != nil {
+		t.Fatalf("GenerateWithOptions() with validation failed: %v", err)
+	}
+	out, _ := afero.ReadFile(fs, "/out.md")
+	for _, want := range []string{"| `main` | - | ❌ fail | @metadata |", "**Annotation Coverage:** 0.0% (0/1 annotated, 0 passing)"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("Output should contain %q, got:\n%s", want, out)
+		}
+	}
+
+	if err := GenerateWithOptions(fs, "/mono", "/schema.yaml", "", GenerateOptions{OutputDir: "/docs", Validate: true}); err != nil {
+		t.Fatalf("GenerateWithOptions() with output dir failed: %v", err)
+	}
+	index, _ := afero.ReadFile(fs, "/docs/index.md")
+	for _, want := range []string{
+		"| [environments/prod](environments-prod.md) | 1 | 1 | 100.0% (1/1 annotated, 1 passing) |",
+		"| [environments/staging](environments-staging.md) | 1 | 1 | 0.0% (0/1 annotated, 0 passing) |",
+		"**Annotation Coverage:** 50.0% (1/2 annotated, 1 passing)",
+	} {
+		if !strings.Contains(string(index), want) {
+			t.Errorf("Index should contain %q, got:\n%s", want, index)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/toozej/terranotate/internal/diff"
+	"github.com/toozej/terranotate/internal/fixer"
+	"github.com/toozej/terranotate/internal/inherit"
+	"github.com/toozej/terranotate/internal/journal"
+	"github.com/toozej/terranotate/internal/sidecar"
+	"gopkg.in/yaml.v3"
+)
+
+// Migrate implements the migrate command logic, rewriting annotations written for schema
+// version from to the schema's current version using the schema's migrations section
+func Migrate(fs afero.Fs, path, schemaFile string, from int, dryRun bool) error {
+	fmt.Println("=================================================")
+	fmt.Println("Terranotate - Migrate Annotations")
+	fmt.Println("=================================================")
+	fmt.Printf("Path: %s\n", path)
+	fmt.Printf("Schema file: %s\n", schemaFile)
+
+	schema, err := loadSchema(fs, schemaFile)
+	if err != nil {
+		return fmt.Errorf("failed to load schema: %w", err)
+	}
+
+	info, err := fs.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to stat path: %w", err)
+	}
+	dir := path
+	if !info.IsDir() {
+		dir = filepath.Dir(path)
+	}
+
+	// Migrations already applied are recorded, so migrating again is a no-op
+	recorded, recordFile, err := recordedSchemaVersion(fs, dir)
+	if err != nil {
+		return err
+	}
+	if recorded > from {
+		fmt.Printf("Annotations are recorded at version %d in %s\n", recorded, recordFile)
+		from = recorded
+	}
+
+	migrations, err := schema.PendingMigrations(from)
+	if err != nil {
+		return fmt.Errorf("invalid schema migrations: %w", err)
+	}
+
+	target := schema.Version
+	if target == 0 && len(migrations) > 0 {
+		target = migrations[len(migrations)-1].Version
+	}
+	fmt.Printf("Migrating annotations from version %d to %d\n", from, target)
+	if dryRun {
+		fmt.Println("Mode: dry-run (no files will be written)")
+	}
+	fmt.Println()
+
+	if len(migrations) == 0 {
+		fmt.Println("✅ No migrations to apply - annotations are already at the schema version")
+		return nil
+	}
+	for _, m := range migrations {
+		fmt.Printf("  v%d: %s\n", m.Version, m.Description)
+	}
+	fmt.Println()
+
+	files := []string{path}
+	if info.IsDir() {
+		files, err = findTerraformFiles(fs, path)
+		if err != nil {
+			return fmt.Errorf("failed to find terraform files: %w", err)
+		}
+	}
+
+	j, err := journal.New(fs, path)
+	if err != nil {
+		return fmt.Errorf("failed to open fix journal: %w", err)
+	}
+	run := j.NewRun("migrate")
+
+	// Sidecar files are migrated in memory and parsed from there until they are written
+	p := newParser(fs, schema, repositoryFiles(fs, path))
+	sidecars := newSidecarEdits(fs)
+	p.SetSidecarResolver(sidecars.resolver(p))
+	f := fixer.NewCommentFixer(fs, schema)
+
+	type fileIssue struct {
+		file string
+		fixer.MigrationIssue
+	}
+	var issues []fileIssue
+	reported := make(map[string]bool) // Inherited annotations are shared, so reported once
+	filesChanged := 0
+
+	for _, file := range files {
+		original, err := afero.ReadFile(fs, file)
+		if err != nil {
+			log.Printf("Warning: Failed to read %s: %v", file, err)
+			continue
+		}
+
+		var sidecarFiles []*sidecar.File
+		for _, sc := range sidecars.load(filepath.Dir(file)) {
+			sidecarFiles = append(sidecarFiles, sc.file)
+		}
+
+		content := string(original)
+		for _, m := range migrations {
+			var migrationIssues []fixer.MigrationIssue
+			content, migrationIssues, err = f.ApplyMigration(p, file, content, sidecarFiles, m)
+			if err != nil {
+				return fmt.Errorf("failed to migrate %s to version %d: %w", file, m.Version, err)
+			}
+			for _, issue := range migrationIssues {
+				if issue.Source != "" {
+					key := fmt.Sprintf("%d %s %s", issue.Version, issue.Source, issue.Message)
+					if reported[key] {
+						continue
+					}
+					reported[key] = true
+				}
+				issues = append(issues, fileIssue{file: file, MigrationIssue: issue})
+			}
+		}
+
+		if content == string(original) {
+			continue
+		}
+		filesChanged++
+
+		if dryRun {
+			fmt.Print(diff.Unified(file, string(original), content))
+			continue
+		}
+
+		// #nosec G306 - Writing source code (Terraform), 0644 is appropriate
+		if err := afero.WriteFile(fs, file, []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file, err)
+		}
+		if err := j.Record(run, file, original, []byte(content)); err != nil {
+			return fmt.Errorf("failed to record migration in journal: %w", err)
+		}
+		fmt.Printf("  ✅ Migrated %s\n", file)
+	}
+
+	written, err := sidecars.write(dryRun, j, run)
+	if err != nil {
+		return err
+	}
+	filesChanged += written
+
+	// Only a complete migration is recorded, so annotations left behind are retried
+	if !dryRun && len(issues) == 0 {
+		if err := recordSchemaVersion(fs, dir, target, j, run); err != nil {
+			return err
+		}
+		fmt.Printf("  📝 Recorded version %d in %s\n", target, filepath.Join(dir, inherit.DefaultsFile))
+	}
+
+	if len(issues) > 0 {
+		fmt.Println("\n⚠️  Annotations that could not be migrated:")
+		for _, issue := range issues {
+			if issue.Source != "" {
+				fmt.Printf("  %s (v%d): %s\n", issue.Source, issue.Version, issue.Message)
+				continue
+			}
+			fmt.Printf("  %s:%d %s (v%d): %s\n", issue.file, issue.Line, issue.Resource, issue.Version, issue.Message)
+		}
+	}
+
+	fmt.Println("\n" + strings.Repeat("=", 50))
+	fmt.Printf("Migration Summary: %d files processed, %d files migrated, %d issues\n", len(files), filesChanged, len(issues))
+	fmt.Println(strings.Repeat("=", 50))
+
+	if !dryRun && len(run.Files) > 0 {
+		journalFile, err := j.Save(run)
+		if err != nil {
+			return fmt.Errorf("failed to save fix journal: %w", err)
+		}
+		fmt.Printf("\n📒 Journal saved as: %s\n", journalFile)
+		fmt.Printf("💡 Undo with: terranotate fix --revert --run %s %s\n", run.ID, path)
+	}
+
+	if len(issues) > 0 {
+		return fmt.Errorf("%d annotation(s) could not be migrated and need manual changes", len(issues))
+	}
+
+	return nil
+}
+
+// recordedSchemaVersion returns the schema version annotations were last migrated to, recorded
+// as schema_version in the DefaultsFile of the directory or its closest parent up to the
+// repository root, and the file it is recorded in. It returns 0 when none is recorded.
+func recordedSchemaVersion(fs afero.Fs, dir string) (int, string, error) {
+	current := filepath.Clean(dir)
+	if abs, err := filepath.Abs(current); err == nil {
+		current = abs
+	}
+	for {
+		path := filepath.Join(current, inherit.DefaultsFile)
+		if data, err := afero.ReadFile(fs, path); err == nil {
+			var defaults struct {
+				SchemaVersion int `yaml:"schema_version"`
+			}
+			if err := yaml.Unmarshal(data, &defaults); err != nil {
+				return 0, "", fmt.Errorf("failed to parse %s: %w", path, err)
+			}
+			if defaults.SchemaVersion > 0 {
+				return defaults.SchemaVersion, path, nil
+			}
+		}
+
+		// Versions are only looked up within the repository
+		if exists, _ := afero.Exists(fs, filepath.Join(current, ".git")); exists {
+			return 0, "", nil
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return 0, "", nil
+		}
+		current = parent
+	}
+}
+
+// recordSchemaVersion sets schema_version in the DefaultsFile of a directory, keeping the rest
+// of the file, and records the change in the journal
+func recordSchemaVersion(fs afero.Fs, dir string, version int, j *journal.Journal, run *journal.Run) error {
+	path := filepath.Join(dir, inherit.DefaultsFile)
+
+	// #nosec G304 - Defaults file in a directory provided by user via CLI
+	original, readErr := afero.ReadFile(fs, path)
+
+	var doc yaml.Node
+	if readErr == nil {
+		if err := yaml.Unmarshal(original, &doc); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("failed to record schema version in %s: expected a mapping", path)
+	}
+
+	value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(version)}
+	found := false
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "schema_version" {
+			root.Content[i+1] = value
+			found = true
+		}
+	}
+	if !found {
+		key := &yaml.Node{Kind: yaml.ScalarNode, Value: "schema_version"}
+		root.Content = append(root.Content, key, value)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+	content := buf.Bytes()
+	if readErr == nil && bytes.Equal(content, original) {
+		return nil
+	}
+
+	// #nosec G306 - Defaults files are committed alongside Terraform source, 0644 is appropriate
+	if err := afero.WriteFile(fs, path, content, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if readErr != nil {
+		return j.RecordCreated(run, path, content)
+	}
+	return j.Record(run, path, original, content)
+}
@@ -0,0 +1,234 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestMigrate(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	if err := fs.MkdirAll("/repo/.git", 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+
+	tfContent := `# @metadata owner:alice cost_center:cc-1 environment:prod
+resource "aws_vpc" "main" {
+  cidr_block = "10.0.0.0/16"
+}
+`
+	if err := afero.WriteFile(fs, "/repo/main.tf", []byte(tfContent), 0644); err != nil {
+		t.Fatalf("failed to write main.tf: %v", err)
+	}
+
+	schemaContent := `version: 3
+migrations:
+  - version: 2
+    description: Rename cost center
+    rename:
+      cost_center: costcenter
+  - version: 3
+    description: Use long environment names
+    map_values:
+      environment:
+        prod: production
+`
+	if err := afero.WriteFile(fs, "/repo/schema.yaml", []byte(schemaContent), 0644); err != nil {
+		t.Fatalf("failed to write schema: %v", err)
+	}
+
+	// Dry run leaves the file untouched
+	if err := Migrate(fs, "/repo", "/repo/schema.yaml", 0, true); err != nil {
+		t.Fatalf("Migrate() dry run failed: %v", err)
+	}
+	content, _ := afero.ReadFile(fs, "/repo/main.tf")
+	if string(content) != tfContent {
+		t.Errorf("Dry run modified file:\n%s", content)
+	}
+
+	if exists, _ := afero.Exists(fs, "/repo/.terranotate.yaml"); exists {
+		t.Error("Dry run recorded the schema version")
+	}
+
+	// Starting at version 2 only maps values
+	if err := Migrate(fs, "/repo", "/repo/schema.yaml", 2, false); err != nil {
+		t.Fatalf("Migrate() from version 2 failed: %v", err)
+	}
+	content, _ = afero.ReadFile(fs, "/repo/main.tf")
+	if !strings.Contains(string(content), "cost_center:cc-1 environment:production") {
+		t.Errorf("Expected only environment to be migrated, got:\n%s", content)
+	}
+	record, _ := afero.ReadFile(fs, "/repo/.terranotate.yaml")
+	if string(record) != "schema_version: 3\n" {
+		t.Errorf("Expected the schema version to be recorded, got %q", record)
+	}
+
+	// The recorded version makes migrating again a no-op, whatever --from says
+	if err := Migrate(fs, "/repo", "/repo/schema.yaml", 0, false); err != nil {
+		t.Fatalf("Migrate() again failed: %v", err)
+	}
+	if again, _ := afero.ReadFile(fs, "/repo/main.tf"); string(again) != string(content) {
+		t.Errorf("Expected migrating again to be a no-op, got:\n%s", again)
+	}
+
+	// Reverting the migration removes the record too
+	if err := RevertFix(fs, "/repo", ""); err != nil {
+		t.Fatalf("RevertFix() failed: %v", err)
+	}
+	if exists, _ := afero.Exists(fs, "/repo/.terranotate.yaml"); exists {
+		t.Error("Expected revert to remove the recorded version")
+	}
+
+	if err := Migrate(fs, "/repo", "/repo/schema.yaml", 0, false); err != nil {
+		t.Fatalf("Migrate() failed: %v", err)
+	}
+	content, _ = afero.ReadFile(fs, "/repo/main.tf")
+	if !strings.Contains(string(content), "# @metadata owner:alice costcenter:cc-1 environment:production") {
+		t.Errorf("Expected all migrations to be applied, got:\n%s", content)
+	}
+
+	// Conflicts are reported as an error, left unchanged and not recorded
+	if err := fs.Remove("/repo/.terranotate.yaml"); err != nil {
+		t.Fatalf("failed to remove recorded version: %v", err)
+	}
+	conflict := strings.Replace(tfContent, "owner:alice", "owner:alice costcenter:cc-9", 1)
+	if err := afero.WriteFile(fs, "/repo/main.tf", []byte(conflict), 0644); err != nil {
+		t.Fatalf("failed to write main.tf: %v", err)
+	}
+	if err := Migrate(fs, "/repo", "/repo/schema.yaml", 0, false); err == nil {
+		t.Error("Expected error for annotation that could not be migrated")
+	}
+	content, _ = afero.ReadFile(fs, "/repo/main.tf")
+	if !strings.Contains(string(content), "cost_center:cc-1") {
+		t.Errorf("Expected conflicting field to be left unchanged, got:\n%s", content)
+	}
+	if exists, _ := afero.Exists(fs, "/repo/.terranotate.yaml"); exists {
+		t.Error("Expected an incomplete migration not to be recorded")
+	}
+}
+
+func TestMigrateSidecar(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	files := map[string]string{
+		"/repo/.git/HEAD": "ref: refs/heads/main\n",
+		"/repo/.terranotate.yaml": `# Defaults for every directory
+annotations:
+  "@metadata":
+    team: platform
+`,
+		"/repo/vpc/main.tf": `resource "aws_vpc" "main" {}
+`,
+		"/repo/vpc/terranotate.yaml": `resources:
+  aws_vpc.main:
+    "@metadata":
+      owner: alice.smith
+      cost_center: cc-1
+      environment: prod
+`,
+		"/repo/schema.yaml": `version: 2
+migrations:
+  - version: 2
+    rename:
+      cost_center: costcenter
+    split:
+      - field: owner
+        separator: "."
+        into: [owner_first, owner_last]
+    map_values:
+      environment:
+        prod: production
+`,
+	}
+	for name, content := range files {
+		if err := afero.WriteFile(fs, name, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	if err := Migrate(fs, "/repo", "/repo/schema.yaml", 0, false); err != nil {
+		t.Fatalf("Migrate() failed: %v", err)
+	}
+
+	content, _ := afero.ReadFile(fs, "/repo/vpc/terranotate.yaml")
+	expected := `resources:
+  aws_vpc.main:
+    "@metadata":
+      costcenter: cc-1
+      environment: production
+      owner_first: alice
+      owner_last: smith
+`
+	if string(content) != expected {
+		t.Errorf("Unexpected sidecar file:\n%s", content)
+	}
+	if content, _ := afero.ReadFile(fs, "/repo/vpc/main.tf"); string(content) != files["/repo/vpc/main.tf"] {
+		t.Errorf("Expected main.tf to be unchanged, got:\n%s", content)
+	}
+
+	// The version is recorded alongside the existing defaults
+	record, _ := afero.ReadFile(fs, "/repo/.terranotate.yaml")
+	if !strings.HasSuffix(string(record), "schema_version: 2\n") || !strings.Contains(string(record), "team: platform") {
+		t.Errorf("Expected the version to be added to the defaults file, got:\n%s", record)
+	}
+}
+
+func TestMigrateInherited(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	if err := fs.MkdirAll("/repo/.git", 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	tfContent := `# @metadata(file) cost_center:abc
+
+# @metadata owner:alice
+resource "aws_vpc" "main" {
+  cidr_block = "10.0.0.0/16"
+}
+`
+	if err := afero.WriteFile(fs, "/repo/main.tf", []byte(tfContent), 0644); err != nil {
+		t.Fatalf("failed to write main.tf: %v", err)
+	}
+	defaults := "annotations:\n  \"@metadata\":\n    cost_center: x\n"
+	if err := afero.WriteFile(fs, "/repo/.terranotate.yaml", []byte(defaults), 0644); err != nil {
+		t.Fatalf("failed to write defaults: %v", err)
+	}
+	schemaContent := `version: 2
+migrations:
+  - version: 2
+    description: Rename cost center
+    rename:
+      cost_center: costcenter
+`
+	if err := afero.WriteFile(fs, "/repo/schema.yaml", []byte(schemaContent), 0644); err != nil {
+		t.Fatalf("failed to write schema: %v", err)
+	}
+
+	// The file header is migrated, the directory defaults are reported and nothing is recorded
+	if err := Migrate(fs, "/repo", "/repo/schema.yaml", 0, false); err == nil {
+		t.Error("Expected error for inherited annotation that could not be migrated")
+	}
+	content, _ := afero.ReadFile(fs, "/repo/main.tf")
+	if !strings.HasPrefix(string(content), "# @metadata(file) costcenter:abc\n") {
+		t.Errorf("Expected the file header to be migrated, got:\n%s", content)
+	}
+	record, _ := afero.ReadFile(fs, "/repo/.terranotate.yaml")
+	if string(record) != defaults {
+		t.Errorf("Expected directory defaults to be left unchanged and the version not recorded, got %q", record)
+	}
+
+	// Once the defaults are migrated by hand the migration completes
+	defaults = "annotations:\n  \"@metadata\":\n    costcenter: x\n"
+	if err := afero.WriteFile(fs, "/repo/.terranotate.yaml", []byte(defaults), 0644); err != nil {
+		t.Fatalf("failed to write defaults: %v", err)
+	}
+	if err := Migrate(fs, "/repo", "/repo/schema.yaml", 0, false); err != nil {
+		t.Fatalf("Migrate() failed: %v", err)
+	}
+	record, _ = afero.ReadFile(fs, "/repo/.terranotate.yaml")
+	if !strings.HasSuffix(string(record), "schema_version: 2\n") {
+		t.Errorf("Expected the schema version to be recorded, got %q", record)
+	}
+}
@@ -0,0 +1,178 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/toozej/terranotate/internal/owners"
+	"github.com/toozej/terranotate/internal/parser"
+	"github.com/toozej/terranotate/internal/repo"
+	"github.com/toozej/terranotate/internal/validator"
+)
+
+// OwnersOptions configures the owners command. Empty fields fall back to the schema's owners
+// section, then to defaults.
+type OwnersOptions struct {
+	Format   string // owners.FormatCODEOWNERS (default), owners.FormatJSON or owners.FormatYAML
+	Output   string // Write to this file instead of stdout
+	By       string // Aggregate per owners.ByDirectory (default) or owners.ByFile
+	Strategy string // owners.StrategyMajority (default) or owners.StrategyAll
+	Org      string // GitHub organization teams belong to
+	Field    string // @metadata field naming the owning team (default "team")
+}
+
+// Owners implements the owners command logic, exporting @metadata ownership annotations as a
+// CODEOWNERS file or an ownership map
+func Owners(fs afero.Fs, path, schemaFile string, opts OwnersOptions) error {
+	fmt.Println("=================================================")
+	fmt.Println("Terranotate - Ownership Export")
+	fmt.Println("=================================================")
+	fmt.Printf("Path: %s\n", path)
+	if schemaFile != "" {
+		fmt.Printf("Schema file: %s\n", schemaFile)
+	}
+	if opts.Output != "" {
+		fmt.Printf("Output: %s\n", opts.Output)
+	} else {
+		fmt.Println("Output: stdout")
+	}
+	fmt.Println()
+
+	ownership, err := CollectOwners(fs, path, schemaFile, opts)
+	if err != nil {
+		return err
+	}
+
+	content, err := ownership.Render(opts.Format)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("👥 %d resource(s), %d ownership rule(s)\n", len(ownership.Resources), len(ownership.Entries))
+	for _, entry := range ownership.Entries {
+		if len(entry.Conflicts) > 0 {
+			fmt.Printf("⚠️  %s: %s selected over %s\n", entry.Path, strings.Join(entry.Owners, " "), strings.Join(entry.Conflicts, " "))
+		}
+	}
+	if len(ownership.Unowned) > 0 {
+		fmt.Printf("⚠️  %d resource(s) without a team: %s\n", len(ownership.Unowned), strings.Join(ownership.Unowned, ", "))
+	}
+
+	if opts.Output == "" {
+		fmt.Println()
+		fmt.Print(content)
+		return nil
+	}
+
+	if err := afero.WriteFile(fs, opts.Output, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	fmt.Printf("\n✅ Ownership written to: %s\n", opts.Output)
+	return nil
+}
+
+// CollectOwners builds the ownership map of the Terraform files under path. Paths are relative
+// to the enclosing git repository, as CODEOWNERS expects.
+func CollectOwners(fs afero.Fs, path, schemaFile string, opts OwnersOptions) (owners.Map, error) {
+	var schema validator.ValidationSchema
+	if schemaFile != "" {
+		var err error
+		schema, err = loadSchema(fs, schemaFile)
+		if err != nil {
+			return owners.Map{}, fmt.Errorf("failed to load schema: %w", err)
+		}
+	}
+
+	v, err := validator.NewSchemaValidatorFromSchema(fs, schema)
+	if err != nil {
+		return owners.Map{}, fmt.Errorf("failed to load schema: %w", err)
+	}
+
+	config := schema.Owners
+	field := firstNonEmpty(opts.Field, config.Field, "team")
+	buildOpts := owners.Options{
+		Org:       firstNonEmpty(opts.Org, config.Org),
+		Strategy:  firstNonEmpty(opts.Strategy, config.Strategy),
+		By:        opts.By,
+		Overrides: config.Overrides,
+	}
+
+	info, err := fs.Stat(path)
+	if err != nil {
+		return owners.Map{}, fmt.Errorf("failed to stat path: %w", err)
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		files, err = findTerraformFiles(fs, path)
+		if err != nil {
+			return owners.Map{}, fmt.Errorf("failed to find terraform files: %w", err)
+		}
+	}
+
+	// Paths are relative to the enclosing git repository, falling back to path itself (or the
+	// directory of a file)
+	start := path
+	if !info.IsDir() {
+		start = filepath.Dir(path)
+	}
+	if abs, err := filepath.Abs(start); err == nil {
+		start = abs
+	}
+	root, ok := repo.Root(fs, start)
+	if !ok {
+		root = start
+	}
+
+	p := newParser(fs, schema, files)
+
+	var resources []owners.Resource
+	for _, file := range files {
+		parsed, err := p.ParseFile(file)
+		if err != nil {
+			log.Printf("Warning: Failed to parse %s: %v", file, err)
+			continue
+		}
+
+		rel := file
+		if abs, err := filepath.Abs(file); err == nil {
+			if r, err := filepath.Rel(root, abs); err == nil {
+				rel = r
+			}
+		}
+
+		for _, resource := range parsed {
+			resources = append(resources, owners.Resource{
+				Address: resource.Address(),
+				File:    filepath.ToSlash(rel),
+				Team:    annotationString(v, resource, field),
+				Owner:   annotationString(v, resource, "owner"),
+				Contact: resource.GetNestedField("@metadata", "contact"),
+			})
+		}
+	}
+
+	return owners.Build(resources, buildOpts)
+}
+
+// annotationString returns a @metadata field as a string, or "" if it is unset or a placeholder
+func annotationString(v *validator.SchemaValidator, resource parser.TerraformResource, field string) string {
+	value := resource.GetNestedField("@metadata", field)
+	if value == nil || v.IsPlaceholder(value) {
+		return ""
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// firstNonEmpty returns the first non-empty value
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
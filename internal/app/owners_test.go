@@ -0,0 +1,67 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestOwners(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	schema := `owners:
+  org: acme
+  overrides:
+    infra/modules/legacy: ["@acme/archive"]
+`
+	files := map[string]string{
+		"/schema.yaml":    schema,
+		"/repo/.git/HEAD": "ref: refs/heads/main\n",
+		"/repo/infra/modules/network/main.tf": `
+# @metadata owner:jane.doe team:network
+resource "aws_vpc" "main" {}
+`,
+		"/repo/infra/modules/legacy/main.tf": `
+# @metadata owner:bob.smith team:platform
+resource "aws_instance" "old" {}
+`,
+		"/repo/infra/main.tf": `
+# @metadata owner:CHANGEME team:CHANGEME
+resource "aws_s3_bucket" "state" {}
+`,
+	}
+	for file, content := range files {
+		if err := afero.WriteFile(fs, file, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", file, err)
+		}
+	}
+
+	ownership, err := CollectOwners(fs, "/repo/infra", "/schema.yaml", OwnersOptions{})
+	if err != nil {
+		t.Fatalf("CollectOwners() failed: %v", err)
+	}
+	// Paths are relative to the git repository, and overrides replace annotated owners
+	if len(ownership.Entries) != 2 || ownership.Entries[0].Path != "/infra/modules/legacy/" || ownership.Entries[0].Owners[0] != "@acme/archive" || ownership.Entries[1].Owners[0] != "@acme/network" {
+		t.Errorf("Unexpected entries: %+v", ownership.Entries)
+	}
+	// Placeholder teams are unowned
+	if len(ownership.Unowned) != 1 || ownership.Unowned[0] != "aws_s3_bucket.state" {
+		t.Errorf("Expected aws_s3_bucket.state to be unowned, got %v", ownership.Unowned)
+	}
+
+	if err := Owners(fs, "/repo/infra", "/schema.yaml", OwnersOptions{Output: "/repo/.github/CODEOWNERS", Org: "other"}); err != nil {
+		t.Fatalf("Owners() failed: %v", err)
+	}
+	data, err := afero.ReadFile(fs, "/repo/.github/CODEOWNERS")
+	if err != nil {
+		t.Fatalf("Expected CODEOWNERS to be written: %v", err)
+	}
+	if !strings.Contains(string(data), "/infra/modules/network/ @other/network\n") {
+		t.Errorf("Expected --org to override the schema, got:\n%s", data)
+	}
+
+	if err := Owners(fs, "/repo/infra", "", OwnersOptions{Format: "xml"}); err == nil {
+		t.Error("Expected error for an unsupported format")
+	}
+}
@@ -2,21 +2,32 @@ package app
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/spf13/afero"
 	"github.com/toozej/terranotate/internal/parser"
+	"github.com/toozej/terranotate/internal/validator"
 )
 
+// ParseOptions configures the parse command
+type ParseOptions struct {
+	Explain bool // Show every effective annotation value and where it comes from
+}
+
 // Parse implements the parse command logic
 func Parse(fs afero.Fs, filename string) error {
+	return ParseWithOptions(fs, filename, ParseOptions{})
+}
+
+// ParseWithOptions parses a Terraform file and prints its annotations with the given options
+func ParseWithOptions(fs afero.Fs, filename string, opts ParseOptions) error {
 	fmt.Println("=================================================")
 	fmt.Println("Terranotate - Terraform Comment Parser")
 	fmt.Println("\n=================================================")
 
-	// Define the comment prefixes you want to parse
-	prefixes := []string{"@metadata", "@docs", "@validation", "@config"}
-
-	p := parser.NewCommentParser(fs, prefixes)
+	// Module calls are resolved from the parent directories too, so that annotations on the
+	// module blocks calling this file's module are inherited
+	p := newParser(fs, validator.ValidationSchema{}, repositoryFiles(fs, filename))
 
 	// Parse the Terraform file
 	resources, err := p.ParseFile(filename)
@@ -27,8 +38,8 @@ func Parse(fs afero.Fs, filename string) error {
 	fmt.Printf("Found %d resources in %s\n\n", len(resources), filename)
 
 	for _, resource := range resources {
-		fmt.Printf("\n📦 Resource: %s.%s (lines %d-%d)\n",
-			resource.Type, resource.Name, resource.StartLine, resource.EndLine)
+		fmt.Printf("\n📦 Resource: %s (lines %d-%d)\n",
+			resource.Address(), resource.StartLine, resource.EndLine)
 
 		if len(resource.PrecedingComments) > 0 {
 			fmt.Println("\n  📝 Preceding Comments:")
@@ -45,11 +56,90 @@ func Parse(fs afero.Fs, filename string) error {
 				printFields(comment.Fields, "      ")
 			}
 		}
+
+		if len(resource.AttributeComments) > 0 || len(resource.Blocks) > 0 {
+			fmt.Println("\n  🔖 Attribute and Block Comments:")
+			paths := make([]string, 0, len(resource.AttributeComments))
+			for path := range resource.AttributeComments {
+				paths = append(paths, path)
+			}
+			sort.Strings(paths)
+			for _, path := range paths {
+				printPathComments(path, resource.AttributeComments[path])
+			}
+			for _, block := range resource.Blocks {
+				printPathComments(block.Path+" {}", block.Comments)
+			}
+		}
+
+		if len(resource.SidecarComments) > 0 {
+			fmt.Println("\n  🗂️  Sidecar Annotations:")
+			for _, comment := range resource.SidecarComments {
+				fmt.Printf("    %s from %s\n", comment.Prefix, comment.Source)
+				printFields(comment.Fields, "      ")
+			}
+		}
+
+		if len(resource.Inherited) > 0 {
+			fmt.Println("\n  🧬 Inherited Annotations:")
+			for _, comment := range resource.Inherited {
+				fmt.Printf("    %s from %s\n", comment.Prefix, comment.Source)
+				printFields(comment.Fields, "      ")
+			}
+		}
+
+		if opts.Explain {
+			printEffectiveAnnotations(resource)
+		}
 	}
 
 	return nil
 }
 
+// printEffectiveAnnotations prints every effective annotation field of a resource with the
+// place its value comes from
+func printEffectiveAnnotations(resource parser.TerraformResource) {
+	var prefixes []string
+	seen := make(map[string]bool)
+	for _, comment := range append(resource.Comments(), resource.Inherited...) {
+		if !seen[comment.Prefix] {
+			seen[comment.Prefix] = true
+			prefixes = append(prefixes, comment.Prefix)
+		}
+	}
+	if len(prefixes) == 0 {
+		return
+	}
+
+	fmt.Println("\n  🔎 Effective Annotations:")
+	for _, prefix := range prefixes {
+		annotation, _ := resource.GetAnnotation(prefix)
+		fmt.Printf("    %s\n", prefix)
+
+		paths := make([]string, 0, len(annotation.RawValues))
+		for path := range annotation.RawValues {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+
+		for _, path := range paths {
+			source, inherited := annotation.Sources[path]
+			if !inherited {
+				source = fmt.Sprintf("resource (%s:%d)", resource.File, annotation.FieldLines[path])
+			}
+			fmt.Printf("      %s: %s  ← %s\n", path, annotation.RawValues[path], source)
+		}
+	}
+}
+
+// printPathComments prints the comments on an attribute or nested block
+func printPathComments(path string, comments []parser.StructuredComment) {
+	for _, comment := range comments {
+		fmt.Printf("    %s [Lines %d-%d] %s\n", path, comment.Line, comment.EndLine, comment.Prefix)
+		printFields(comment.Fields, "      ")
+	}
+}
+
 // printFields recursively prints nested field structures
 func printFields(fields map[string]interface{}, indent string) {
 	for k, v := range fields {
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/afero"
+	"github.com/toozej/terranotate/internal/diff"
+	"github.com/toozej/terranotate/internal/journal"
+	"github.com/toozej/terranotate/internal/parser"
+	"github.com/toozej/terranotate/internal/sidecar"
+)
+
+// sidecarEdit is a sidecar file being edited with its content before the edits
+type sidecarEdit struct {
+	file     *sidecar.File
+	original []byte
+}
+
+// sidecarEdits holds the sidecar files edited by a command until they are written
+type sidecarEdits struct {
+	fs    afero.Fs
+	files map[string][]*sidecarEdit // Sidecar files by directory
+}
+
+// newSidecarEdits creates an empty set of sidecar edits
+func newSidecarEdits(fs afero.Fs) *sidecarEdits {
+	return &sidecarEdits{fs: fs, files: make(map[string][]*sidecarEdit)}
+}
+
+// load returns the sidecar files that exist in a directory, loading them once
+func (se *sidecarEdits) load(dir string) []*sidecarEdit {
+	if edits, exists := se.files[dir]; exists {
+		return edits
+	}
+
+	var edits []*sidecarEdit
+	for _, name := range sidecar.FileNames {
+		path := filepath.Join(dir, name)
+		original, err := afero.ReadFile(se.fs, path)
+		if err != nil {
+			continue
+		}
+		file, err := sidecar.Load(se.fs, path)
+		if err != nil {
+			log.Printf("Warning: %v", err)
+			continue
+		}
+		edits = append(edits, &sidecarEdit{file: file, original: original})
+	}
+
+	se.files[dir] = edits
+	return edits
+}
+
+// resolver returns a sidecar resolver for the parser that reads the edited files, so
+// re-parsing between edits sees earlier ones
+func (se *sidecarEdits) resolver(p *parser.CommentParser) func(resource parser.TerraformResource) []parser.StructuredComment {
+	return func(resource parser.TerraformResource) []parser.StructuredComment {
+		var annotations []parser.StructuredComment
+		for _, sc := range se.load(filepath.Dir(resource.File)) {
+			found, err := sc.file.Annotations(p, sidecar.Addresses(resource)...)
+			if err != nil {
+				log.Printf("Warning: Failed to load sidecar annotations: %v", err)
+				continue
+			}
+			annotations = append(annotations, found...)
+		}
+		return annotations
+	}
+}
+
+// write writes and journals the changed sidecar files, or prints their diff in dry-run mode,
+// and returns how many changed
+func (se *sidecarEdits) write(dryRun bool, j *journal.Journal, run *journal.Run) (int, error) {
+	dirs := make([]string, 0, len(se.files))
+	for dir := range se.files {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	written := 0
+	for _, dir := range dirs {
+		for _, sc := range se.files[dir] {
+			if !sc.file.Modified() {
+				continue
+			}
+			content, err := sc.file.Bytes()
+			if err != nil {
+				return written, err
+			}
+			written++
+
+			if dryRun {
+				fmt.Print(diff.Unified(sc.file.Path, string(sc.original), string(content)))
+				continue
+			}
+
+			// #nosec G306 - Sidecar files are committed alongside Terraform source, 0644 is appropriate
+			if err := afero.WriteFile(se.fs, sc.file.Path, content, 0644); err != nil {
+				return written, fmt.Errorf("failed to write %s: %w", sc.file.Path, err)
+			}
+			if err := j.Record(run, sc.file.Path, sc.original, content); err != nil {
+				return written, fmt.Errorf("failed to record edit in journal: %w", err)
+			}
+			fmt.Printf("  ✅ Updated %s\n", sc.file.Path)
+		}
+	}
+
+	return written, nil
+}
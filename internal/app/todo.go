@@ -0,0 +1,150 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/toozej/terranotate/internal/validator"
+)
+
+// TodoItem is a single placeholder value still present in the codebase
+type TodoItem struct {
+	File     string
+	Resource string
+	Owner    string
+	validator.PlaceholderFinding
+}
+
+// Todo implements the todo command logic, listing every placeholder value grouped by owner or directory
+func Todo(fs afero.Fs, path, schemaFile, groupBy string) error {
+	if groupBy == "" {
+		groupBy = "directory"
+	}
+	if groupBy != "directory" && groupBy != "owner" {
+		return fmt.Errorf("invalid --group-by value '%s' (expected 'owner' or 'directory')", groupBy)
+	}
+
+	fmt.Println("=================================================")
+	fmt.Println("Terranotate - Placeholder TODO Report")
+	fmt.Println("=================================================")
+	fmt.Printf("Path: %s\n", path)
+	if schemaFile != "" {
+		fmt.Printf("Schema file: %s\n", schemaFile)
+	}
+	fmt.Println()
+
+	items, err := CollectTodos(fs, path, schemaFile)
+	if err != nil {
+		return err
+	}
+
+	if len(items) == 0 {
+		fmt.Println("✅ No placeholder values found!")
+		return nil
+	}
+
+	groups := groupTodos(items, groupBy)
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		label := "📁"
+		if groupBy == "owner" {
+			label = "👤"
+		}
+		fmt.Printf("\n%s %s (%d)\n", label, key, len(groups[key]))
+		fmt.Println(strings.Repeat("-", 80))
+		for _, item := range groups[key] {
+			source := ""
+			if item.Source != "" {
+				source = " (from " + item.Source + ")"
+			}
+			fmt.Printf("  %s:%d %s %s %s = %s%s\n", item.File, item.Line, item.Resource, item.Prefix, item.Field, item.Value, source)
+		}
+	}
+
+	fmt.Println("\n" + strings.Repeat("=", 50))
+	fmt.Printf("TODO Summary: %d placeholder value(s) in %d %s group(s)\n", len(items), len(groups), groupBy)
+	fmt.Println(strings.Repeat("=", 50))
+
+	return nil
+}
+
+// CollectTodos finds every placeholder value in the Terraform files under path
+func CollectTodos(fs afero.Fs, path, schemaFile string) ([]TodoItem, error) {
+	var schema validator.ValidationSchema
+	if schemaFile != "" {
+		var err error
+		schema, err = loadSchema(fs, schemaFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load schema: %w", err)
+		}
+	}
+
+	v, err := validator.NewSchemaValidatorFromSchema(fs, schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load schema: %w", err)
+	}
+
+	info, err := fs.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat path: %w", err)
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		files, err = findTerraformFiles(fs, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find terraform files: %w", err)
+		}
+	}
+
+	p := newParser(fs, schema, files)
+
+	var items []TodoItem
+	for _, file := range files {
+		resources, err := p.ParseFile(file)
+		if err != nil {
+			log.Printf("Warning: Failed to parse %s: %v", file, err)
+			continue
+		}
+
+		for _, resource := range resources {
+			owner := "(unowned)"
+			if value := resource.GetNestedField("@metadata", "owner"); value != nil && !v.IsPlaceholder(value) {
+				owner = fmt.Sprintf("%v", value)
+			}
+
+			for _, finding := range v.FindPlaceholders(resource) {
+				items = append(items, TodoItem{
+					File:               file,
+					Resource:           resource.Address(),
+					Owner:              owner,
+					PlaceholderFinding: finding,
+				})
+			}
+		}
+	}
+
+	return items, nil
+}
+
+// groupTodos groups TODO items by owner or by directory
+func groupTodos(items []TodoItem, groupBy string) map[string][]TodoItem {
+	groups := make(map[string][]TodoItem)
+	for _, item := range items {
+		key := filepath.Dir(item.File)
+		if groupBy == "owner" {
+			key = item.Owner
+		}
+		groups[key] = append(groups[key], item)
+	}
+	return groups
+}
@@ -0,0 +1,91 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestTodo(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	err := fs.MkdirAll("/infra/network", 0755)
+	if err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+
+	tfContent := `
+# @metadata owner:alice.smith team:CHANGEME
+resource "aws_vpc" "main" {
+  cidr_block = "10.0.0.0/16"
+}
+
+# @metadata owner:CHANGEME contact.email:changeme@example.com
+resource "aws_subnet" "public" { cidr_block = "10.0.1.0/24" }
+`
+	err = afero.WriteFile(fs, "/infra/network/main.tf", []byte(tfContent), 0644)
+	if err != nil {
+		t.Fatalf("failed to write main.tf: %v", err)
+	}
+	err = afero.WriteFile(fs, "/infra/clean.tf", []byte(`# @metadata owner:bob.jones`+"\n"+`resource "a" "b" {}`), 0644)
+	if err != nil {
+		t.Fatalf("failed to write clean.tf: %v", err)
+	}
+
+	items, err := CollectTodos(fs, "/infra", "")
+	if err != nil {
+		t.Fatalf("CollectTodos() failed: %v", err)
+	}
+	if len(items) != 3 {
+		t.Fatalf("Expected 3 placeholders, got %d: %v", len(items), items)
+	}
+
+	byOwner := groupTodos(items, "owner")
+	if len(byOwner["alice.smith"]) != 1 {
+		t.Errorf("Expected 1 placeholder owned by alice.smith, got %d", len(byOwner["alice.smith"]))
+	}
+	if len(byOwner["(unowned)"]) != 2 {
+		t.Errorf("Expected 2 unowned placeholders, got %d", len(byOwner["(unowned)"]))
+	}
+
+	byDir := groupTodos(items, "directory")
+	if len(byDir) != 1 || len(byDir["/infra/network"]) != 3 {
+		t.Errorf("Expected all placeholders in /infra/network, got %v", byDir)
+	}
+
+	// Custom placeholder values are added to the default patterns
+	err = afero.WriteFile(fs, "/schema.yaml", []byte("placeholders:\n  values: [alice.smith]\n"), 0644)
+	if err != nil {
+		t.Fatalf("failed to write schema: %v", err)
+	}
+	items, err = CollectTodos(fs, "/infra/network/main.tf", "/schema.yaml")
+	if err != nil {
+		t.Fatalf("CollectTodos() with schema failed: %v", err)
+	}
+	if len(items) != 4 {
+		t.Errorf("Expected the default placeholders and alice.smith, got %v", items)
+	}
+
+	// Custom patterns replace the defaults
+	err = afero.WriteFile(fs, "/schema.yaml", []byte("placeholders:\n  patterns: [\"^alice\"]\n"), 0644)
+	if err != nil {
+		t.Fatalf("failed to write schema: %v", err)
+	}
+	items, err = CollectTodos(fs, "/infra/network/main.tf", "/schema.yaml")
+	if err != nil {
+		t.Fatalf("CollectTodos() with schema failed: %v", err)
+	}
+	if len(items) != 1 || items[0].Field != "owner" {
+		t.Errorf("Expected only the owner placeholder, got %v", items)
+	}
+
+	if err := Todo(fs, "/infra", "", "owner"); err != nil {
+		t.Errorf("Todo() failed: %v", err)
+	}
+	if err := Todo(fs, "/infra", "", "bogus"); err == nil {
+		t.Error("Todo() should have failed for invalid group-by")
+	}
+	if err := Todo(fs, "/non-existent", "", ""); err == nil {
+		t.Error("Todo() should have failed for non-existent path")
+	}
+}
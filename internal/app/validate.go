@@ -12,17 +12,34 @@ import (
 	"github.com/toozej/terranotate/internal/validator"
 )
 
+// ValidateOptions configures validation
+type ValidateOptions struct {
+	Baseline       string // Known-violations file; only violations missing from it fail validation
+	UpdateBaseline bool   // Create the baseline, or remove violations that no longer occur from it
+	Explain        bool   // Show where inherited values come from
+}
+
 // Validate implements the validate command logic
 func Validate(fs afero.Fs, terraformFile, schemaFile string) error {
+	return ValidateWithOptions(fs, terraformFile, schemaFile, ValidateOptions{})
+}
+
+// ValidateWithOptions validates a single Terraform file with the given options
+func ValidateWithOptions(fs afero.Fs, terraformFile, schemaFile string, opts ValidateOptions) error {
 	fmt.Println("=================================================")
 	fmt.Println("Terranotate - Schema Validation")
 	fmt.Println("=================================================")
 	fmt.Printf("Terraform file: %s\n", terraformFile)
 	fmt.Printf("Schema file: %s\n\n", schemaFile)
 
-	// Parse the Terraform file
-	prefixes := []string{"@metadata", "@docs", "@validation", "@config"}
-	p := parser.NewCommentParser(fs, prefixes)
+	// Load the schema, which also declares how values are parsed
+	v, err := validator.NewSchemaValidator(fs, schemaFile)
+	if err != nil {
+		return fmt.Errorf("failed to load schema: %w", err)
+	}
+
+	// Parse the Terraform file, addressing its resources by the module calls of its repository
+	p := newParser(fs, v.Schema(), repositoryFiles(fs, terraformFile))
 
 	resources, err := p.ParseFile(terraformFile)
 	if err != nil {
@@ -31,17 +48,14 @@ func Validate(fs afero.Fs, terraformFile, schemaFile string) error {
 
 	fmt.Printf("Parsed %d resources\n", len(resources))
 
-	// Load and validate against schema
-	v, err := validator.NewSchemaValidator(fs, schemaFile)
-	if err != nil {
-		return fmt.Errorf("failed to load schema: %w", err)
-	}
-
 	fmt.Println("Validating against schema...")
 
-	result := v.ValidateResources(resources)
+	result, err := applyBaseline(fs, v.ValidateResources(resources), opts)
+	if err != nil {
+		return err
+	}
 
-	validator.PrintValidationResults(result)
+	validator.PrintValidationResultsWithOptions(result, validator.PrintOptions{Explain: opts.Explain})
 
 	if !result.Passed {
 		return fmt.Errorf("\n💡 Tip: Run 'terranotate fix %s %s' to auto-fix some issues", terraformFile, schemaFile)
@@ -52,6 +66,15 @@ func Validate(fs afero.Fs, terraformFile, schemaFile string) error {
 
 // ValidateAuto automatically detects the type of path and validates accordingly
 func ValidateAuto(fs afero.Fs, path, schemaFile string) error {
+	return ValidateAutoWithOptions(fs, path, schemaFile, ValidateOptions{})
+}
+
+// ValidateAutoWithOptions detects the type of path and validates it with the given options
+func ValidateAutoWithOptions(fs afero.Fs, path, schemaFile string, opts ValidateOptions) error {
+	if opts.UpdateBaseline && opts.Baseline == "" {
+		return fmt.Errorf("--update-baseline requires --baseline")
+	}
+
 	// Check if path exists
 	info, err := fs.Stat(path)
 	if err != nil {
@@ -60,7 +83,7 @@ func ValidateAuto(fs afero.Fs, path, schemaFile string) error {
 
 	// If it's a single file, validate as single file
 	if !info.IsDir() {
-		return Validate(fs, path, schemaFile)
+		return ValidateWithOptions(fs, path, schemaFile, opts)
 	}
 
 	// It's a directory - detect whether it's a module or workspace
@@ -69,14 +92,30 @@ func ValidateAuto(fs afero.Fs, path, schemaFile string) error {
 	switch detectedType {
 	case "workspace":
 		fmt.Println("🔍 Auto-detected: Terraform Workspace")
-		return ValidateWorkspace(fs, path, schemaFile)
+		return ValidateWorkspaceWithOptions(fs, path, schemaFile, opts)
 	case "module":
 		fmt.Println("🔍 Auto-detected: Terraform Module")
-		return ValidateModule(fs, path, schemaFile)
+		return ValidateModuleWithOptions(fs, path, schemaFile, opts)
 	default:
 		// Default to single directory validation (treat as simple terraform directory)
 		fmt.Println("🔍 Auto-detected: Terraform Directory")
-		return validateDirectory(fs, path, schemaFile)
+		return validateDirectory(fs, path, schemaFile, opts)
+	}
+}
+
+// findDetectedTerraformFiles finds the Terraform files under a directory the same way validation
+// does: recursively for workspaces, the root and modules/ for modules, and the top level otherwise
+func findDetectedTerraformFiles(fs afero.Fs, path string) ([]string, error) {
+	switch detectDirectoryType(fs, path) {
+	case "workspace":
+		fmt.Println("🔍 Auto-detected: Terraform Workspace")
+		return findWorkspaceTerraformFiles(fs, path)
+	case "module":
+		fmt.Println("🔍 Auto-detected: Terraform Module")
+		return findModuleTerraformFiles(fs, path)
+	default:
+		fmt.Println("🔍 Auto-detected: Terraform Directory")
+		return findTerraformFilesForGeneration(fs, path)
 	}
 }
 
@@ -158,7 +197,7 @@ func hasTerraformFiles(fs afero.Fs, path string) bool {
 }
 
 // validateDirectory validates all .tf files in a single directory (non-recursive)
-func validateDirectory(fs afero.Fs, dir, schemaFile string) error {
+func validateDirectory(fs afero.Fs, dir, schemaFile string, opts ValidateOptions) error {
 	fmt.Println("=================================================")
 	fmt.Println("Terranotate - Directory Validation")
 	fmt.Println("=================================================")
@@ -188,9 +227,14 @@ func validateDirectory(fs afero.Fs, dir, schemaFile string) error {
 	}
 	fmt.Println()
 
+	// Load the schema, which also declares how values are parsed
+	v, err := validator.NewSchemaValidator(fs, schemaFile)
+	if err != nil {
+		return fmt.Errorf("failed to load schema: %w", err)
+	}
+
 	// Parse and validate all files
-	prefixes := []string{"@metadata", "@docs", "@validation", "@config"}
-	p := parser.NewCommentParser(fs, prefixes)
+	p := newParser(fs, v.Schema(), repositoryFiles(fs, dir))
 
 	var allResources []parser.TerraformResource
 	for _, file := range tfFiles {
@@ -204,17 +248,14 @@ func validateDirectory(fs afero.Fs, dir, schemaFile string) error {
 
 	fmt.Printf("Parsed %d total resources\n", len(allResources))
 
-	// Load and validate against schema
-	v, err := validator.NewSchemaValidator(fs, schemaFile)
-	if err != nil {
-		return fmt.Errorf("failed to load schema: %w", err)
-	}
-
 	fmt.Println("Validating against schema...")
 
-	result := v.ValidateResources(allResources)
+	result, err := applyBaseline(fs, v.ValidateResources(allResources), opts)
+	if err != nil {
+		return err
+	}
 
-	validator.PrintValidationResults(result)
+	validator.PrintValidationResultsWithOptions(result, validator.PrintOptions{Explain: opts.Explain})
 
 	if !result.Passed {
 		return fmt.Errorf("\n💡 Tip: Run 'terranotate fix %s %s' to auto-fix some issues", dir, schemaFile)
@@ -225,6 +266,11 @@ func validateDirectory(fs afero.Fs, dir, schemaFile string) error {
 
 // ValidateModule implements the validate-module command logic
 func ValidateModule(fs afero.Fs, moduleDir, schemaFile string) error {
+	return ValidateModuleWithOptions(fs, moduleDir, schemaFile, ValidateOptions{})
+}
+
+// ValidateModuleWithOptions validates a module and its sub-modules with the given options
+func ValidateModuleWithOptions(fs afero.Fs, moduleDir, schemaFile string, opts ValidateOptions) error {
 	fmt.Println("=======================================================")
 	fmt.Println("Terranotate - Module Validation (with Sub-modules)")
 	fmt.Println("=======================================================")
@@ -254,9 +300,12 @@ func ValidateModule(fs afero.Fs, moduleDir, schemaFile string) error {
 	fmt.Println()
 
 	// Validate all files
-	result := validateTerraformFiles(fs, tfFiles, schemaFile)
+	result, err := applyBaseline(fs, validateTerraformFiles(fs, moduleDir, tfFiles, schemaFile), opts)
+	if err != nil {
+		return err
+	}
 
-	printModuleValidationResults(result, moduleDir)
+	printModuleValidationResults(result, moduleDir, opts)
 
 	if !result.Passed {
 		return fmt.Errorf("module validation failed")
@@ -267,6 +316,11 @@ func ValidateModule(fs afero.Fs, moduleDir, schemaFile string) error {
 
 // ValidateWorkspace implements the validate-workspace command logic
 func ValidateWorkspace(fs afero.Fs, workspaceDir, schemaFile string) error {
+	return ValidateWorkspaceWithOptions(fs, workspaceDir, schemaFile, ValidateOptions{})
+}
+
+// ValidateWorkspaceWithOptions validates every directory of a workspace with the given options
+func ValidateWorkspaceWithOptions(fs afero.Fs, workspaceDir, schemaFile string, opts ValidateOptions) error {
 	fmt.Println("=========================================================")
 	fmt.Println("Terranotate - Workspace Validation (Recursive)")
 	fmt.Println("=========================================================")
@@ -296,9 +350,12 @@ func ValidateWorkspace(fs afero.Fs, workspaceDir, schemaFile string) error {
 	fmt.Println()
 
 	// Validate all files
-	result := validateTerraformFiles(fs, tfFiles, schemaFile)
+	result, err := applyBaseline(fs, validateTerraformFiles(fs, workspaceDir, tfFiles, schemaFile), opts)
+	if err != nil {
+		return err
+	}
 
-	printWorkspaceValidationResults(result, workspaceDir, filesByDir)
+	printWorkspaceValidationResults(result, workspaceDir, filesByDir, opts)
 
 	if !result.Passed {
 		return fmt.Errorf("workspace validation failed")
@@ -414,7 +471,8 @@ func groupFilesByDirectory(files []string, baseDir string) map[string][]string {
 	return result
 }
 
-func validateTerraformFiles(fs afero.Fs, files []string, schemaFile string) validator.ValidationResult {
+// validateTerraformFiles validates the Terraform files found under root
+func validateTerraformFiles(fs afero.Fs, root string, files []string, schemaFile string) validator.ValidationResult {
 	aggregatedResult := validator.ValidationResult{Passed: true}
 
 	v, err := validator.NewSchemaValidator(fs, schemaFile)
@@ -422,8 +480,7 @@ func validateTerraformFiles(fs afero.Fs, files []string, schemaFile string) vali
 		log.Fatalf("Failed to load schema: %v", err)
 	}
 
-	prefixes := []string{"@metadata", "@docs", "@validation", "@config"}
-	p := parser.NewCommentParser(fs, prefixes)
+	p := newParser(fs, v.Schema(), repositoryFiles(fs, root))
 
 	for _, file := range files {
 		resources, err := p.ParseFile(file)
@@ -436,16 +493,11 @@ func validateTerraformFiles(fs afero.Fs, files []string, schemaFile string) vali
 			continue // Skip files with no resources
 		}
 
+		// Errors carry the file they were found in
 		result := v.ValidateResources(resources)
 
-		// Add file context to errors
-		for i := range result.Errors {
-			result.Errors[i].ResourceType = fmt.Sprintf("%s (%s)",
-				result.Errors[i].ResourceType,
-				file)
-		}
-
 		aggregatedResult.Errors = append(aggregatedResult.Errors, result.Errors...)
+		aggregatedResult.Warnings = append(aggregatedResult.Warnings, result.Warnings...)
 		if !result.Passed {
 			aggregatedResult.Passed = false
 		}
@@ -454,7 +506,7 @@ func validateTerraformFiles(fs afero.Fs, files []string, schemaFile string) vali
 	return aggregatedResult
 }
 
-func printModuleValidationResults(result validator.ValidationResult, moduleDir string) {
+func printModuleValidationResults(result validator.ValidationResult, moduleDir string, opts ValidateOptions) {
 	fmt.Println(strings.Repeat("=", 80))
 	fmt.Println("MODULE VALIDATION RESULTS")
 	fmt.Println(strings.Repeat("=", 80))
@@ -462,14 +514,15 @@ func printModuleValidationResults(result validator.ValidationResult, moduleDir s
 	if result.Passed {
 		fmt.Println("\n✅ Module validation passed!")
 		fmt.Printf("   All files in %s meet schema requirements\n", moduleDir)
+		validator.PrintWarnings(result.Warnings, validator.PrintOptions{Explain: opts.Explain})
 		return
 	}
 
 	fmt.Printf("\n❌ Module validation failed for: %s\n", moduleDir)
-	validator.PrintValidationResults(result)
+	validator.PrintValidationResultsWithOptions(result, validator.PrintOptions{Explain: opts.Explain})
 }
 
-func printWorkspaceValidationResults(result validator.ValidationResult, workspaceDir string, filesByDir map[string][]string) {
+func printWorkspaceValidationResults(result validator.ValidationResult, workspaceDir string, filesByDir map[string][]string, opts ValidateOptions) {
 	fmt.Println(strings.Repeat("=", 80))
 	fmt.Println("WORKSPACE VALIDATION RESULTS")
 	fmt.Println(strings.Repeat("=", 80))
@@ -483,24 +536,21 @@ func printWorkspaceValidationResults(result validator.ValidationResult, workspac
 		for dir := range filesByDir {
 			fmt.Printf("   ✓ %s\n", dir)
 		}
-		return
+		if len(result.Warnings) == 0 {
+			return
+		}
+		fmt.Printf("\n⚠️  %d warning(s):\n", len(result.Warnings))
+	} else {
+		fmt.Printf("\n❌ Workspace validation failed for: %s\n", workspaceDir)
 	}
 
-	fmt.Printf("\n❌ Workspace validation failed for: %s\n", workspaceDir)
-
 	errorsByDir := make(map[string][]validator.ValidationError)
-	for _, err := range result.Errors {
-		parts := strings.Split(err.ResourceType, " (")
-		if len(parts) == 2 {
-			filePath := strings.TrimSuffix(parts[1], ")")
-			for dir, files := range filesByDir {
-				for _, file := range files {
-					if file == filePath {
-						// Clean up ResourceType for display
-						err.ResourceType = parts[0] + " (" + filepath.Base(filePath) + ")"
-						errorsByDir[dir] = append(errorsByDir[dir], err)
-						break
-					}
+	for _, err := range append(append([]validator.ValidationError{}, result.Errors...), result.Warnings...) {
+		for dir, files := range filesByDir {
+			for _, file := range files {
+				if file == err.File {
+					errorsByDir[dir] = append(errorsByDir[dir], err)
+					break
 				}
 			}
 		}
@@ -508,7 +558,7 @@ func printWorkspaceValidationResults(result validator.ValidationResult, workspac
 
 	fmt.Println("\n" + strings.Repeat("=", 80))
 	for dir, errors := range errorsByDir {
-		fmt.Printf("\n📁 Directory: %s (%d errors)\n", dir, len(errors))
+		fmt.Printf("\n📁 Directory: %s (%d issues)\n", dir, len(errors))
 		fmt.Println(strings.Repeat("-", 80))
 
 		for _, err := range errors {
@@ -519,11 +569,16 @@ func printWorkspaceValidationResults(result validator.ValidationResult, workspac
 				icon = "⚠️"
 			}
 
-			fmt.Printf("  %s [%s] %s - Line %d\n", icon, severity, err.ResourceType, err.Line)
-			fmt.Printf("     %s\n\n", err.Message)
+			// Show the file name next to the resource address
+			fmt.Printf("  %s [%s] %s (%s) - Line %d\n", icon, severity, err.Address(), filepath.Base(err.File), err.Line)
+			fmt.Printf("     %s\n", err.Message)
+			if opts.Explain {
+				validator.PrintExplanation(err)
+			}
+			fmt.Println()
 		}
 	}
 
 	fmt.Println(strings.Repeat("=", 80))
-	fmt.Printf("\nTotal errors: %d across %d directories\n", len(result.Errors), len(errorsByDir))
+	fmt.Printf("\nTotal errors: %d, warnings: %d across %d directories\n", len(result.Errors), len(result.Warnings), len(errorsByDir))
 }
@@ -1,9 +1,11 @@
 package app
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/spf13/afero"
+	"github.com/toozej/terranotate/internal/validator"
 )
 
 func TestValidate(t *testing.T) {
@@ -157,7 +159,7 @@ func TestValidateDirectory(t *testing.T) {
 		t.Fatalf("failed: %v", err)
 	}
 
-	err = validateDirectory(fs, "/dir", "/schema.yaml")
+	err = validateDirectory(fs, "/dir", "/schema.yaml", ValidateOptions{})
 	if err != nil {
 		t.Errorf("validateDirectory() failed: %v", err)
 	}
@@ -167,7 +169,7 @@ func TestValidateDirectory(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed: %v", err)
 	}
-	err = validateDirectory(fs, "/empty", "/schema.yaml")
+	err = validateDirectory(fs, "/empty", "/schema.yaml", ValidateOptions{})
 	if err == nil {
 		t.Error("validateDirectory() should have failed for empty directory")
 	}
@@ -222,3 +224,191 @@ func TestValidateWorkspace(t *testing.T) {
 		t.Errorf("ValidateWorkspace() failed: %v", err)
 	}
 }
+
+func TestValidateBaseline(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	schema := `global:
+  required_prefixes: ["@metadata"]
+  prefix_rules:
+    "@metadata":
+      required_fields: [owner]
+`
+	if err := afero.WriteFile(fs, "/schema.yaml", []byte(schema), 0644); err != nil {
+		t.Fatalf("failed to write schema: %v", err)
+	}
+	writeTF := func(content string) {
+		if err := afero.WriteFile(fs, "/repo/main.tf", []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write main.tf: %v", err)
+		}
+	}
+	writeTF(`
+resource "aws_vpc" "main" {}
+
+resource "aws_s3_bucket" "logs" {}
+`)
+
+	opts := ValidateOptions{Baseline: "/repo/terranotate-baseline.json"}
+
+	// A missing baseline fails, so a mistyped path doesn't pass silently
+	if err := ValidateAutoWithOptions(fs, "/repo", "/schema.yaml", opts); err == nil {
+		t.Fatal("Expected a missing baseline to fail validation")
+	}
+	if exists, _ := afero.Exists(fs, opts.Baseline); exists {
+		t.Fatal("Expected a missing baseline not to be created without --update-baseline")
+	}
+
+	// Updating records existing violations and passes
+	opts.UpdateBaseline = true
+	if err := ValidateAutoWithOptions(fs, "/repo", "/schema.yaml", opts); err != nil {
+		t.Fatalf("Expected first run to create the baseline and pass: %v", err)
+	}
+	opts.UpdateBaseline = false
+	var baseline validator.Baseline
+	data, err := afero.ReadFile(fs, opts.Baseline)
+	if err != nil {
+		t.Fatalf("Expected baseline to be written: %v", err)
+	}
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		t.Fatalf("Baseline is invalid JSON: %v", err)
+	}
+	if baseline.Size() != 2 || baseline.Violations[0].File != "main.tf" {
+		t.Fatalf("Unexpected baseline: %+v", baseline)
+	}
+
+	// Moving resources around does not introduce new violations
+	writeTF(`
+
+resource "aws_s3_bucket" "logs" {}
+resource "aws_vpc" "main" {}
+`)
+	if err := ValidateAutoWithOptions(fs, "/repo", "/schema.yaml", opts); err != nil {
+		t.Errorf("Expected known violations to be suppressed: %v", err)
+	}
+
+	// A new unannotated resource fails
+	writeTF(`
+resource "aws_vpc" "main" {}
+resource "aws_s3_bucket" "logs" {}
+resource "aws_subnet" "a" {}
+`)
+	if err := ValidateAutoWithOptions(fs, "/repo", "/schema.yaml", opts); err == nil {
+		t.Error("Expected a new violation to fail validation")
+	}
+
+	// Fixing a violation and updating the baseline prunes it
+	writeTF(`
+resource "aws_s3_bucket" "logs" {}
+
+# @metadata owner:network
+resource "aws_vpc" "main" {}
+`)
+	opts.UpdateBaseline = true
+	if err := ValidateAutoWithOptions(fs, "/repo", "/schema.yaml", opts); err != nil {
+		t.Fatalf("Expected update run to pass: %v", err)
+	}
+	data, _ = afero.ReadFile(fs, opts.Baseline)
+	baseline = validator.Baseline{}
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		t.Fatalf("Baseline is invalid JSON: %v", err)
+	}
+	if baseline.Size() != 1 || baseline.Violations[0].Resource != "aws_s3_bucket.logs" {
+		t.Errorf("Expected only aws_s3_bucket.logs to remain, got %+v", baseline.Violations)
+	}
+
+	if err := ValidateAutoWithOptions(fs, "/repo", "/schema.yaml", ValidateOptions{UpdateBaseline: true}); err == nil {
+		t.Error("Expected --update-baseline without --baseline to fail")
+	}
+}
+
+func TestValidateCoercesValuesBySchemaType(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	schemaContent := `
+global:
+  required_prefixes: ["@metadata"]
+field_validations:
+  zip:
+    type: string
+    pattern: "^[0-9]{5}$"
+  retries:
+    type: integer
+    max: 10
+`
+	if err := afero.WriteFile(fs, "/schema.yaml", []byte(schemaContent), 0644); err != nil {
+		t.Fatalf("failed to write schema: %v", err)
+	}
+
+	tfContent := `
+# @metadata zip:01234 retries:007
+resource "aws_vpc" "main" { cidr_block = "10.0.0.0/16" }
+`
+	if err := afero.WriteFile(fs, "/main.tf", []byte(tfContent), 0644); err != nil {
+		t.Fatalf("failed to write main.tf: %v", err)
+	}
+
+	// 007 only parses as an integer because the schema declares retries as one
+	if err := Validate(fs, "/main.tf", "/schema.yaml"); err != nil {
+		t.Errorf("Validate() failed: %v", err)
+	}
+}
+
+func TestValidateBaselineUsesModuleAddresses(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	schema := `global: { required_prefixes: ["@metadata"] }`
+	files := map[string]string{
+		"/schema.yaml": schema,
+		"/repo/main.tf": `
+module "network" {
+  source = "./modules/network"
+}
+
+module "data" {
+  source = "./modules/data"
+}
+`,
+		"/repo/modules/network/main.tf": `resource "aws_subnet" "private" {}`,
+		"/repo/modules/data/main.tf":    `resource "aws_subnet" "private" {}`,
+		"/repo/.git/HEAD":               "ref: refs/heads/main\n",
+	}
+	for path, content := range files {
+		if err := afero.WriteFile(fs, path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+
+	opts := ValidateOptions{Baseline: "/repo/terranotate-baseline.json", UpdateBaseline: true}
+	if err := ValidateAutoWithOptions(fs, "/repo", "/schema.yaml", opts); err != nil {
+		t.Fatalf("Expected first run to create the baseline and pass: %v", err)
+	}
+	opts.UpdateBaseline = false
+
+	data, err := afero.ReadFile(fs, opts.Baseline)
+	if err != nil {
+		t.Fatalf("Expected baseline to be written: %v", err)
+	}
+	var baseline validator.Baseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		t.Fatalf("Baseline is invalid JSON: %v", err)
+	}
+
+	resources := make(map[string]bool)
+	for _, violation := range baseline.Violations {
+		resources[violation.Resource] = true
+	}
+	for _, address := range []string{"module.network.aws_subnet.private", "module.data.aws_subnet.private"} {
+		if !resources[address] {
+			t.Errorf("Expected baseline to contain %s, got %+v", address, baseline.Violations)
+		}
+	}
+
+	// Validating a single file resolves its address from the whole repository, so the
+	// baseline written by the workspace run still matches
+	if err := ValidateWithOptions(fs, "/repo/modules/network/main.tf", "/schema.yaml", opts); err != nil {
+		t.Errorf("Expected single-file run to match the baseline: %v", err)
+	}
+	if err := ValidateAutoWithOptions(fs, "/repo/modules/data", "/schema.yaml", opts); err != nil {
+		t.Errorf("Expected directory run to match the baseline: %v", err)
+	}
+}
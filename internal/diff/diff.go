@@ -0,0 +1,158 @@
+// Package diff computes line-based differences between file contents.
+package diff
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Edit operations
+const (
+	OpInsert = "insert"
+	OpDelete = "delete"
+)
+
+// Edit is a single inserted or deleted line
+type Edit struct {
+	Op   string `json:"op"`   // "insert" or "delete"
+	Line int    `json:"line"` // 1-based line number (in the new content for inserts, the old content for deletes)
+	Text string `json:"text"`
+}
+
+// Lines returns the edits that turn oldText into newText
+func Lines(oldText, newText string) []Edit {
+	a := strings.Split(oldText, "\n")
+	b := strings.Split(newText, "\n")
+
+	var edits []Edit
+	for _, op := range script(a, b) {
+		if op.kind == ' ' {
+			continue
+		}
+		if op.kind == '+' {
+			edits = append(edits, Edit{Op: OpInsert, Line: op.newLine, Text: op.text})
+		} else {
+			edits = append(edits, Edit{Op: OpDelete, Line: op.oldLine, Text: op.text})
+		}
+	}
+	return edits
+}
+
+// lineOp is one line of an edit script: ' ' (unchanged), '+' (inserted) or '-' (deleted)
+type lineOp struct {
+	kind    byte
+	text    string
+	oldLine int
+	newLine int
+}
+
+// script computes an edit script using the longest common subsequence of lines
+func script(a, b []string) []lineOp {
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var ops []lineOp
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, lineOp{kind: ' ', text: a[i], oldLine: i + 1, newLine: j + 1})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, lineOp{kind: '-', text: a[i], oldLine: i + 1, newLine: j + 1})
+			i++
+		default:
+			ops = append(ops, lineOp{kind: '+', text: b[j], oldLine: i + 1, newLine: j + 1})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, lineOp{kind: '-', text: a[i], oldLine: i + 1, newLine: j + 1})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, lineOp{kind: '+', text: b[j], oldLine: i + 1, newLine: j + 1})
+	}
+
+	return ops
+}
+
+// contextSize is the number of unchanged lines shown around each change in unified output
+const contextSize = 3
+
+// Unified renders the difference between oldText and newText in unified diff format
+func Unified(name, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+
+	ops := script(strings.Split(oldText, "\n"), strings.Split(newText, "\n"))
+
+	var sb strings.Builder
+	sb.WriteString("--- a/" + name + "\n")
+	sb.WriteString("+++ b/" + name + "\n")
+
+	for start := 0; start < len(ops); {
+		// Find the next change
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+
+		// Extend the hunk while changes are within two context windows of each other
+		end := start
+		for i := start; i < len(ops); i++ {
+			if ops[i].kind != ' ' {
+				end = i
+			} else if i-end > 2*contextSize {
+				break
+			}
+		}
+
+		from := max(start-contextSize, 0)
+		to := min(end+contextSize+1, len(ops))
+
+		oldCount, newCount := 0, 0
+		for _, op := range ops[from:to] {
+			if op.kind != '+' {
+				oldCount++
+			}
+			if op.kind != '-' {
+				newCount++
+			}
+		}
+
+		fmtHunk(&sb, ops[from].oldLine, oldCount, ops[from].newLine, newCount)
+		for _, op := range ops[from:to] {
+			sb.WriteByte(op.kind)
+			sb.WriteString(op.text)
+			sb.WriteByte('\n')
+		}
+
+		start = to
+	}
+
+	return sb.String()
+}
+
+// fmtHunk writes a unified diff hunk header
+func fmtHunk(sb *strings.Builder, oldStart, oldCount, newStart, newCount int) {
+	sb.WriteString("@@ -" + strconv.Itoa(oldStart) + "," + strconv.Itoa(oldCount) +
+		" +" + strconv.Itoa(newStart) + "," + strconv.Itoa(newCount) + " @@\n")
+}
@@ -0,0 +1,41 @@
+package diff
+
+import (
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	oldText := "a\nb\nc\nd"
+	newText := "a\n# inserted\nb\nd"
+
+	edits := Lines(oldText, newText)
+	if len(edits) != 2 {
+		t.Fatalf("Expected 2 edits, got %d: %v", len(edits), edits)
+	}
+
+	if edits[0] != (Edit{Op: OpInsert, Line: 2, Text: "# inserted"}) {
+		t.Errorf("Unexpected first edit: %+v", edits[0])
+	}
+	if edits[1] != (Edit{Op: OpDelete, Line: 3, Text: "c"}) {
+		t.Errorf("Unexpected second edit: %+v", edits[1])
+	}
+
+	if edits := Lines(oldText, oldText); len(edits) != 0 {
+		t.Errorf("Expected no edits for identical content, got %v", edits)
+	}
+}
+
+func TestUnified(t *testing.T) {
+	oldText := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10"
+	newText := "1\n2\n3\n4\n5\n6\n7\n8\n9\nten"
+
+	got := Unified("main.tf", oldText, newText)
+	want := "--- a/main.tf\n+++ b/main.tf\n@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+ten\n"
+	if got != want {
+		t.Errorf("Unified() =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := Unified("main.tf", oldText, oldText); got != "" {
+		t.Errorf("Expected empty diff for identical content, got %q", got)
+	}
+}
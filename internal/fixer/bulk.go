@@ -0,0 +1,335 @@
+package fixer
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/toozej/terranotate/internal/parser"
+)
+
+// Bulk edit operations
+const (
+	OpSet    = "set"
+	OpUnset  = "unset"
+	OpRename = "rename"
+)
+
+// fieldNameRegex matches valid annotation field names, including dotted nested paths
+var fieldNameRegex = regexp.MustCompile(`^[\w]+(\.[\w]+)*$`)
+
+// tokenRegex matches the key:value tokens of an annotation line as the parser reads them, so
+// text inside a quoted value is never taken for a token
+var tokenRegex = regexp.MustCompile(`([\w\.]+):` + parser.ValuePattern)
+
+// BulkEdit describes a single annotation change applied to every selected resource
+type BulkEdit struct {
+	Op     string // OpSet, OpUnset or OpRename
+	Prefix string // Comment prefix, e.g. "@metadata"
+	Field  string // Field name, dotted for nested fields
+	Value  string // New value for OpSet, new field name for OpRename
+}
+
+// Validate checks that the edit can be written as annotation syntax. Set values containing
+// whitespace are quoted when written.
+func (e BulkEdit) Validate() error {
+	if e.Prefix == "" {
+		return fmt.Errorf("a comment prefix is required")
+	}
+	if !fieldNameRegex.MatchString(e.Field) {
+		return fmt.Errorf("invalid field name '%s'", e.Field)
+	}
+
+	switch e.Op {
+	case OpSet:
+		if e.Value == "" || strings.ContainsAny(e.Value, "\r\n") {
+			return fmt.Errorf("invalid value '%s' for field '%s' (values cannot be empty or span lines)", e.Value, e.Field)
+		}
+		if _, ok := quoteValue(e.Value); !ok {
+			return fmt.Errorf("invalid value '%s' for field '%s' (values with whitespace cannot contain both quote characters)", e.Value, e.Field)
+		}
+	case OpRename:
+		if !fieldNameRegex.MatchString(e.Value) {
+			return fmt.Errorf("invalid field name '%s'", e.Value)
+		}
+	case OpUnset:
+	default:
+		return fmt.Errorf("unknown bulk edit operation '%s'", e.Op)
+	}
+
+	return nil
+}
+
+// Conflict returns why the edit cannot be applied to a resource, or "" if it can: a rename
+// whose target field the resource already sets itself would write a duplicate key
+func (e BulkEdit) Conflict(resource parser.TerraformResource) string {
+	if e.Op != OpRename {
+		return ""
+	}
+	own := resource
+	own.Inherited = nil
+	if own.GetNestedField(e.Prefix, e.Field) != nil && own.GetNestedField(e.Prefix, e.Value) != nil {
+		return fmt.Sprintf("cannot rename '%s' to '%s': '%s' is already set", e.Field, e.Value, e.Value)
+	}
+	return ""
+}
+
+// quoteValue returns a value as annotation syntax, quoting values with whitespace or that
+// start with a quote. It reports false when the value cannot be quoted.
+func quoteValue(value string) (string, bool) {
+	if !strings.ContainsAny(value, " \t") && !strings.HasPrefix(value, `"`) && !strings.HasPrefix(value, "'") {
+		return value, true
+	}
+	if !strings.Contains(value, `"`) {
+		return `"` + value + `"`, true
+	}
+	if !strings.Contains(value, "'") {
+		return "'" + value + "'", true
+	}
+	return value, false
+}
+
+// fieldTokens returns the [start, end, keyStart, keyEnd] positions of the tokens on a line
+// whose key is field or, with nested, a field below it
+func fieldTokens(line, field string, nested bool) [][]int {
+	var tokens [][]int
+	for _, match := range tokenRegex.FindAllStringSubmatchIndex(line, -1) {
+		key := line[match[2]:match[3]]
+		if key == field || (nested && strings.HasPrefix(key, field+".")) {
+			tokens = append(tokens, match[:4])
+		}
+	}
+	return tokens
+}
+
+// Selector chooses the resources a bulk edit applies to. Empty criteria match everything.
+type Selector struct {
+	Type  string            // Resource type glob, e.g. "aws_*"
+	Name  string            // Resource name glob, e.g. "web_*"
+	Path  string            // File path glob, matched against the full path and the base name
+	Where map[string]string // Field value globs keyed by "field" (any prefix) or "@prefix:field"
+}
+
+// Matches reports whether a resource in the given file is selected
+func (s Selector) Matches(file string, resource parser.TerraformResource) bool {
+	if !globMatch(s.Type, resource.Type) || !globMatch(s.Name, resource.Name) {
+		return false
+	}
+
+	if s.Path != "" {
+		slashed := filepath.ToSlash(file)
+		if !globMatch(s.Path, slashed) && !globMatch(s.Path, path.Base(slashed)) && !strings.HasPrefix(slashed, strings.TrimSuffix(s.Path, "/")+"/") {
+			return false
+		}
+	}
+
+	for key, pattern := range s.Where {
+		if !s.fieldMatches(resource, key, pattern) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// fieldMatches checks a single where clause against a resource's annotations
+func (s Selector) fieldMatches(resource parser.TerraformResource, key, pattern string) bool {
+	prefix, field := "", key
+	if parts := strings.SplitN(key, ":", 2); len(parts) == 2 {
+		prefix, field = parts[0], parts[1]
+	}
+
+	prefixes := []string{prefix}
+	if prefix == "" {
+		prefixes = nil
+		for _, comment := range resource.Comments() {
+			prefixes = append(prefixes, comment.Prefix)
+		}
+	}
+
+	for _, p := range prefixes {
+		if value := resource.GetNestedField(p, field); value != nil && globMatch(pattern, fmt.Sprintf("%v", value)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// globMatch matches a value against a glob pattern, treating an empty pattern as a wildcard
+func globMatch(pattern, value string) bool {
+	if pattern == "" {
+		return true
+	}
+	matched, err := path.Match(pattern, value)
+	return err == nil && matched
+}
+
+// ApplyBulkEdit applies an edit to the annotations of the given resources, preserving all
+// other lines of the content. It returns the updated content and the addresses ("type.name")
+// of the resources that changed.
+func (cf *CommentFixer) ApplyBulkEdit(content string, resources []parser.TerraformResource, edit BulkEdit) (string, []string) {
+	lines := strings.Split(content, "\n")
+	var changed []string
+
+	// Work from the bottom of the file up so line numbers of earlier resources stay valid
+	ordered := append([]parser.TerraformResource{}, resources...)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].StartLine > ordered[j].StartLine })
+
+	for _, resource := range ordered {
+		var ok bool
+		switch edit.Op {
+		case OpSet:
+			lines, ok = cf.setField(lines, resource, edit)
+		case OpUnset:
+			lines, ok = cf.unsetField(lines, resource, edit)
+		case OpRename:
+			lines, ok = cf.renameField(lines, resource, edit)
+		}
+		if ok {
+			changed = append(changed, resource.Address())
+		}
+	}
+
+	return strings.Join(lines, "\n"), changed
+}
+
+// commentsWithPrefix returns the resource's comments for a prefix, last in the file first.
+// Sidecar annotations live in another file and are edited by ApplySidecarBulkEdit.
+func commentsWithPrefix(resource parser.TerraformResource, prefix string) []parser.StructuredComment {
+	var comments []parser.StructuredComment
+	for _, comment := range append(append([]parser.StructuredComment{}, resource.PrecedingComments...), resource.InlineComments...) {
+		if comment.Prefix == prefix {
+			comments = append(comments, comment)
+		}
+	}
+	sort.SliceStable(comments, func(i, j int) bool { return comments[i].Line > comments[j].Line })
+	return comments
+}
+
+// setField replaces the field's value, adds it to an existing comment or inserts a new comment
+func (cf *CommentFixer) setField(lines []string, resource parser.TerraformResource, edit BulkEdit) ([]string, bool) {
+	value, _ := quoteValue(edit.Value)
+	token := edit.Field + ":" + value
+	comments := commentsWithPrefix(resource, edit.Prefix)
+
+	// Replace existing values
+	replaced, changed := false, false
+	for _, comment := range comments {
+		for i := comment.Line - 1; i < comment.EndLine && i < len(lines); i++ {
+			tokens := fieldTokens(lines[i], edit.Field, false)
+			updated := lines[i]
+			for t := len(tokens) - 1; t >= 0; t-- {
+				updated = updated[:tokens[t][0]] + token + updated[tokens[t][1]:]
+			}
+			if len(tokens) > 0 {
+				replaced = true
+			}
+			if updated != lines[i] {
+				lines[i] = updated
+				changed = true
+			}
+		}
+	}
+	if replaced {
+		return lines, changed
+	}
+
+	// A value set in a sidecar file is replaced there
+	for _, comment := range resource.SidecarComments {
+		if comment.Prefix == edit.Prefix && lookupPath(comment.Fields, edit.Field) {
+			return lines, false
+		}
+	}
+
+	// Append to the line holding the prefix of an existing comment
+	for _, comment := range comments {
+		for i := comment.Line - 1; i < comment.EndLine && i < len(lines); i++ {
+			if strings.Contains(lines[i], edit.Prefix) {
+				lines[i] = strings.TrimRight(lines[i], " \t") + " " + token
+				return lines, true
+			}
+		}
+	}
+
+	// Insert a new comment above the resource
+	insertLine := cf.findInsertionPoint(lines, resource.StartLine-1)
+	return cf.insertLines(lines, insertLine, []string{"# " + edit.Prefix + " " + token}), true
+}
+
+// lookupPath reports whether a dotted path is set in nested fields
+func lookupPath(fields map[string]interface{}, field string) bool {
+	parts := strings.Split(field, ".")
+	for i, part := range parts {
+		value, exists := fields[part]
+		if !exists {
+			return false
+		}
+		if i == len(parts)-1 {
+			return true
+		}
+		if fields, exists = value.(map[string]interface{}); !exists {
+			return false
+		}
+	}
+	return false
+}
+
+// unsetField removes the field, dropping continuation lines left without content
+func (cf *CommentFixer) unsetField(lines []string, resource parser.TerraformResource, edit BulkEdit) ([]string, bool) {
+	changed := false
+
+	for _, comment := range commentsWithPrefix(resource, edit.Prefix) {
+		for i := comment.EndLine - 1; i >= comment.Line-1; i-- {
+			if i >= len(lines) {
+				continue
+			}
+			tokens := fieldTokens(lines[i], edit.Field, true)
+			if len(tokens) == 0 {
+				continue
+			}
+
+			// Remove each token with the whitespace before it
+			for t := len(tokens) - 1; t >= 0; t-- {
+				start := tokens[t][0]
+				for start > 0 && (lines[i][start-1] == ' ' || lines[i][start-1] == '\t') {
+					start--
+				}
+				lines[i] = lines[i][:start] + lines[i][tokens[t][1]:]
+			}
+			changed = true
+
+			remaining := strings.TrimSpace(lines[i])
+			if remaining == "#" || remaining == "//" {
+				lines = append(lines[:i], lines[i+1:]...)
+			}
+		}
+	}
+
+	return lines, changed
+}
+
+// renameField renames the field, including nested fields below it (e.g. "contact" renames
+// "contact.email"). Resources that already set the new field are left unchanged.
+func (cf *CommentFixer) renameField(lines []string, resource parser.TerraformResource, edit BulkEdit) ([]string, bool) {
+	if edit.Conflict(resource) != "" {
+		return lines, false
+	}
+	changed := false
+
+	for _, comment := range commentsWithPrefix(resource, edit.Prefix) {
+		for i := comment.Line - 1; i < comment.EndLine && i < len(lines); i++ {
+			tokens := fieldTokens(lines[i], edit.Field, true)
+			for t := len(tokens) - 1; t >= 0; t-- {
+				keyStart := tokens[t][2]
+				lines[i] = lines[i][:keyStart] + edit.Value + lines[i][keyStart+len(edit.Field):]
+				changed = true
+			}
+		}
+	}
+
+	return lines, changed
+}
@@ -0,0 +1,285 @@
+package fixer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/toozej/terranotate/internal/parser"
+	"github.com/toozej/terranotate/internal/validator"
+)
+
+const bulkTestContent = `# @metadata owner:alice cost_center:cc-1
+# contact.email:alice@example.com
+resource "aws_vpc" "main" {
+  cidr_block = "10.0.0.0/16"
+}
+
+
+
+
+
+
+# @metadata owner:bob
+resource "aws_subnet" "web_a" {
+  vpc_id = "x"
+}
+
+
+
+
+
+
+resource "aws_s3_bucket" "logs" {
+  bucket = "logs"
+}
+`
+
+func parseBulkTestContent(t *testing.T, content string) []parser.TerraformResource {
+	t.Helper()
+	p := parser.NewCommentParser(afero.NewMemMapFs(), []string{"@metadata", "@docs"})
+	resources, err := p.ParseSource("main.tf", []byte(content))
+	if err != nil {
+		t.Fatalf("failed to parse content: %v", err)
+	}
+	return resources
+}
+
+func TestApplyBulkEdit(t *testing.T) {
+	cf := NewCommentFixer(afero.NewMemMapFs(), validator.ValidationSchema{})
+	resources := parseBulkTestContent(t, bulkTestContent)
+
+	tests := []struct {
+		name        string
+		edit        BulkEdit
+		wantChanged int
+		wantFields  map[string]interface{} // resource name -> expected @metadata field value (nil = absent)
+		field       string
+	}{
+		{
+			name:        "set replaces existing and inserts missing",
+			edit:        BulkEdit{Op: OpSet, Prefix: "@metadata", Field: "owner", Value: "carol"},
+			wantChanged: 3,
+			field:       "owner",
+			wantFields:  map[string]interface{}{"main": "carol", "web_a": "carol", "logs": "carol"},
+		},
+		{
+			name:        "set appends to existing prefix line",
+			edit:        BulkEdit{Op: OpSet, Prefix: "@metadata", Field: "team", Value: "core"},
+			wantChanged: 3,
+			field:       "team",
+			wantFields:  map[string]interface{}{"main": "core", "web_a": "core", "logs": "core"},
+		},
+		{
+			name:        "unset removes nested fields and empty lines",
+			edit:        BulkEdit{Op: OpUnset, Prefix: "@metadata", Field: "contact"},
+			wantChanged: 1,
+			field:       "contact.email",
+			wantFields:  map[string]interface{}{"main": nil},
+		},
+		{
+			name:        "rename keeps value",
+			edit:        BulkEdit{Op: OpRename, Prefix: "@metadata", Field: "cost_center", Value: "costcenter"},
+			wantChanged: 1,
+			field:       "costcenter",
+			wantFields:  map[string]interface{}{"main": "cc-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated, changed := cf.ApplyBulkEdit(bulkTestContent, resources, tt.edit)
+			if len(changed) != tt.wantChanged {
+				t.Errorf("changed %v, want %d resources", changed, tt.wantChanged)
+			}
+
+			byName := make(map[string]parser.TerraformResource)
+			for _, r := range parseBulkTestContent(t, updated) {
+				byName[r.Name] = r
+			}
+			if len(byName) != 3 {
+				t.Fatalf("expected 3 resources after edit, got %d:\n%s", len(byName), updated)
+			}
+			for name, want := range tt.wantFields {
+				resource := byName[name]
+				got := resource.GetNestedField("@metadata", tt.field)
+				if want == nil {
+					if got != nil {
+						t.Errorf("%s: expected %s to be removed, got %v\n%s", name, tt.field, got, updated)
+					}
+					continue
+				}
+				if got != want {
+					t.Errorf("%s: %s = %v, want %v\n%s", name, tt.field, got, want, updated)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyBulkEditQuotedValues(t *testing.T) {
+	cf := NewCommentFixer(afero.NewMemMapFs(), validator.ValidationSchema{})
+	content := `# @metadata owner:alice team:"core platform" note:'a b'
+resource "aws_vpc" "main" {
+  cidr_block = "10.0.0.0/16"
+}
+`
+	resources := parseBulkTestContent(t, content)
+
+	tests := []struct {
+		name     string
+		edit     BulkEdit
+		wantLine string
+	}{
+		{
+			name:     "set replaces the whole quoted value",
+			edit:     BulkEdit{Op: OpSet, Prefix: "@metadata", Field: "team", Value: "net"},
+			wantLine: `# @metadata owner:alice team:net note:'a b'`,
+		},
+		{
+			name:     "unset removes the whole quoted value",
+			edit:     BulkEdit{Op: OpUnset, Prefix: "@metadata", Field: "note"},
+			wantLine: `# @metadata owner:alice team:"core platform"`,
+		},
+		{
+			name:     "rename keeps the quoted value",
+			edit:     BulkEdit{Op: OpRename, Prefix: "@metadata", Field: "team", Value: "squad"},
+			wantLine: `# @metadata owner:alice squad:"core platform" note:'a b'`,
+		},
+		{
+			name:     "set quotes values with whitespace",
+			edit:     BulkEdit{Op: OpSet, Prefix: "@metadata", Field: "owner", Value: "alice smith"},
+			wantLine: `# @metadata owner:"alice smith" team:"core platform" note:'a b'`,
+		},
+		{
+			name:     "set quotes values with double quotes in single quotes",
+			edit:     BulkEdit{Op: OpSet, Prefix: "@metadata", Field: "owner", Value: `the "core" team`},
+			wantLine: `# @metadata owner:'the "core" team' team:"core platform" note:'a b'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated, changed := cf.ApplyBulkEdit(content, resources, tt.edit)
+			if len(changed) != 1 {
+				t.Errorf("changed %v, want 1 resource", changed)
+			}
+			if got := strings.SplitN(updated, "\n", 2)[0]; got != tt.wantLine {
+				t.Errorf("comment line = %q, want %q", got, tt.wantLine)
+			}
+		})
+	}
+}
+
+func TestApplyBulkEditTokenBoundaries(t *testing.T) {
+	cf := NewCommentFixer(afero.NewMemMapFs(), validator.ValidationSchema{})
+	content := `# @metadata description:"the owner:bob and team:net" owner:alice
+resource "aws_vpc" "main" {
+  cidr_block = "10.0.0.0/16"
+}
+`
+	resources := parseBulkTestContent(t, content)
+
+	// Text inside quoted values is never edited as a field
+	tests := []struct {
+		edit     BulkEdit
+		wantLine string
+	}{
+		{BulkEdit{Op: OpSet, Prefix: "@metadata", Field: "owner", Value: "carol"}, `# @metadata description:"the owner:bob and team:net" owner:carol`},
+		{BulkEdit{Op: OpSet, Prefix: "@metadata", Field: "team", Value: "core"}, `# @metadata description:"the owner:bob and team:net" owner:alice team:core`},
+		{BulkEdit{Op: OpUnset, Prefix: "@metadata", Field: "owner"}, `# @metadata description:"the owner:bob and team:net"`},
+		{BulkEdit{Op: OpRename, Prefix: "@metadata", Field: "owner", Value: "lead"}, `# @metadata description:"the owner:bob and team:net" lead:alice`},
+	}
+	for _, tt := range tests {
+		updated, _ := cf.ApplyBulkEdit(content, resources, tt.edit)
+		if got := strings.SplitN(updated, "\n", 2)[0]; got != tt.wantLine {
+			t.Errorf("%s %s: comment line = %q, want %q", tt.edit.Op, tt.edit.Field, got, tt.wantLine)
+		}
+	}
+}
+
+func TestApplyBulkEditRenameConflict(t *testing.T) {
+	cf := NewCommentFixer(afero.NewMemMapFs(), validator.ValidationSchema{})
+
+	// The second resource already has the target field, so only the first is renamed
+	content := strings.Replace(bulkTestContent, "# @metadata owner:bob", "# @metadata owner:bob lead:carol", 1)
+	resources := parseBulkTestContent(t, content)
+	edit := BulkEdit{Op: OpRename, Prefix: "@metadata", Field: "owner", Value: "lead"}
+
+	var conflicts []string
+	for _, resource := range resources {
+		if edit.Conflict(resource) != "" {
+			conflicts = append(conflicts, resource.Name)
+		}
+	}
+	if len(conflicts) != 1 || conflicts[0] != "web_a" {
+		t.Errorf("Expected a conflict on web_a only, got %v", conflicts)
+	}
+
+	updated, changed := cf.ApplyBulkEdit(content, resources, edit)
+	if len(changed) != 1 || changed[0] != "aws_vpc.main" {
+		t.Errorf("changed %v, want only aws_vpc.main", changed)
+	}
+	if !strings.Contains(updated, "# @metadata owner:bob lead:carol") {
+		t.Errorf("Expected the conflicting resource to be left unchanged:\n%s", updated)
+	}
+}
+
+func TestSelectorMatches(t *testing.T) {
+	resources := parseBulkTestContent(t, bulkTestContent)
+
+	tests := []struct {
+		name     string
+		file     string
+		selector Selector
+		want     []string
+	}{
+		{"empty selector", "main.tf", Selector{}, []string{"main", "web_a", "logs"}},
+		{"type glob", "main.tf", Selector{Type: "aws_s*"}, []string{"web_a", "logs"}},
+		{"name glob", "main.tf", Selector{Name: "web_*"}, []string{"web_a"}},
+		{"path directory", "modules/network/main.tf", Selector{Path: "modules/network"}, []string{"main", "web_a", "logs"}},
+		{"path mismatch", "modules/compute/main.tf", Selector{Path: "modules/network"}, nil},
+		{"where any prefix", "main.tf", Selector{Where: map[string]string{"owner": "b*"}}, []string{"web_a"}},
+		{"where scoped nested", "main.tf", Selector{Where: map[string]string{"@metadata:contact.email": "*@example.com"}}, []string{"main"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, r := range resources {
+				if tt.selector.Matches(tt.file, r) {
+					got = append(got, r.Name)
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestBulkEditValidate(t *testing.T) {
+	if err := (BulkEdit{Op: OpSet, Prefix: "@metadata", Field: "owner", Value: "a b"}).Validate(); err != nil {
+		t.Errorf("unexpected error for value containing whitespace: %v", err)
+	}
+	if err := (BulkEdit{Op: OpSet, Prefix: "@metadata", Field: "owner", Value: `it's "a b"`}).Validate(); err == nil {
+		t.Error("expected error for value with whitespace and both quote characters")
+	}
+	if err := (BulkEdit{Op: OpSet, Prefix: "@metadata", Field: "owner", Value: "a\nb"}).Validate(); err == nil {
+		t.Error("expected error for value spanning lines")
+	}
+	if err := (BulkEdit{Op: OpRename, Prefix: "@metadata", Field: "owner", Value: "bad name"}).Validate(); err == nil {
+		t.Error("expected error for invalid new field name")
+	}
+	if err := (BulkEdit{Op: "replace", Prefix: "@metadata", Field: "owner"}).Validate(); err == nil {
+		t.Error("expected error for unknown operation")
+	}
+	if err := (BulkEdit{Op: OpUnset, Prefix: "@metadata", Field: "contact.email"}).Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
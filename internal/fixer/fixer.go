@@ -3,6 +3,7 @@ package fixer
 import (
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 
 	"github.com/spf13/afero"
@@ -26,6 +27,19 @@ func NewCommentFixer(fs afero.Fs, schema validator.ValidationSchema) *CommentFix
 
 // FixFile attempts to fix validation errors in a Terraform file
 func (cf *CommentFixer) FixFile(filename string, resources []parser.TerraformResource, errors []validator.ValidationError) (string, int, error) {
+	return cf.fixFile(filename, resources, errors, nil)
+}
+
+// fieldResolver supplies values for missing fields in place of placeholders
+type fieldResolver interface {
+	// beginResource is called once before the fields of a resource are resolved
+	beginResource(resource parser.TerraformResource, lines []string, errors []validator.ValidationError)
+	// resolveField returns the value to write for a missing field
+	resolveField(resource parser.TerraformResource, prefix, field, placeholder string) (string, error)
+}
+
+// fixFile applies fixes to a Terraform file, asking the resolver (if any) for field values
+func (cf *CommentFixer) fixFile(filename string, resources []parser.TerraformResource, errors []validator.ValidationError, resolver fieldResolver) (string, int, error) {
 	// #nosec G304 - File provided by user via CLI, using afero abstraction
 	f, err := cf.fs.Open(filename)
 	if err != nil {
@@ -41,13 +55,15 @@ func (cf *CommentFixer) FixFile(filename string, resources []parser.TerraformRes
 	lines := strings.Split(string(content), "\n")
 	fixCount := 0
 
+	// Lines inserted so far, used to shift the positions of later resources
+	offset := 0
+
 	// Group errors by resource
 	errorsByResource := cf.groupErrorsByResource(errors)
 
 	// Process each resource
 	for _, resource := range resources {
-		resourceKey := fmt.Sprintf("%s.%s", resource.Type, resource.Name)
-		resourceErrors, hasErrors := errorsByResource[resourceKey]
+		resourceErrors, hasErrors := errorsByResource[resource.Address()]
 
 		if !hasErrors {
 			continue
@@ -65,34 +81,63 @@ func (cf *CommentFixer) FixFile(filename string, resources []parser.TerraformRes
 			continue
 		}
 
+		if resolver != nil {
+			if err := resolveFixes(resolver, resource, lines, resourceErrors, fixes); err != nil {
+				return "", 0, err
+			}
+		}
+
 		// Insert comment block immediately before the resource declaration
 		// Skip any existing comments directly above the resource
-		insertLine := cf.findInsertionPoint(lines, resource.StartLine)
+		insertLine := cf.findInsertionPoint(lines, resource.StartLine-1+offset)
 
 		// Build comment block
 		commentBlock := cf.buildCommentBlock(fixes)
 
 		// Insert the comment block
 		lines = cf.insertLines(lines, insertLine, commentBlock)
+		offset += len(commentBlock)
 		fixCount += len(fixes)
 	}
 
 	return strings.Join(lines, "\n"), fixCount, nil
 }
 
-// groupErrorsByResource groups validation errors by resource
+// resolveFixes asks the resolver for the value of each field of a resource's fixes
+func resolveFixes(resolver fieldResolver, resource parser.TerraformResource, lines []string, errors []validator.ValidationError, fixes []CommentFix) error {
+	resolver.beginResource(resource, lines, errors)
+	for _, fix := range fixes {
+		for _, field := range sortedFields(fix) {
+			value, err := resolver.resolveField(resource, fix.Prefix, field, fix.Fields[field])
+			if err != nil {
+				return err
+			}
+			fix.Fields[field] = value
+		}
+	}
+	return nil
+}
+
+// sortedFields returns the fields of a fix in sorted order
+func sortedFields(fix CommentFix) []string {
+	fields := make([]string, 0, len(fix.Fields))
+	for field := range fix.Fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// groupErrorsByResource groups validation errors by resource address. Errors on attribute and
+// nested block annotations (fields scoped as "path/prefix:field") are not fixed.
 func (cf *CommentFixer) groupErrorsByResource(errors []validator.ValidationError) map[string][]validator.ValidationError {
 	result := make(map[string][]validator.ValidationError)
 
 	for _, err := range errors {
-		// Remove filename suffix if present
-		resourceType := err.ResourceType
-		if idx := strings.Index(resourceType, " ("); idx != -1 {
-			resourceType = resourceType[:idx]
+		if strings.Contains(err.Field, "/") {
+			continue
 		}
-
-		key := fmt.Sprintf("%s.%s", resourceType, err.ResourceName)
-		result[key] = append(result[key], err)
+		result[err.Address()] = append(result[err.Address()], err)
 	}
 
 	return result
@@ -153,6 +198,9 @@ func (cf *CommentFixer) generateFixes(resource parser.TerraformResource, errors
 		}
 	}
 
+	// Keep fix order stable so output and prompts are deterministic
+	sort.SliceStable(fixes, func(i, j int) bool { return fixes[i].Prefix < fixes[j].Prefix })
+
 	return fixes
 }
 
@@ -176,14 +224,14 @@ func (cf *CommentFixer) generatePrefixFix(prefix string, rules validator.Resourc
 
 	// Add placeholders for all required fields
 	for _, field := range prefixRule.RequiredFields {
-		fix.Fields[field] = cf.getPlaceholderValue(field)
+		fix.Fields[field] = cf.getPlaceholderValue(prefix, field)
 	}
 
 	// Add placeholders for required nested fields
-	for nestedPath, nestedRule := range prefixRule.NestedFields {
-		for _, field := range nestedRule.RequiredFields {
+	for _, nestedPath := range prefixRule.OrderedNestedPaths() {
+		for _, field := range prefixRule.NestedFields[nestedPath].RequiredFields {
 			fullPath := nestedPath + "." + field
-			fix.Fields[fullPath] = cf.getPlaceholderValue(field)
+			fix.Fields[fullPath] = cf.getPlaceholderValue(prefix, fullPath)
 		}
 	}
 
@@ -198,40 +246,26 @@ func (cf *CommentFixer) generateFieldFix(prefix string, fields []string, rules v
 	}
 
 	for _, field := range fields {
-		fix.Fields[field] = cf.getPlaceholderValue(field)
+		fix.Fields[field] = cf.getPlaceholderValue(prefix, field)
 	}
 
 	return fix
 }
 
-// getPlaceholderValue returns a placeholder value for a field
-func (cf *CommentFixer) getPlaceholderValue(field string) string {
+// getPlaceholderValue returns a placeholder value for a field at a dotted path under a prefix.
+// Every value matches validator.DefaultPlaceholderPatterns, so fixed annotations are reported
+// as placeholders until real values are filled in; values of non-string types fail validation
+// until then.
+func (cf *CommentFixer) getPlaceholderValue(prefix, field string) string {
 	// Remove nested path if present
 	parts := strings.Split(field, ".")
 	fieldName := parts[len(parts)-1]
 
-	// Common field placeholders
+	// Placeholders shaped like the values of common fields
 	placeholders := map[string]string{
-		"owner":             "CHANGEME",
-		"team":              "CHANGEME",
-		"priority":          "medium",
-		"environment":       "production",
-		"email":             "changeme@example.com",
-		"slack":             "@changeme",
-		"phone":             "555-0000",
-		"description":       "CHANGEME: Add description",
-		"required":          "true",
-		"enabled":           "true",
-		"backup":            "true",
-		"encrypted":         "true",
-		"cost_center":       "CHANGEME",
-		"department":        "CHANGEME",
-		"emergency_contact": "oncall@example.com",
-		"uptime":            "99.9",
-		"replicas":          "3",
-		"backup_required":   "true",
-		"mfa_required":      "true",
-		"password_policy":   "strict",
+		"email":       "changeme@example.com",
+		"slack":       "@changeme",
+		"description": "CHANGEME: Add description",
 	}
 
 	if val, exists := placeholders[fieldName]; exists {
@@ -239,26 +273,21 @@ func (cf *CommentFixer) getPlaceholderValue(field string) string {
 	}
 
 	// Check field validation for type hints
-	if validation, exists := cf.schema.FieldValidations[fieldName]; exists {
-		if len(validation.AllowedValues) > 0 {
-			return validation.AllowedValues[0]
+	if validation, exists := cf.schema.FieldValidationFor(prefix, field); exists {
+		kind := validation.Type
+		if validation.Format != "" {
+			kind = validation.Format
 		}
 
-		switch validation.Type {
-		case "boolean":
-			return "true"
-		case "integer":
-			if validation.Min > 0 {
-				return fmt.Sprintf("%d", int(validation.Min))
-			}
-			return "1"
-		case "float":
-			if validation.Min > 0 {
-				return fmt.Sprintf("%.1f", validation.Min)
-			}
-			return "1.0"
+		switch kind {
 		case "array":
 			return "[CHANGEME]"
+		case validator.FormatEmail:
+			return "changeme@example.com"
+		case validator.FormatURL:
+			return "https://changeme.example.com"
+		case validator.FormatSlack:
+			return "@changeme"
 		}
 	}
 
@@ -319,7 +348,8 @@ func (cf *CommentFixer) buildCommentBlock(fixes []CommentFix) []string {
 		lines = append(lines, commentLine)
 
 		// Add nested fields on separate lines in schema order
-		for nestedPath, nestedRule := range prefixRule.NestedFields {
+		for _, nestedPath := range prefixRule.OrderedNestedPaths() {
+			nestedRule := prefixRule.NestedFields[nestedPath]
 			if fieldMap, ok := nestedFields[nestedPath]; ok && len(fieldMap) > 0 {
 				nestedLine := "#"
 
@@ -421,12 +451,12 @@ func (cf *CommentFixer) insertLines(lines []string, position int, newLines []str
 	return result
 }
 
-// hasValidComments checks if a resource already has valid comments that satisfy the schema
-// This includes placeholders like "CHANGEME" which are considered valid
+// hasValidComments checks if a resource already has a comment for every required prefix, so the
+// fixer leaves it alone. Placeholder values like "CHANGEME" don't make comments valid: they are
+// reported by the placeholder rule until they are filled in by hand.
 func (cf *CommentFixer) hasValidComments(resource parser.TerraformResource, errors []validator.ValidationError) bool {
 	// If there are validation errors for this resource, comments are not valid
 	// However, we need to check if the errors are only about missing prefixes/fields
-	// If comments exist with placeholder values (like "CHANGEME"), they're considered valid
 
 	// Check if any of the resource's comments match the schema structure
 	for _, comment := range resource.PrecedingComments {
@@ -434,8 +464,7 @@ func (cf *CommentFixer) hasValidComments(resource parser.TerraformResource, erro
 		if strings.HasPrefix(comment.Raw, "# @") || strings.HasPrefix(comment.Raw, "# terraform:") {
 			// This looks like a managed comment - check if it has fields
 			if strings.Contains(comment.Raw, ":") {
-				// Comment has fields, consider it valid even if values are placeholders
-				// Only skip if ALL required prefixes have at least some comment
+				// Comment has fields; only skip if ALL required prefixes have at least some comment
 				return cf.allPrefixesHaveComments(resource, errors)
 			}
 		}
@@ -525,6 +554,7 @@ func (cf *CommentFixer) getApplicableRules(resourceType string) validator.Resour
 	return validator.ResourceRules{
 		RequiredPrefixes: cf.schema.Global.RequiredPrefixes,
 		PrefixRules:      cf.schema.Global.PrefixRules,
+		PrefixOrder:      cf.schema.Global.PrefixOrder,
 	}
 }
 
@@ -102,9 +102,21 @@ func TestBuildCommentBlock(t *testing.T) {
 
 func TestGetPlaceholderValue(t *testing.T) {
 	fs := afero.NewMemMapFs()
-	schema := validator.ValidationSchema{}
+	schema := validator.ValidationSchema{
+		FieldValidations: map[string]validator.FieldValidation{
+			"priority": {Type: "enum", AllowedValues: []string{"low", "high"}},
+			"replicas": {Type: "integer", Min: bound(1)},
+			"runbook":  {Type: "url"},
+		},
+	}
 	fixer := NewCommentFixer(fs, schema)
 
+	// Every placeholder is detected as one by the default placeholder patterns
+	placeholders, err := validator.NewSchemaValidatorFromSchema(fs, validator.ValidationSchema{})
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+
 	tests := []struct {
 		field    string
 		expected string
@@ -113,14 +125,22 @@ func TestGetPlaceholderValue(t *testing.T) {
 		{"team", "CHANGEME"},
 		{"purpose", "CHANGEME"},
 		{"unknown_field", "CHANGEME"},
+		{"environment", "CHANGEME"},
+		{"priority", "CHANGEME"},
+		{"replicas", "CHANGEME"},
+		{"contact.email", "changeme@example.com"},
+		{"runbook", "https://changeme.example.com"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.field, func(t *testing.T) {
-			got := fixer.getPlaceholderValue(tt.field)
+			got := fixer.getPlaceholderValue("@metadata", tt.field)
 			if got != tt.expected {
 				t.Errorf("getPlaceholderValue(%q) = %q, want %q", tt.field, got, tt.expected)
 			}
+			if !placeholders.IsPlaceholder(got) {
+				t.Errorf("getPlaceholderValue(%q) = %q is not detected as a placeholder", tt.field, got)
+			}
 		})
 	}
 }
@@ -343,6 +363,73 @@ func TestFixFile(t *testing.T) {
 	}
 }
 
+func TestFixFile_InsertsAboveEachResource(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	tfContent := `terraform {
+}
+# vpc
+resource "aws_vpc" "main" {
+}
+# subnet
+resource "aws_subnet" "a" {
+}
+# route
+resource "aws_route" "r" {
+}
+`
+	err := afero.WriteFile(fs, "/test.tf", []byte(tfContent), 0644)
+	if err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	schema := validator.ValidationSchema{
+		Global: validator.GlobalRules{
+			RequiredPrefixes: []string{"@metadata"},
+			PrefixRules: map[string]validator.PrefixRule{
+				"@metadata": {RequiredFields: []string{"owner"}},
+			},
+		},
+	}
+
+	p := parser.NewCommentParser(fs, []string{"@metadata"})
+	resources, err := p.ParseFile("/test.tf")
+	if err != nil {
+		t.Fatalf("failed to parse test file: %v", err)
+	}
+
+	var errors []validator.ValidationError
+	for _, resource := range resources {
+		errors = append(errors, validator.ValidationError{
+			ResourceType: resource.Type,
+			ResourceName: resource.Name,
+			Message:      "Missing required comment prefix: @metadata",
+		})
+	}
+
+	fixedContent, _, err := NewCommentFixer(fs, schema).FixFile("/test.tf", resources, errors)
+	if err != nil {
+		t.Fatalf("FixFile failed: %v", err)
+	}
+
+	// Each comment lands directly above its resource line (StartLine is 1-based), and the
+	// lines inserted for earlier resources don't shift the comments of later ones
+	lines := strings.Split(fixedContent, "\n")
+	resourceLines := 0
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "resource ") {
+			continue
+		}
+		resourceLines++
+		if i == 0 || lines[i-1] != "# @metadata owner:CHANGEME" {
+			t.Errorf("Expected @metadata comment directly above %q:\n%s", line, fixedContent)
+		}
+	}
+	if resourceLines != 3 || strings.Count(fixedContent, "@metadata") != 3 || !strings.Contains(fixedContent, "}\n# subnet\n") {
+		t.Errorf("Unexpected fixed content:\n%s", fixedContent)
+	}
+}
+
 func TestCopyFile(t *testing.T) {
 	fs := afero.NewMemMapFs()
 
@@ -0,0 +1,141 @@
+package fixer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/toozej/terranotate/internal/parser"
+	"github.com/toozej/terranotate/internal/validator"
+)
+
+// contextLines is the number of source lines shown around a resource declaration
+const contextLines = 3
+
+// InteractiveFixer fixes validation errors by prompting for each missing field
+// value instead of inserting placeholders
+type InteractiveFixer struct {
+	*CommentFixer
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// NewInteractiveFixer creates a new interactive fixer reading answers from in and writing prompts to out
+func NewInteractiveFixer(fs afero.Fs, schema validator.ValidationSchema, in io.Reader, out io.Writer) *InteractiveFixer {
+	// Reuse an existing buffered reader so answers are not lost between files
+	reader, ok := in.(*bufio.Reader)
+	if !ok {
+		reader = bufio.NewReader(in)
+	}
+
+	return &InteractiveFixer{
+		CommentFixer: NewCommentFixer(fs, schema),
+		in:           reader,
+		out:          out,
+	}
+}
+
+// FixFile attempts to fix validation errors in a Terraform file, prompting for missing values
+func (ix *InteractiveFixer) FixFile(filename string, resources []parser.TerraformResource, errors []validator.ValidationError) (string, int, error) {
+	return ix.fixFile(filename, resources, errors, ix)
+}
+
+// beginResource shows the failing resource, its errors and surrounding source
+func (ix *InteractiveFixer) beginResource(resource parser.TerraformResource, lines []string, errors []validator.ValidationError) {
+	_, _ = fmt.Fprintf(ix.out, "\n🔧 %s (line %d)\n", resource.Address(), resource.StartLine)
+	_, _ = fmt.Fprintln(ix.out, strings.Repeat("-", 60))
+
+	for _, err := range errors {
+		_, _ = fmt.Fprintf(ix.out, "  ❌ %s\n", err.Message)
+	}
+	_, _ = fmt.Fprintln(ix.out)
+
+	// Show source context around the resource declaration
+	start := resource.StartLine - 1 - contextLines
+	if start < 0 {
+		start = 0
+	}
+	end := resource.StartLine + contextLines
+	if resource.EndLine > 0 && resource.EndLine < end {
+		end = resource.EndLine
+	}
+	if end > len(lines) {
+		end = len(lines)
+	}
+	for i := start; i < end; i++ {
+		_, _ = fmt.Fprintf(ix.out, "  %4d | %s\n", i+1, lines[i])
+	}
+	_, _ = fmt.Fprintln(ix.out)
+}
+
+// resolveField prompts for a field value until it passes the schema's field validation. An
+// empty answer keeps the placeholder, to be reported by validation until it is filled in.
+func (ix *InteractiveFixer) resolveField(resource parser.TerraformResource, prefix, field, placeholder string) (string, error) {
+	validation, hasValidation := ix.schema.FieldValidationFor(prefix, field)
+
+	if hasValidation && len(validation.AllowedValues) > 0 {
+		_, _ = fmt.Fprintln(ix.out, "  Choices:")
+		for i, allowed := range validation.AllowedValues {
+			_, _ = fmt.Fprintf(ix.out, "    %d) %s\n", i+1, allowed)
+		}
+	}
+
+	for {
+		_, _ = fmt.Fprintf(ix.out, "  %s %s [%s]: ", prefix, field, placeholder)
+
+		input, err := ix.in.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("failed to read input: %w", err)
+		}
+		if errors.Is(err, io.EOF) && input == "" {
+			return "", fmt.Errorf("input closed while fixing %s", resource.Address())
+		}
+
+		value := strings.TrimSpace(input)
+		if value == "" {
+			return placeholder, nil
+		}
+
+		// Allow choosing an allowed value by its number
+		if hasValidation && len(validation.AllowedValues) > 0 {
+			if idx, convErr := strconv.Atoi(value); convErr == nil && idx >= 1 && idx <= len(validation.AllowedValues) {
+				value = validation.AllowedValues[idx-1]
+			}
+		}
+
+		if problem := ix.checkInput(prefix, field, value, validation, hasValidation); problem != "" {
+			_, _ = fmt.Fprintf(ix.out, "  ⚠️  %s\n", problem)
+			continue
+		}
+
+		return value, nil
+	}
+}
+
+// checkInput returns a description of why a value cannot be written, or "" if it is acceptable
+func (ix *InteractiveFixer) checkInput(prefix, field, value string, validation validator.FieldValidation, hasValidation bool) string {
+	if strings.ContainsAny(value, " \t") {
+		return "Values cannot contain whitespace"
+	}
+
+	if !hasValidation {
+		return ""
+	}
+
+	// Check the value as it will be parsed once written
+	fieldType, _ := ix.schema.FieldType(prefix, field)
+	violations := validation.CheckValue(field, parser.CoerceValue(value, fieldType))
+	if len(violations) == 0 {
+		return ""
+	}
+
+	messages := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		messages = append(messages, violation.Message)
+	}
+	return strings.Join(messages, "; ")
+}
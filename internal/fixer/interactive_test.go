@@ -0,0 +1,97 @@
+package fixer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/toozej/terranotate/internal/parser"
+	"github.com/toozej/terranotate/internal/validator"
+)
+
+func TestInteractiveFixFile(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	tfContent := `locals {
+  name = "example"
+}
+
+resource "aws_vpc" "main" {
+  cidr_block = "10.0.0.0/16"
+}
+`
+	err := afero.WriteFile(fs, "/test.tf", []byte(tfContent), 0644)
+	if err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	schema := validator.ValidationSchema{
+		Global: validator.GlobalRules{
+			RequiredPrefixes: []string{"@metadata"},
+			PrefixRules: map[string]validator.PrefixRule{
+				"@metadata": {
+					RequiredFields: []string{"owner", "priority", "replicas"},
+				},
+			},
+		},
+		FieldValidations: map[string]validator.FieldValidation{
+			"owner":    {Type: "string", Pattern: `^[a-z]+\.[a-z]+$`},
+			"priority": {Type: "string", AllowedValues: []string{"low", "medium", "high"}},
+			"replicas": {Type: "integer", Min: bound(1), Max: bound(10)},
+		},
+	}
+
+	resources := []parser.TerraformResource{
+		{Type: "aws_vpc", Name: "main", Module: "module.network", StartLine: 5, EndLine: 7},
+	}
+	errors := []validator.ValidationError{
+		{ResourceType: "aws_vpc", ResourceName: "main", Module: "module.network", Message: "Missing required comment prefix: @metadata"},
+	}
+
+	// Fields are prompted in sorted order: owner, priority, replicas
+	input := strings.Join([]string{
+		"Alice Smith", // whitespace rejected
+		"alice",       // pattern rejected
+		"alice.smith",
+		"3",  // choice number for "high"
+		"42", // above maximum
+		"",   // keep the placeholder
+	}, "\n") + "\n"
+	var out bytes.Buffer
+
+	fixer := NewInteractiveFixer(fs, schema, strings.NewReader(input), &out)
+	fixedContent, fixCount, err := fixer.FixFile("/test.tf", resources, errors)
+	if err != nil {
+		t.Fatalf("FixFile failed: %v", err)
+	}
+
+	if fixCount != 1 {
+		t.Errorf("Expected 1 fix, got %d", fixCount)
+	}
+
+	// The annotation goes between the locals block and the resource, not inside the resource
+	expected := "}\n# @metadata owner:alice.smith priority:high replicas:CHANGEME\n\nresource \"aws_vpc\" \"main\" {"
+	if !strings.Contains(fixedContent, expected) {
+		t.Errorf("Fixed content missing expected annotation above resource:\n%s", fixedContent)
+	}
+
+	prompts := out.String()
+	for _, want := range []string{"module.network.aws_vpc.main (line 5)", "5 | resource", "1) low", "whitespace", "does not match required pattern", "exceeds maximum"} {
+		if !strings.Contains(prompts, want) {
+			t.Errorf("Expected prompt output to contain %q:\n%s", want, prompts)
+		}
+	}
+
+	// Running out of input aborts the fix
+	fixer = NewInteractiveFixer(fs, schema, strings.NewReader("alice.smith\n"), &out)
+	_, _, err = fixer.FixFile("/test.tf", resources, errors)
+	if err == nil || !strings.Contains(err.Error(), "module.network.aws_vpc.main") {
+		t.Errorf("Expected error naming the resource address when input is exhausted, got %v", err)
+	}
+}
+
+// bound returns a pointer to a numeric bound
+func bound(value float64) *float64 {
+	return &value
+}
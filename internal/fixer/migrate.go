@@ -0,0 +1,294 @@
+package fixer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/toozej/terranotate/internal/parser"
+	"github.com/toozej/terranotate/internal/sidecar"
+	"github.com/toozej/terranotate/internal/validator"
+)
+
+// MigrationIssue describes an annotation a migration could not rewrite
+type MigrationIssue struct {
+	Version  int    // Migration version
+	Resource string // Resource address, e.g. module.vpc.aws_subnet.private; "" for inherited annotations
+	Line     int    // Resource start line, or the line of the inherited annotation
+	Source   string // Inherited annotation the issue is in, e.g. "directory defaults (...)"; "" for the resource's own
+	Message  string
+}
+
+// migrationOp is a single rename, move, split or value mapping from a migration
+type migrationOp struct {
+	kind   string
+	from   string
+	to     string
+	split  validator.SplitRule
+	values map[string]string
+}
+
+// migrationOps expands a migration into its operations in application order
+func migrationOps(m validator.Migration) []migrationOp {
+	var ops []migrationOp
+	for _, kind := range []string{"rename", "move"} {
+		fields := m.Rename
+		if kind == "move" {
+			fields = m.Move
+		}
+		for _, from := range sortedKeys(fields) {
+			ops = append(ops, migrationOp{kind: kind, from: from, to: fields[from]})
+		}
+	}
+	for _, rule := range m.Split {
+		ops = append(ops, migrationOp{kind: "split", from: rule.Field, split: rule})
+	}
+	mapped := make([]string, 0, len(m.MapValues))
+	for field := range m.MapValues {
+		mapped = append(mapped, field)
+	}
+	sort.Strings(mapped)
+	for _, field := range mapped {
+		ops = append(ops, migrationOp{kind: "map", from: field, values: m.MapValues[field]})
+	}
+	return ops
+}
+
+// sortedKeys returns the keys of a field mapping in sorted order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ApplyMigration rewrites the annotations in content, including its file header, and those of
+// its resources in the given sidecar files, according to a migration, re-parsing with p between
+// edits. It returns the updated content and the annotations it could not migrate. Annotations
+// inherited from module calls and directory defaults are reported as issues with their Source,
+// once per file, for they are shared with other files and must be migrated by hand.
+func (cf *CommentFixer) ApplyMigration(p *parser.CommentParser, filename, content string, sidecars []*sidecar.File, m validator.Migration) (string, []MigrationIssue, error) {
+	prefix := m.GetPrefix()
+	var issues []MigrationIssue
+
+	for _, op := range migrationOps(m) {
+		var err error
+		var inheritedIssues []MigrationIssue
+		content, inheritedIssues, err = cf.migrateInherited(p, filename, content, prefix, m.Version, op)
+		if err != nil {
+			return content, issues, err
+		}
+		issues = append(issues, inheritedIssues...)
+
+		resources, err := p.ParseSource(filename, []byte(content))
+		if err != nil {
+			return content, issues, err
+		}
+
+		// Work from the bottom of the file up so line numbers of earlier resources stay valid
+		sort.SliceStable(resources, func(i, j int) bool { return resources[i].StartLine > resources[j].StartLine })
+
+		for _, resource := range resources {
+			resource.Inherited = nil
+
+			// Comments and sidecar annotations are planned separately so each is edited in place
+			comments, annotations := resource, resource
+			comments.SidecarComments = nil
+			annotations.PrecedingComments, annotations.InlineComments = nil, nil
+
+			edits, problem := planMigrationOp(comments, resource, prefix, op)
+			sidecarEdits, sidecarProblem := planMigrationOp(annotations, resource, prefix, op)
+			if problem == "" {
+				problem = sidecarProblem
+			}
+			for _, edit := range append(append([]BulkEdit{}, edits...), sidecarEdits...) {
+				if problem != "" {
+					break
+				}
+				if err := edit.Validate(); err != nil {
+					problem = err.Error()
+				}
+			}
+			if problem != "" {
+				issues = append(issues, MigrationIssue{
+					Version:  m.Version,
+					Resource: resource.Address(),
+					Line:     resource.StartLine,
+					Message:  problem,
+				})
+				continue
+			}
+
+			for _, edit := range edits {
+				content, err = cf.applyToResource(p, filename, content, resource, edit)
+				if err != nil {
+					return content, issues, err
+				}
+			}
+			for _, edit := range sidecarEdits {
+				applyToSidecars(sidecars, resource, edit)
+			}
+		}
+	}
+
+	return content, issues, nil
+}
+
+// migrateInherited applies an operation to the file header annotations in content and reports
+// the other inherited annotations of its resources that the operation applies to
+func (cf *CommentFixer) migrateInherited(p *parser.CommentParser, filename, content, prefix string, version int, op migrationOp) (string, []MigrationIssue, error) {
+	resources, err := p.ParseSource(filename, []byte(content))
+	if err != nil {
+		return content, nil, err
+	}
+
+	// Every resource inherits the same annotations, each listed once
+	var headers []parser.StructuredComment
+	var issues []MigrationIssue
+	seen := make(map[string]bool)
+	for _, resource := range resources {
+		for _, comment := range resource.Inherited {
+			if comment.Prefix != prefix || seen[comment.Source] {
+				continue
+			}
+			seen[comment.Source] = true
+
+			if comment.Scope == parser.ScopeFile {
+				headers = append(headers, comment)
+				continue
+			}
+			source := parser.TerraformResource{PrecedingComments: []parser.StructuredComment{comment}}
+			edits, problem := planMigrationOp(source, source, prefix, op)
+			if problem == "" && len(edits) > 0 {
+				problem = fmt.Sprintf("cannot %s '%s' in an inherited annotation; migrate it by hand", op.kind, op.from)
+			}
+			if problem != "" {
+				issues = append(issues, MigrationIssue{Version: version, Line: comment.Line, Source: comment.Source, Message: problem})
+			}
+		}
+	}
+
+	// Work from the bottom of the file up so line numbers of earlier headers stay valid
+	sort.SliceStable(headers, func(i, j int) bool { return headers[i].Line > headers[j].Line })
+
+	for _, comment := range headers {
+		// The header is edited as the only comment of a resource placed right below it
+		header := parser.TerraformResource{StartLine: comment.EndLine + 1, PrecedingComments: []parser.StructuredComment{comment}}
+		edits, problem := planMigrationOp(header, header, prefix, op)
+		for _, edit := range edits {
+			if problem != "" {
+				break
+			}
+			if err := edit.Validate(); err != nil {
+				problem = err.Error()
+			}
+		}
+		if problem != "" {
+			issues = append(issues, MigrationIssue{Version: version, Line: comment.Line, Source: comment.Source, Message: problem})
+			continue
+		}
+		// Edits stay within the header's lines until the last, which may remove a line
+		for _, edit := range edits {
+			content, _ = cf.ApplyBulkEdit(content, []parser.TerraformResource{header}, edit)
+		}
+	}
+
+	return content, issues, nil
+}
+
+// applyToSidecars applies an edit to a resource's entries in sidecar files. Set adds fields to
+// the files that already annotate the resource with the edit's prefix.
+func applyToSidecars(sidecars []*sidecar.File, resource parser.TerraformResource, edit BulkEdit) {
+	addresses := sidecar.Addresses(resource)
+	for _, file := range sidecars {
+		switch edit.Op {
+		case OpSet:
+			if file.Has(addresses, edit.Prefix) && !file.Replace(addresses, edit.Prefix, edit.Field, edit.Value) {
+				file.Set(addresses, edit.Prefix, edit.Field, edit.Value)
+			}
+		case OpUnset:
+			file.Unset(addresses, edit.Prefix, edit.Field)
+		case OpRename:
+			file.Rename(addresses, edit.Prefix, edit.Field, edit.Value)
+		}
+	}
+}
+
+// applyToResource re-parses content and applies an edit to the resource with the same address
+func (cf *CommentFixer) applyToResource(p *parser.CommentParser, filename, content string, target parser.TerraformResource, edit BulkEdit) (string, error) {
+	resources, err := p.ParseSource(filename, []byte(content))
+	if err != nil {
+		return content, err
+	}
+
+	for _, resource := range resources {
+		if resource.Address() == target.Address() {
+			updated, _ := cf.ApplyBulkEdit(content, []parser.TerraformResource{resource}, edit)
+			return updated, nil
+		}
+	}
+
+	return content, fmt.Errorf("resource %s not found after edit", target.Address())
+}
+
+// planMigrationOp returns the edits that apply an operation to the annotations of source, or a
+// description of why they cannot be migrated. Target fields must not be set anywhere on the
+// resource.
+func planMigrationOp(source, resource parser.TerraformResource, prefix string, op migrationOp) ([]BulkEdit, string) {
+	value := source.GetNestedField(prefix, op.from)
+	if value == nil {
+		return nil, ""
+	}
+	current := fmt.Sprintf("%v", value)
+
+	switch op.kind {
+	case "rename", "move":
+		if resource.GetNestedField(prefix, op.to) != nil {
+			return nil, fmt.Sprintf("cannot %s '%s' to '%s': '%s' is already set", op.kind, op.from, op.to, op.to)
+		}
+		return []BulkEdit{{Op: OpRename, Prefix: prefix, Field: op.from, Value: op.to}}, ""
+
+	case "split":
+		if _, nested := value.(map[string]interface{}); nested {
+			return nil, fmt.Sprintf("cannot split '%s': it has nested fields", op.from)
+		}
+		parts := strings.Split(current, op.split.Separator)
+		if len(parts) != len(op.split.Into) {
+			return nil, fmt.Sprintf("cannot split '%s' value '%s' on '%s' into %d fields (%s)",
+				op.from, current, op.split.Separator, len(op.split.Into), strings.Join(op.split.Into, ", "))
+		}
+		var edits []BulkEdit
+		for i, field := range op.split.Into {
+			if field != op.from && resource.GetNestedField(prefix, field) != nil {
+				return nil, fmt.Sprintf("cannot split '%s' into '%s': '%s' is already set", op.from, field, field)
+			}
+			edits = append(edits, BulkEdit{Op: OpSet, Prefix: prefix, Field: field, Value: parts[i]})
+		}
+		// The original field is removed last (unless reused as a target) so the new fields
+		// are added to its comment rather than a new one
+		reused := false
+		for _, field := range op.split.Into {
+			if field == op.from {
+				reused = true
+			}
+		}
+		if !reused {
+			edits = append(edits, BulkEdit{Op: OpUnset, Prefix: prefix, Field: op.from})
+		}
+		return edits, ""
+
+	case "map":
+		if _, nested := value.(map[string]interface{}); nested {
+			return nil, fmt.Sprintf("cannot map values of '%s': it has nested fields", op.from)
+		}
+		mapped, ok := op.values[current]
+		if !ok || mapped == current {
+			return nil, ""
+		}
+		return []BulkEdit{{Op: OpSet, Prefix: prefix, Field: op.from, Value: mapped}}, ""
+	}
+
+	return nil, fmt.Sprintf("unknown migration operation '%s'", op.kind)
+}
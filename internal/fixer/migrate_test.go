@@ -0,0 +1,137 @@
+package fixer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/toozej/terranotate/internal/parser"
+	"github.com/toozej/terranotate/internal/validator"
+)
+
+func TestApplyMigration(t *testing.T) {
+	content := `# @metadata owner:alice.smith cost_center:cc-1 environment:prod
+# contact.email:alice@example.com
+resource "aws_vpc" "main" {
+  cidr_block = "10.0.0.0/16"
+}
+
+
+
+
+
+
+# @metadata owner:bob costcenter:cc-9 cost_center:cc-2 environment:dev
+resource "aws_subnet" "web" {
+  vpc_id = "x"
+}
+`
+
+	migration := validator.Migration{
+		Version:   2,
+		Rename:    map[string]string{"cost_center": "costcenter"},
+		Move:      map[string]string{"contact.email": "contact.primary.email"},
+		Split:     []validator.SplitRule{{Field: "owner", Separator: ".", Into: []string{"owner_first", "owner_last"}}},
+		MapValues: map[string]map[string]string{"environment": {"prod": "production"}},
+	}
+
+	p := parser.NewCommentParser(afero.NewMemMapFs(), []string{"@metadata", "@docs"})
+	cf := NewCommentFixer(afero.NewMemMapFs(), validator.ValidationSchema{})
+
+	updated, issues, err := cf.ApplyMigration(p, "main.tf", content, nil, migration)
+	if err != nil {
+		t.Fatalf("ApplyMigration() failed: %v", err)
+	}
+
+	resources, err := p.ParseSource("main.tf", []byte(updated))
+	if err != nil {
+		t.Fatalf("failed to parse migrated content: %v\n%s", err, updated)
+	}
+	main, web := resources[0], resources[1]
+
+	expected := map[string]interface{}{
+		"costcenter":            "cc-1",
+		"contact.primary.email": "alice@example.com",
+		"owner_first":           "alice",
+		"owner_last":            "smith",
+		"environment":           "production",
+	}
+	for field, want := range expected {
+		if got := main.GetNestedField("@metadata", field); got != want {
+			t.Errorf("main: %s = %v, want %v\n%s", field, got, want, updated)
+		}
+	}
+	for _, field := range []string{"owner", "cost_center", "contact.email"} {
+		if got := main.GetNestedField("@metadata", field); got != nil {
+			t.Errorf("main: expected %s to be migrated away, got %v", field, got)
+		}
+	}
+
+	// The second resource already has the rename target and an unsplittable owner
+	if len(issues) != 2 {
+		t.Fatalf("expected 2 issues, got %d: %v", len(issues), issues)
+	}
+	for _, issue := range issues {
+		if issue.Resource != "aws_subnet.web" || issue.Version != 2 {
+			t.Errorf("unexpected issue: %+v", issue)
+		}
+	}
+	if got := web.GetNestedField("@metadata", "cost_center"); got != "cc-2" {
+		t.Errorf("web: conflicting field should be left unchanged, got %v", got)
+	}
+	if got := web.GetNestedField("@metadata", "environment"); got != "dev" {
+		t.Errorf("web: unmapped value should be left unchanged, got %v", got)
+	}
+
+	// Applying the migration again is a no-op
+	again, issues, err := cf.ApplyMigration(p, "main.tf", updated, nil, migration)
+	if err != nil {
+		t.Fatalf("second ApplyMigration() failed: %v", err)
+	}
+	if again != updated {
+		t.Errorf("expected migration to be idempotent, got:\n%s", again)
+	}
+	if len(issues) != 2 || !strings.Contains(issues[0].Message+issues[1].Message, "already set") {
+		t.Errorf("expected the same issues on re-run, got %v", issues)
+	}
+}
+
+func TestApplyMigrationInherited(t *testing.T) {
+	content := `# @metadata(file) owner:alice.smith team:net
+
+# @metadata cost_center:cc-1
+resource "aws_vpc" "main" {
+  cidr_block = "10.0.0.0/16"
+}
+
+resource "aws_subnet" "web" {
+  vpc_id = "x"
+}
+`
+	migration := validator.Migration{
+		Version: 2,
+		Rename:  map[string]string{"team": "squad"},
+		Split:   []validator.SplitRule{{Field: "owner", Separator: ".", Into: []string{"owner_first", "owner_last"}}},
+	}
+
+	p := parser.NewCommentParser(afero.NewMemMapFs(), []string{"@metadata"})
+	p.SetInheritanceResolver(func(filename string) []parser.StructuredComment {
+		return []parser.StructuredComment{{Prefix: "@metadata", Line: 3, Fields: map[string]interface{}{"team": "core"}, Source: "module call module.net (/main.tf:3)"}}
+	})
+	cf := NewCommentFixer(afero.NewMemMapFs(), validator.ValidationSchema{})
+
+	updated, issues, err := cf.ApplyMigration(p, "main.tf", content, nil, migration)
+	if err != nil {
+		t.Fatalf("ApplyMigration() failed: %v", err)
+	}
+
+	// The file header is migrated in place
+	if !strings.HasPrefix(updated, "# @metadata(file) squad:net owner_first:alice owner_last:smith\n") {
+		t.Errorf("Expected the file header to be migrated, got:\n%s", updated)
+	}
+
+	// The module call annotation is reported once, however many resources inherit it
+	if len(issues) != 1 || issues[0].Source != "module call module.net (/main.tf:3)" || issues[0].Resource != "" {
+		t.Errorf("Expected one issue for the module call annotation, got %+v", issues)
+	}
+}
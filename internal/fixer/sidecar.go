@@ -0,0 +1,106 @@
+package fixer
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/toozej/terranotate/internal/parser"
+	"github.com/toozej/terranotate/internal/sidecar"
+	"github.com/toozej/terranotate/internal/validator"
+)
+
+// FixSidecar attempts to fix validation errors in a Terraform file by adding the missing
+// annotations to the sidecar file of its directory instead of the file itself. The returned
+// sidecar file holds the changes and is not written.
+func (cf *CommentFixer) FixSidecar(filename string, resources []parser.TerraformResource, errors []validator.ValidationError) (*sidecar.File, int, error) {
+	return cf.fixSidecar(filename, resources, errors, nil)
+}
+
+// FixSidecar attempts to fix validation errors in a Terraform file's sidecar file, prompting for
+// missing values
+func (ix *InteractiveFixer) FixSidecar(filename string, resources []parser.TerraformResource, errors []validator.ValidationError) (*sidecar.File, int, error) {
+	return ix.fixSidecar(filename, resources, errors, ix)
+}
+
+// fixSidecar adds fixes to the sidecar file of a Terraform file, asking the resolver (if any)
+// for field values
+func (cf *CommentFixer) fixSidecar(filename string, resources []parser.TerraformResource, errors []validator.ValidationError, resolver fieldResolver) (*sidecar.File, int, error) {
+	file, err := sidecar.Find(cf.fs, filepath.Dir(filename))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// The resolver shows the resource's source for context
+	var lines []string
+	if resolver != nil {
+		// #nosec G304 - File provided by user via CLI, using afero abstraction
+		content, err := afero.ReadFile(cf.fs, filename)
+		if err != nil {
+			return nil, 0, err
+		}
+		lines = strings.Split(string(content), "\n")
+	}
+
+	fixCount := 0
+	errorsByResource := cf.groupErrorsByResource(errors)
+
+	for _, resource := range resources {
+		resourceErrors, hasErrors := errorsByResource[resource.Address()]
+		if !hasErrors || cf.hasValidComments(resource, resourceErrors) {
+			continue
+		}
+
+		fixes := cf.generateFixes(resource, resourceErrors)
+		if len(fixes) == 0 {
+			continue
+		}
+
+		if resolver != nil {
+			if err := resolveFixes(resolver, resource, lines, resourceErrors, fixes); err != nil {
+				return nil, 0, err
+			}
+		}
+
+		// Entries are keyed by the address relative to the directory's module, so they apply to
+		// every instance of the module
+		addresses := []string{resource.Type + "." + resource.Name, resource.Address()}
+		for _, fix := range fixes {
+			added := false
+			for _, field := range sortedFields(fix) {
+				if file.Set(addresses, fix.Prefix, field, fix.Fields[field]) {
+					added = true
+				}
+			}
+			if added {
+				fixCount++
+			}
+		}
+	}
+
+	return file, fixCount, nil
+}
+
+// ApplySidecarBulkEdit applies an edit to the annotations the given resources have in a sidecar
+// file. Set only replaces values the sidecar file already has; new values go to comments. It
+// returns the addresses of the resources that changed.
+func (cf *CommentFixer) ApplySidecarBulkEdit(file *sidecar.File, resources []parser.TerraformResource, edit BulkEdit) []string {
+	var changed []string
+	for _, resource := range resources {
+		addresses := sidecar.Addresses(resource)
+
+		var ok bool
+		switch edit.Op {
+		case OpSet:
+			ok = file.Replace(addresses, edit.Prefix, edit.Field, edit.Value)
+		case OpUnset:
+			ok = file.Unset(addresses, edit.Prefix, edit.Field)
+		case OpRename:
+			ok = file.Rename(addresses, edit.Prefix, edit.Field, edit.Value)
+		}
+		if ok {
+			changed = append(changed, resource.Address())
+		}
+	}
+	return changed
+}
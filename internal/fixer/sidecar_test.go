@@ -0,0 +1,69 @@
+package fixer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/toozej/terranotate/internal/parser"
+	"github.com/toozej/terranotate/internal/validator"
+)
+
+func TestFixSidecar(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	tfContent := `resource "aws_vpc" "main" {
+  cidr_block = "10.0.0.0/16"
+}
+`
+	if err := afero.WriteFile(fs, "/vpc/main.tf", []byte(tfContent), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	sidecarContent := `{"resources": {"aws_vpc.main": {"@metadata": {"owner": "alice"}}}}`
+	if err := afero.WriteFile(fs, "/vpc/terranotate.json", []byte(sidecarContent), 0644); err != nil {
+		t.Fatalf("failed to write sidecar file: %v", err)
+	}
+
+	schema := validator.ValidationSchema{
+		Global: validator.GlobalRules{
+			RequiredPrefixes: []string{"@metadata"},
+			PrefixRules: map[string]validator.PrefixRule{
+				"@metadata": {
+					RequiredFields: []string{"owner", "team"},
+				},
+			},
+		},
+	}
+
+	resources := []parser.TerraformResource{
+		{Type: "aws_vpc", Name: "main", Module: "module.vpc", File: "/vpc/main.tf", StartLine: 1, EndLine: 3},
+	}
+	errors := []validator.ValidationError{
+		{ResourceType: "aws_vpc", ResourceName: "main", Module: "module.vpc", Message: "@metadata: Missing required field 'team'"},
+	}
+
+	file, fixCount, err := NewCommentFixer(fs, schema).FixSidecar("/vpc/main.tf", resources, errors)
+	if err != nil {
+		t.Fatalf("FixSidecar failed: %v", err)
+	}
+	if fixCount != 1 {
+		t.Errorf("Expected 1 fix, got %d", fixCount)
+	}
+	if file.Path != "/vpc/terranotate.json" {
+		t.Errorf("Expected the existing sidecar file to be fixed, got %s", file.Path)
+	}
+
+	content, err := file.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes failed: %v", err)
+	}
+	if !strings.Contains(string(content), `"owner": "alice",`) || !strings.Contains(string(content), `"team": "CHANGEME"`) {
+		t.Errorf("Expected team to be added next to owner, got:\n%s", content)
+	}
+
+	// The Terraform file is left alone
+	unchanged, _ := afero.ReadFile(fs, "/vpc/main.tf")
+	if string(unchanged) != tfContent {
+		t.Error("Expected the Terraform file to be unchanged")
+	}
+}
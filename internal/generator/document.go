@@ -0,0 +1,406 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/toozej/terranotate/internal/parser"
+	"github.com/toozej/terranotate/internal/validator"
+)
+
+// baseGenerator holds the schema and resource helpers shared by every renderer
+type baseGenerator struct {
+	schema    validator.ValidationSchema
+	validator *validator.SchemaValidator // Set by EnableValidation
+	iface     parser.ModuleInterface     // Set by SetModuleInterface
+}
+
+// EnableValidation validates every documented resource against the schema, adding its
+// status, missing fields and the annotation coverage to the documentation
+func (bg *baseGenerator) EnableValidation() error {
+	sv, err := validator.NewSchemaValidatorFromSchema(nil, bg.schema)
+	if err != nil {
+		return err
+	}
+	bg.validator = sv
+	return nil
+}
+
+// Document is the format-independent model of the generated documentation
+type Document struct {
+	Module         string            `json:"module"`
+	ResourceTypes  []TypeSection     `json:"resource_types"`
+	TotalResources int               `json:"total_resources"`
+	Coverage       *Coverage         `json:"coverage,omitempty"`  // Set when validation is enabled
+	Interface      *InterfaceSection `json:"interface,omitempty"` // Set when the module declares variables, outputs, requirements or module calls
+}
+
+// TypeSection documents every resource of a single type
+type TypeSection struct {
+	Type      string          `json:"type"`
+	Columns   []string        `json:"columns"`                    // docs.columns or required schema fields ("prefix:field"); empty when there are none
+	Optional  []string        `json:"optional_columns,omitempty"` // Optional schema fields, including nested ones
+	Resources []ResourceEntry `json:"resources"`
+	Coverage  *Coverage       `json:"coverage,omitempty"` // Set when validation is enabled
+}
+
+// Coverage summarizes how many documented resources are fully annotated and pass validation
+type Coverage struct {
+	Resources int     `json:"resources"`
+	Annotated int     `json:"annotated"` // Resources with every required prefix
+	Passing   int     `json:"passing"`   // Resources that pass validation
+	Percent   float64 `json:"percent"`   // Annotated resources as a percentage of all resources
+}
+
+// add counts a validated resource
+func (c *Coverage) add(v *EntryValidation) {
+	c.Resources++
+	if v.Annotated {
+		c.Annotated++
+	}
+	if v.Passed {
+		c.Passing++
+	}
+	c.Percent = float64(c.Annotated) * 100 / float64(c.Resources)
+}
+
+// merge adds the counts of another coverage summary
+func (c *Coverage) merge(other Coverage) {
+	c.Resources += other.Resources
+	c.Annotated += other.Annotated
+	c.Passing += other.Passing
+	if c.Resources > 0 {
+		c.Percent = float64(c.Annotated) * 100 / float64(c.Resources)
+	}
+}
+
+// String summarizes the coverage, e.g. "66.7% (2/3 annotated, 1 passing)"
+func (c Coverage) String() string {
+	return fmt.Sprintf("%.1f%% (%d/%d annotated, %d passing)", c.Percent, c.Annotated, c.Resources, c.Passing)
+}
+
+// ComputeCoverage validates resources against the schema and summarizes their coverage
+func ComputeCoverage(schema validator.ValidationSchema, resources []parser.TerraformResource) (Coverage, error) {
+	bg := baseGenerator{schema: schema}
+	if err := bg.EnableValidation(); err != nil {
+		return Coverage{}, err
+	}
+
+	var coverage Coverage
+	for _, resource := range resources {
+		coverage.add(bg.validateEntry(resource))
+	}
+	return coverage, nil
+}
+
+// OptionalValue is an optional schema field set on a resource
+type OptionalValue struct {
+	Resource string
+	Field    string
+	Value    string
+}
+
+// OptionalValues returns every optional field set by the section's resources, in resource order
+func (s TypeSection) OptionalValues() []OptionalValue {
+	var values []OptionalValue
+	for _, entry := range s.Resources {
+		for _, field := range s.Optional {
+			if value, ok := entry.Fields[field]; ok {
+				values = append(values, OptionalValue{Resource: entry.Label(), Field: field, Value: value})
+			}
+		}
+	}
+	return values
+}
+
+// ResourceEntry documents a single resource
+type ResourceEntry struct {
+	Type        string                            `json:"type"`
+	Name        string                            `json:"name"`
+	Address     string                            `json:"address"` // Module-qualified address, e.g. "module.vpc.aws_subnet.private"
+	Line        int                               `json:"line"`
+	Description string                            `json:"description,omitempty"`
+	Fields      map[string]string                 `json:"fields"`               // Required and optional column values keyed by "prefix:field"
+	Annotations map[string]map[string]interface{} `json:"annotations"`          // All parsed fields keyed by prefix
+	Attributes  []AttributeEntry                  `json:"attributes,omitempty"` // Annotated attributes and nested blocks, in source order
+	Validation  *EntryValidation                  `json:"validation,omitempty"` // Set when validation is enabled
+}
+
+// Label identifies the resource within its type section: the name in the root module, or the
+// full address in a child module so same-named resources of different modules stay apart
+func (e ResourceEntry) Label() string {
+	if e.Address == "" || e.Address == e.Type+"."+e.Name {
+		return e.Name
+	}
+	return e.Address
+}
+
+// AttributeEntry documents the annotations on an attribute or nested block of a resource
+type AttributeEntry struct {
+	Path        string                            `json:"path"` // e.g. "instance_type" or "ingress[0]"
+	Block       bool                              `json:"block,omitempty"`
+	Line        int                               `json:"line"`
+	Annotations map[string]map[string]interface{} `json:"annotations"` // Parsed fields keyed by prefix
+}
+
+// AttributeValue is a single annotation field on an attribute or nested block
+type AttributeValue struct {
+	Resource string
+	Path     string
+	Field    string // "prefix:field", with a dotted path for nested fields
+	Value    string
+}
+
+// AttributeValues returns every annotation field on the attributes and nested blocks of the
+// section's resources, in resource and source order
+func (s TypeSection) AttributeValues() []AttributeValue {
+	var values []AttributeValue
+	for _, entry := range s.Resources {
+		for _, attribute := range entry.Attributes {
+			path := attribute.Path
+			if attribute.Block {
+				path += " {}"
+			}
+			prefixes := make([]string, 0, len(attribute.Annotations))
+			for prefix := range attribute.Annotations {
+				prefixes = append(prefixes, prefix)
+			}
+			sort.Strings(prefixes)
+			for _, prefix := range prefixes {
+				for _, field := range flattenAnnotation(attribute.Annotations[prefix], "") {
+					values = append(values, AttributeValue{Resource: entry.Label(), Path: path, Field: prefix + ":" + field.path, Value: field.value})
+				}
+			}
+		}
+	}
+	return values
+}
+
+// annotationField is a leaf of a nested annotation
+type annotationField struct {
+	path  string
+	value string
+}
+
+// flattenAnnotation returns the leaves of nested annotation fields by dotted path, sorted
+func flattenAnnotation(fields map[string]interface{}, parentPath string) []annotationField {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		if parentPath != "" || key != "_content" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var leaves []annotationField
+	for _, key := range keys {
+		path := key
+		if parentPath != "" {
+			path = parentPath + "." + key
+		}
+		if nested, ok := fields[key].(map[string]interface{}); ok {
+			leaves = append(leaves, flattenAnnotation(nested, path)...)
+			continue
+		}
+		leaves = append(leaves, annotationField{path: path, value: fmt.Sprintf("%v", fields[key])})
+	}
+	return leaves
+}
+
+// EntryValidation is the validation status of a documented resource
+type EntryValidation struct {
+	Passed    bool     `json:"passed"`
+	Annotated bool     `json:"annotated"`          // Whether the resource has every required prefix
+	Missing   []string `json:"missing,omitempty"`  // Missing required prefixes and fields
+	Errors    []string `json:"errors,omitempty"`   // Validation error messages
+	Warnings  []string `json:"warnings,omitempty"` // Validation warning messages
+}
+
+// Status returns "pass" or "fail"
+func (v EntryValidation) Status() string {
+	if v.Passed {
+		return "pass"
+	}
+	return "fail"
+}
+
+// MissingOrDash returns the missing prefixes and fields as a comma-separated list, or "-"
+func (v EntryValidation) MissingOrDash() string {
+	if len(v.Missing) == 0 {
+		return "-"
+	}
+	return strings.Join(v.Missing, ", ")
+}
+
+// Value returns the resource's value for a column, or "-" if it is not set
+func (e ResourceEntry) Value(column string) string {
+	if value, ok := e.Fields[column]; ok {
+		return value
+	}
+	return "-"
+}
+
+// DescriptionOrDash returns the resource's description, or "-" if it has none
+func (e ResourceEntry) DescriptionOrDash() string {
+	if e.Description == "" {
+		return "-"
+	}
+	return e.Description
+}
+
+// buildDocument builds the documentation model for the given resources
+func (bg *baseGenerator) buildDocument(moduleName string, resources []parser.TerraformResource) Document {
+	doc := Document{Module: moduleName, TotalResources: len(resources), Interface: bg.buildInterface()}
+	if bg.validator != nil {
+		doc.Coverage = &Coverage{}
+	}
+
+	resourcesByType := bg.groupResourcesByType(resources)
+	for _, resourceType := range bg.getSortedResourceTypes(resourcesByType) {
+		section := bg.buildSection(resourceType, resourcesByType[resourceType])
+		for _, entry := range section.Resources {
+			if entry.Validation != nil {
+				doc.Coverage.add(entry.Validation)
+			}
+		}
+		doc.ResourceTypes = append(doc.ResourceTypes, section)
+	}
+
+	return doc
+}
+
+// buildSection builds the documentation model for the resources of a single type
+func (bg *baseGenerator) buildSection(resourceType string, resources []parser.TerraformResource) TypeSection {
+	section := TypeSection{
+		Type:     resourceType,
+		Columns:  bg.getColumns(resourceType),
+		Optional: bg.getOptionalFields(resourceType),
+	}
+	if bg.validator != nil {
+		section.Coverage = &Coverage{}
+	}
+
+	columns := append(append([]string{}, section.Columns...), section.Optional...)
+	for _, resource := range resources {
+		entry := bg.buildEntry(resource, columns)
+		if entry.Validation != nil {
+			section.Coverage.add(entry.Validation)
+		}
+		section.Resources = append(section.Resources, entry)
+	}
+
+	return section
+}
+
+// buildEntry builds the documentation model for a single resource
+func (bg *baseGenerator) buildEntry(resource parser.TerraformResource, columns []string) ResourceEntry {
+	entry := ResourceEntry{
+		Type:        resource.Type,
+		Name:        resource.Name,
+		Address:     resource.Address(),
+		Line:        resource.StartLine,
+		Fields:      make(map[string]string),
+		Annotations: make(map[string]map[string]interface{}),
+	}
+
+	if desc, ok := bg.lookupDescription(resource); ok {
+		entry.Description = desc
+	}
+
+	for _, column := range columns {
+		if value, ok := bg.lookupFieldValue(resource, column); ok {
+			entry.Fields[column] = value
+		}
+	}
+
+	if bg.validator != nil {
+		entry.Validation = bg.validateEntry(resource)
+	}
+
+	for _, comment := range allComments(resource) {
+		if _, exists := entry.Annotations[comment.Prefix]; !exists {
+			annotation, _ := resource.GetAnnotation(comment.Prefix)
+			entry.Annotations[comment.Prefix] = annotation.Fields
+		}
+	}
+
+	for path, comments := range resource.AttributeComments {
+		if len(comments) > 0 {
+			entry.Attributes = append(entry.Attributes, AttributeEntry{Path: path, Line: comments[0].Line, Annotations: annotationsByPrefix(comments)})
+		}
+	}
+	for _, block := range resource.Blocks {
+		if len(block.Comments) > 0 {
+			entry.Attributes = append(entry.Attributes, AttributeEntry{Path: block.Path, Block: true, Line: block.StartLine, Annotations: annotationsByPrefix(block.Comments)})
+		}
+	}
+	sort.Slice(entry.Attributes, func(i, j int) bool {
+		if entry.Attributes[i].Line != entry.Attributes[j].Line {
+			return entry.Attributes[i].Line < entry.Attributes[j].Line
+		}
+		return entry.Attributes[i].Path < entry.Attributes[j].Path
+	})
+
+	return entry
+}
+
+// annotationsByPrefix collects the fields of comments by prefix. Earlier comments win when a
+// prefix appears more than once.
+func annotationsByPrefix(comments []parser.StructuredComment) map[string]map[string]interface{} {
+	annotations := make(map[string]map[string]interface{})
+	for _, comment := range comments {
+		fields, exists := annotations[comment.Prefix]
+		if !exists {
+			fields = make(map[string]interface{})
+			annotations[comment.Prefix] = fields
+		}
+		for key, value := range comment.Fields {
+			if _, set := fields[key]; !set {
+				fields[key] = value
+			}
+		}
+	}
+	return annotations
+}
+
+// validateEntry validates a single resource. EnableValidation must have been called.
+func (bg *baseGenerator) validateEntry(resource parser.TerraformResource) *EntryValidation {
+	result := bg.validator.ValidateResources([]parser.TerraformResource{resource})
+	v := &EntryValidation{Passed: result.Passed, Annotated: true}
+	for _, e := range result.Errors {
+		v.Errors = append(v.Errors, e.Message)
+		switch e.Rule {
+		case validator.RuleMissingPrefix:
+			v.Annotated = false
+			v.Missing = append(v.Missing, e.Field)
+		case validator.RuleMissingField, validator.RuleMissingNested:
+			v.Missing = append(v.Missing, e.Field)
+		}
+	}
+	for _, w := range result.Warnings {
+		v.Warnings = append(v.Warnings, w.Message)
+	}
+	return v
+}
+
+// allColumns returns every required then optional column used by the document, in order of
+// first appearance
+func (doc Document) allColumns() []string {
+	var columns []string
+	for _, section := range doc.ResourceTypes {
+		for _, column := range section.Columns {
+			if !contains(columns, column) {
+				columns = append(columns, column)
+			}
+		}
+	}
+	for _, section := range doc.ResourceTypes {
+		for _, column := range section.Optional {
+			if !contains(columns, column) {
+				columns = append(columns, column)
+			}
+		}
+	}
+	return columns
+}
@@ -0,0 +1,177 @@
+package generator
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/toozej/terranotate/internal/parser"
+	"github.com/toozej/terranotate/internal/validator"
+)
+
+func validationTestData() (validator.ValidationSchema, []parser.TerraformResource) {
+	schema := validator.ValidationSchema{
+		Global: validator.GlobalRules{
+			RequiredPrefixes: []string{"@metadata"},
+			PrefixRules: map[string]validator.PrefixRule{
+				"@metadata": {
+					RequiredFields: []string{"owner"},
+					NestedFields: map[string]validator.NestedRule{
+						"contact": {RequiredFields: []string{"email"}},
+					},
+				},
+			},
+		},
+	}
+
+	resources := []parser.TerraformResource{
+		{Type: "aws_vpc", Name: "main", PrecedingComments: []parser.StructuredComment{
+			{Prefix: "@metadata", Fields: map[string]interface{}{"owner": "net", "contact": map[string]interface{}{"email": "net@example.com"}}},
+		}},
+		{Type: "aws_vpc", Name: "legacy", PrecedingComments: []parser.StructuredComment{
+			{Prefix: "@metadata", Fields: map[string]interface{}{"contact": map[string]interface{}{}}},
+		}},
+		{Type: "aws_s3_bucket", Name: "logs"},
+	}
+
+	return schema, resources
+}
+
+func TestBuildDocumentWithValidation(t *testing.T) {
+	schema, resources := validationTestData()
+	gen := NewMarkdownGenerator(schema)
+
+	if doc := gen.buildDocument("net", resources); doc.Coverage != nil || doc.ResourceTypes[0].Resources[0].Validation != nil {
+		t.Fatal("Validation should be off by default")
+	}
+
+	if err := gen.EnableValidation(); err != nil {
+		t.Fatalf("EnableValidation() failed: %v", err)
+	}
+	doc := gen.buildDocument("net", resources)
+
+	if want := (Coverage{Resources: 3, Annotated: 2, Passing: 1, Percent: 200.0 / 3}); *doc.Coverage != want {
+		t.Errorf("Document coverage = %+v, want %+v", *doc.Coverage, want)
+	}
+
+	// Sections are sorted by type: aws_s3_bucket, then aws_vpc
+	bucket, vpc := doc.ResourceTypes[0], doc.ResourceTypes[1]
+	if bucket.Coverage.Percent != 0 || vpc.Coverage.Percent != 100 {
+		t.Errorf("Unexpected section coverage: %+v, %+v", *bucket.Coverage, *vpc.Coverage)
+	}
+	if got := bucket.Resources[0].Validation.Missing; !reflect.DeepEqual(got, []string{"@metadata"}) {
+		t.Errorf("Missing for aws_s3_bucket.logs = %v", got)
+	}
+	legacy := vpc.Resources[1].Validation
+	if legacy.Passed || !legacy.Annotated || !reflect.DeepEqual(legacy.Missing, []string{"@metadata:owner", "@metadata:contact.email"}) {
+		t.Errorf("Unexpected validation for aws_vpc.legacy: %+v", legacy)
+	}
+	if main := vpc.Resources[0].Validation; !main.Passed || main.Status() != "pass" || main.MissingOrDash() != "-" {
+		t.Errorf("Unexpected validation for aws_vpc.main: %+v", main)
+	}
+}
+
+func TestRenderersWithValidation(t *testing.T) {
+	schema, resources := validationTestData()
+
+	expected := map[string][]string{
+		FormatMarkdown: {
+			"| Resource | @metadata:owner | @metadata:contact.email | Status | Missing |",
+			"| `legacy` | - | - | ❌ fail | @metadata:owner, @metadata:contact.email |",
+			"**Coverage:** 100.0% (2/2 annotated, 1 passing)",
+			"**Annotation Coverage:** 66.7% (2/3 annotated, 1 passing)",
+		},
+		FormatHTML:     {"<th>Status</th><th>Missing</th>", `<td class="fail">fail</td><td>@metadata</td>`, "<strong>Annotation Coverage:</strong> 66.7%"},
+		FormatCSV:      {",status,missing\n", "net,aws_vpc,legacy,aws_vpc.legacy,,,,fail,@metadata:owner;@metadata:contact.email\n"},
+		FormatAsciiDoc: {"|Status |Missing ", "|fail\n|@metadata\n", "*Annotation Coverage:* 66.7%"},
+	}
+	for format, wants := range expected {
+		renderer, err := NewRenderer(format, schema)
+		if err != nil {
+			t.Fatalf("NewRenderer(%s) failed: %v", format, err)
+		}
+		if err := renderer.EnableValidation(); err != nil {
+			t.Fatalf("EnableValidation() failed: %v", err)
+		}
+		out := renderer.GenerateDocumentation("net", resources)
+		for _, want := range wants {
+			if !strings.Contains(out, want) {
+				t.Errorf("%s output should contain %q, got:\n%s", format, want, out)
+			}
+		}
+	}
+
+	renderer := NewJSONGenerator(schema)
+	if err := renderer.EnableValidation(); err != nil {
+		t.Fatalf("EnableValidation() failed: %v", err)
+	}
+	var doc Document
+	if err := json.Unmarshal([]byte(renderer.GenerateDocumentation("net", resources)), &doc); err != nil {
+		t.Fatalf("JSON output is invalid: %v", err)
+	}
+	if doc.Coverage == nil || doc.Coverage.Annotated != 2 || doc.ResourceTypes[1].Resources[1].Validation.Passed {
+		t.Errorf("JSON should include validation and coverage: %+v", doc)
+	}
+}
+
+func TestComputeCoverage(t *testing.T) {
+	schema, resources := validationTestData()
+
+	coverage, err := ComputeCoverage(schema, resources)
+	if err != nil {
+		t.Fatalf("ComputeCoverage() failed: %v", err)
+	}
+	if coverage.Resources != 3 || coverage.Annotated != 2 || coverage.Passing != 1 {
+		t.Errorf("Unexpected coverage: %+v", coverage)
+	}
+	if got := coverage.String(); got != "66.7% (2/3 annotated, 1 passing)" {
+		t.Errorf("Coverage.String() = %q", got)
+	}
+}
+
+func TestRenderersWithAttributeAnnotations(t *testing.T) {
+	resources := []parser.TerraformResource{
+		{
+			Type: "aws_security_group",
+			Name: "web",
+			AttributeComments: map[string][]parser.StructuredComment{
+				"instance_type": {{Prefix: "@docs", Line: 4, Fields: map[string]interface{}{"reason": "bursty batch jobs"}}},
+			},
+			Blocks: []parser.NestedBlock{
+				{Type: "ingress", Path: "ingress[0]", StartLine: 2, Comments: []parser.StructuredComment{
+					{Prefix: "@validation", Line: 1, Fields: map[string]interface{}{"justification": map[string]interface{}{"ticket": "SEC-1"}}},
+				}},
+				{Type: "ingress", Path: "ingress[1]", StartLine: 6},
+			},
+		},
+	}
+
+	doc := NewMarkdownGenerator(validator.ValidationSchema{}).buildDocument("net", resources)
+	entry := doc.ResourceTypes[0].Resources[0]
+	if len(entry.Attributes) != 2 || entry.Attributes[0].Path != "ingress[0]" || !entry.Attributes[0].Block || entry.Attributes[1].Path != "instance_type" {
+		t.Fatalf("Unexpected attribute entries: %+v", entry.Attributes)
+	}
+
+	tests := map[string][]string{
+		FormatMarkdown: {
+			"| `web` | `ingress[0] {}` | @validation:justification.ticket | SEC-1 |",
+			"| `web` | `instance_type` | @docs:reason | bursty batch jobs |",
+		},
+		FormatAsciiDoc: {"|`instance_type`\n|@docs:reason\n|bursty batch jobs"},
+		FormatHTML:     {"<td><code>ingress[0] {}</code></td><td>@validation:justification.ticket</td><td>SEC-1</td>"},
+		FormatJSON:     {`"path": "instance_type"`},
+	}
+	for format, wants := range tests {
+		renderer, err := NewRenderer(format, validator.ValidationSchema{})
+		if err != nil {
+			t.Fatalf("NewRenderer(%s) failed: %v", format, err)
+		}
+		out := renderer.GenerateDocumentation("net", resources)
+		for _, want := range wants {
+			if !strings.Contains(out, want) {
+				t.Errorf("%s output should contain %q, got:\n%s", format, want, out)
+			}
+		}
+	}
+}
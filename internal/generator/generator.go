@@ -11,13 +11,13 @@ import (
 
 // MarkdownGenerator generates markdown documentation from resources
 type MarkdownGenerator struct {
-	schema validator.ValidationSchema
+	baseGenerator
 }
 
 // NewMarkdownGenerator creates a new markdown generator
 func NewMarkdownGenerator(schema validator.ValidationSchema) *MarkdownGenerator {
 	return &MarkdownGenerator{
-		schema: schema,
+		baseGenerator: baseGenerator{schema: schema},
 	}
 }
 
@@ -29,26 +29,31 @@ func (mg *MarkdownGenerator) GenerateDocumentation(moduleName string, resources
 	fmt.Fprintf(&sb, "# %s - Resource Documentation\n\n", moduleName)
 	sb.WriteString("This document provides an overview of all Terraform resources with their metadata annotations.\n\n")
 
-	// Group resources by type
-	resourcesByType := mg.groupResourcesByType(resources)
+	doc := mg.buildDocument(moduleName, resources)
+	head, tail := generateInterfaceSections(doc.Interface)
+	sb.WriteString(head)
 
 	// Generate a table for each resource type
-	for _, resourceType := range mg.getSortedResourceTypes(resourcesByType) {
-		typeResources := resourcesByType[resourceType]
-		sb.WriteString(mg.generateTableForType(resourceType, typeResources))
+	for _, section := range doc.ResourceTypes {
+		sb.WriteString(mg.generateSectionTable(section))
 		sb.WriteString("\n")
 	}
 
+	sb.WriteString(tail)
+
 	// Summary
 	sb.WriteString("---\n\n")
-	fmt.Fprintf(&sb, "**Total Resources:** %d\n\n", len(resources))
-	fmt.Fprintf(&sb, "**Resource Types:** %d\n", len(resourcesByType))
+	fmt.Fprintf(&sb, "**Total Resources:** %d\n\n", doc.TotalResources)
+	fmt.Fprintf(&sb, "**Resource Types:** %d\n", len(doc.ResourceTypes))
+	if doc.Coverage != nil {
+		fmt.Fprintf(&sb, "\n**Annotation Coverage:** %s\n", doc.Coverage)
+	}
 
 	return sb.String()
 }
 
 // groupResourcesByType groups resources by their type
-func (mg *MarkdownGenerator) groupResourcesByType(resources []parser.TerraformResource) map[string][]parser.TerraformResource {
+func (bg *baseGenerator) groupResourcesByType(resources []parser.TerraformResource) map[string][]parser.TerraformResource {
 	grouped := make(map[string][]parser.TerraformResource)
 	for _, resource := range resources {
 		grouped[resource.Type] = append(grouped[resource.Type], resource)
@@ -57,7 +62,7 @@ func (mg *MarkdownGenerator) groupResourcesByType(resources []parser.TerraformRe
 }
 
 // getSortedResourceTypes returns sorted list of resource types
-func (mg *MarkdownGenerator) getSortedResourceTypes(resourcesByType map[string][]parser.TerraformResource) []string {
+func (bg *baseGenerator) getSortedResourceTypes(resourcesByType map[string][]parser.TerraformResource) []string {
 	var types []string
 	for resourceType := range resourcesByType {
 		types = append(types, resourceType)
@@ -66,48 +71,65 @@ func (mg *MarkdownGenerator) getSortedResourceTypes(resourcesByType map[string][
 	return types
 }
 
-// generateTableForType generates a markdown table for resources of a specific type
-func (mg *MarkdownGenerator) generateTableForType(resourceType string, resources []parser.TerraformResource) string {
+// generateSectionTable generates the markdown table for a resource type section
+func (mg *MarkdownGenerator) generateSectionTable(section TypeSection) string {
 	var sb strings.Builder
 
-	fmt.Fprintf(&sb, "## %s\n\n", resourceType)
+	fmt.Fprintf(&sb, "## %s\n\n", section.Type)
 
-	// Get the required fields from schema
-	fields := mg.getRequiredFields(resourceType)
+	// Schema fields as columns, or the description when there are none
+	headers := []string{"Resource Name", "Description"}
+	if len(section.Columns) > 0 {
+		headers = []string{"Resource"}
+		for _, field := range section.Columns {
+			headers = append(headers, MarkdownEscape(field))
+		}
+	}
+	if section.Coverage != nil {
+		headers = append(headers, "Status", "Missing")
+	}
 
-	if len(fields) == 0 {
-		// No schema fields defined, create simple table
-		sb.WriteString("| Resource Name | Description |\n")
-		sb.WriteString("|--------------|-------------|\n")
+	sb.WriteString("| " + strings.Join(headers, " | ") + " |\n")
+	sb.WriteString("|" + strings.Repeat("--------|", len(headers)) + "\n")
 
-		for _, resource := range resources {
-			desc := mg.extractDescription(resource)
-			fmt.Fprintf(&sb, "| `%s` | %s |\n", resource.Name, desc)
+	// Rows
+	for _, entry := range section.Resources {
+		cells := []string{fmt.Sprintf("`%s`", entry.Label())}
+		if len(section.Columns) == 0 {
+			cells = append(cells, MarkdownEscape(entry.DescriptionOrDash()))
 		}
-	} else {
-		// Create table with schema fields as columns
-		// Header
-		sb.WriteString("| Resource | ")
-		for _, field := range fields {
-			fmt.Fprintf(&sb, "%s | ", field)
+		for _, field := range section.Columns {
+			cells = append(cells, MarkdownEscape(entry.Value(field)))
 		}
-		sb.WriteString("\n")
+		if entry.Validation != nil {
+			status := "✅ pass"
+			if !entry.Validation.Passed {
+				status = "❌ fail"
+			}
+			cells = append(cells, status, MarkdownEscape(entry.Validation.MissingOrDash()))
+		}
+		sb.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
 
-		// Separator
-		sb.WriteString("|----------|")
-		for range fields {
-			sb.WriteString("--------|")
+	if section.Coverage != nil {
+		fmt.Fprintf(&sb, "\n**Coverage:** %s\n", section.Coverage)
+	}
+
+	if optional := section.OptionalValues(); len(optional) > 0 {
+		sb.WriteString("\n**Optional fields**\n\n")
+		sb.WriteString("| Resource | Field | Value |\n")
+		sb.WriteString("|----------|-------|-------|\n")
+		for _, value := range optional {
+			fmt.Fprintf(&sb, "| `%s` | %s | %s |\n", value.Resource, MarkdownEscape(value.Field), MarkdownEscape(value.Value))
 		}
-		sb.WriteString("\n")
+	}
 
-		// Rows
-		for _, resource := range resources {
-			fmt.Fprintf(&sb, "| `%s` |", resource.Name)
-			for _, field := range fields {
-				value := mg.extractFieldValue(resource, field)
-				fmt.Fprintf(&sb, " %s |", value)
-			}
-			sb.WriteString("\n")
+	if attributes := section.AttributeValues(); len(attributes) > 0 {
+		sb.WriteString("\n**Attribute annotations**\n\n")
+		sb.WriteString("| Resource | Attribute | Field | Value |\n")
+		sb.WriteString("|----------|-----------|-------|-------|\n")
+		for _, value := range attributes {
+			fmt.Fprintf(&sb, "| `%s` | `%s` | %s | %s |\n", value.Resource, value.Path, MarkdownEscape(value.Field), MarkdownEscape(value.Value))
 		}
 	}
 
@@ -115,36 +137,90 @@ func (mg *MarkdownGenerator) generateTableForType(resourceType string, resources
 	return sb.String()
 }
 
-// getRequiredFields gets the list of required fields for a resource type from schema
-func (mg *MarkdownGenerator) getRequiredFields(resourceType string) []string {
+// getColumns gets the fields shown as table columns for a resource type: the resource
+// type's docs.columns, else the schema's docs.columns, else the required fields
+func (bg *baseGenerator) getColumns(resourceType string) []string {
+	if rules, exists := bg.schema.ResourceTypes[resourceType]; exists && len(rules.Docs.Columns) > 0 {
+		return rules.Docs.Columns
+	}
+	if len(bg.schema.Docs.Columns) > 0 {
+		return bg.schema.Docs.Columns
+	}
+	return bg.getRequiredFields(resourceType)
+}
+
+// getRequiredFields gets the list of required fields for a resource type from schema, in
+// schema declaration order. Required nested fields are returned as dotted paths, e.g.
+// "@metadata:contact.email".
+func (bg *baseGenerator) getRequiredFields(resourceType string) []string {
+	return bg.collectFields(resourceType, nil, func(rule validator.PrefixRule) []string {
+		return append(append([]string{}, rule.RequiredFields...), nestedFieldPaths(rule, true)...)
+	})
+}
+
+// getOptionalFields gets the optional fields for a resource type from schema, including
+// optional nested fields as dotted paths. Fields shown as columns are skipped.
+func (bg *baseGenerator) getOptionalFields(resourceType string) []string {
+	return bg.collectFields(resourceType, bg.getColumns(resourceType), func(rule validator.PrefixRule) []string {
+		return append(append([]string{}, rule.OptionalFields...), nestedFieldPaths(rule, false)...)
+	})
+}
+
+// collectFields collects "prefix:field" names from the resource type's prefix rules and then
+// the global prefix rules, in schema declaration order, skipping duplicates and excluded fields
+func (bg *baseGenerator) collectFields(resourceType string, exclude []string, fieldsOf func(validator.PrefixRule) []string) []string {
 	var fields []string
 
-	// Check if there's a specific rule for this resource type
-	if rules, exists := mg.schema.ResourceTypes[resourceType]; exists {
-		for prefix, prefixRule := range rules.PrefixRules {
-			// Add required fields with prefix
-			for _, field := range prefixRule.RequiredFields {
-				fields = append(fields, fmt.Sprintf("%s:%s", prefix, field))
+	add := func(prefix string, rule validator.PrefixRule) {
+		for _, field := range fieldsOf(rule) {
+			fieldName := fmt.Sprintf("%s:%s", prefix, field)
+			if !contains(exclude, fieldName) && !contains(fields, fieldName) {
+				fields = append(fields, fieldName)
 			}
 		}
 	}
 
-	// Also check global rules
-	for prefix, prefixRule := range mg.schema.Global.PrefixRules {
-		for _, field := range prefixRule.RequiredFields {
-			fieldName := fmt.Sprintf("%s:%s", prefix, field)
-			// Only add if not already present
-			if !contains(fields, fieldName) {
-				fields = append(fields, fieldName)
-			}
+	// Check if there's a specific rule for this resource type
+	if rules, exists := bg.schema.ResourceTypes[resourceType]; exists {
+		for _, prefix := range rules.OrderedPrefixes() {
+			add(prefix, rules.PrefixRules[prefix])
 		}
 	}
 
+	// Also check global rules
+	for _, prefix := range bg.schema.Global.OrderedPrefixes() {
+		add(prefix, bg.schema.Global.PrefixRules[prefix])
+	}
+
 	return fields
 }
 
-// extractFieldValue extracts a field value from a resource's comments
-func (mg *MarkdownGenerator) extractFieldValue(resource parser.TerraformResource, fieldName string) string {
+// nestedFieldPaths returns the dotted paths of a prefix rule's required or optional nested fields
+func nestedFieldPaths(rule validator.PrefixRule, required bool) []string {
+	var paths []string
+	for _, nestedPath := range rule.OrderedNestedPaths() {
+		nestedRule := rule.NestedFields[nestedPath]
+		fields := nestedRule.OptionalFields
+		if required {
+			fields = nestedRule.RequiredFields
+		}
+		for _, field := range fields {
+			paths = append(paths, nestedPath+"."+field)
+		}
+	}
+	return paths
+}
+
+// extractFieldValue extracts a field value from a resource's comments, or "-" if it is not set
+func (bg *baseGenerator) extractFieldValue(resource parser.TerraformResource, fieldName string) string {
+	if value, ok := bg.lookupFieldValue(resource, fieldName); ok {
+		return value
+	}
+	return "-"
+}
+
+// lookupFieldValue finds a field value in a resource's comments
+func (bg *baseGenerator) lookupFieldValue(resource parser.TerraformResource, fieldName string) (string, bool) {
 	// Parse field name (format: "prefix:field" or "field")
 	parts := strings.SplitN(fieldName, ":", 2)
 	var prefix, field string
@@ -156,34 +232,69 @@ func (mg *MarkdownGenerator) extractFieldValue(resource parser.TerraformResource
 		field = fieldName
 	}
 
-	// Search through resource comments
-	for _, comment := range resource.PrecedingComments {
+	// Search through resource comments, including annotations inside the block
+	for _, comment := range allComments(resource) {
 		// Check if this comment matches the prefix
 		if prefix != "" && comment.Prefix != prefix {
 			continue
 		}
 
-		// Extract field value from the comment's Fields map
-		if value, exists := comment.Fields[field]; exists {
-			return fmt.Sprintf("%v", value)
+		// Extract field value from the comment's Fields map, following dotted paths
+		if value, exists := lookupPath(comment.Fields, field); exists {
+			return fmt.Sprintf("%v", value), true
+		}
+	}
+
+	return "", false
+}
+
+// lookupPath finds the value at a dotted path in nested annotation fields
+func lookupPath(fields map[string]interface{}, path string) (interface{}, bool) {
+	parts := strings.Split(path, ".")
+	current := fields
+	for i, part := range parts {
+		value, exists := current[part]
+		if !exists {
+			return nil, false
 		}
+		if i == len(parts)-1 {
+			return value, true
+		}
+		nested, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current = nested
 	}
+	return nil, false
+}
 
+// allComments returns a resource's preceding, inline and sidecar annotations followed by the
+// annotations it inherits, so that values set on the resource are found first
+func allComments(resource parser.TerraformResource) []parser.StructuredComment {
+	return append(resource.Comments(), resource.Inherited...)
+}
+
+// extractDescription extracts description from resource comments, or "-" if there is none
+func (bg *baseGenerator) extractDescription(resource parser.TerraformResource) string {
+	if desc, ok := bg.lookupDescription(resource); ok {
+		return desc
+	}
 	return "-"
 }
 
-// extractDescription extracts description from resource comments
-func (mg *MarkdownGenerator) extractDescription(resource parser.TerraformResource) string {
+// lookupDescription finds a description in the @docs or @metadata comments
+func (bg *baseGenerator) lookupDescription(resource parser.TerraformResource) (string, bool) {
 	// Try to find description in different comment prefixes
-	for _, comment := range resource.PrecedingComments {
+	for _, comment := range allComments(resource) {
 		if comment.Prefix == "@docs" || comment.Prefix == "@metadata" {
 			if desc, exists := comment.Fields["description"]; exists {
-				return fmt.Sprintf("%v", desc)
+				return fmt.Sprintf("%v", desc), true
 			}
 		}
 	}
 
-	return "-"
+	return "", false
 }
 
 // contains checks if a string slice contains a specific string
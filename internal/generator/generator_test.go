@@ -1,11 +1,13 @@
 package generator
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 
 	"github.com/toozej/terranotate/internal/parser"
 	"github.com/toozej/terranotate/internal/validator"
+	"gopkg.in/yaml.v3"
 )
 
 func TestNewMarkdownGenerator(t *testing.T) {
@@ -270,7 +272,7 @@ func TestGenerateTableForType(t *testing.T) {
 		},
 	}
 
-	table := gen.generateTableForType("aws_vpc", resources)
+	table := gen.generateSectionTable(gen.buildSection("aws_vpc", resources))
 
 	// Should contain resource type header
 	if !strings.Contains(table, "## aws_vpc") {
@@ -288,6 +290,165 @@ func TestGenerateTableForType(t *testing.T) {
 	}
 }
 
+func TestNestedAndInlineFields(t *testing.T) {
+	schema := validator.ValidationSchema{
+		Global: validator.GlobalRules{
+			PrefixRules: map[string]validator.PrefixRule{
+				"@metadata": {
+					RequiredFields: []string{"owner"},
+					NestedFields: map[string]validator.NestedRule{
+						"contact": {RequiredFields: []string{"email"}, OptionalFields: []string{"slack"}},
+					},
+				},
+				"@config": {
+					OptionalFields: []string{"autoscaling"},
+					NestedFields: map[string]validator.NestedRule{
+						"monitoring": {OptionalFields: []string{"interval"}},
+					},
+				},
+			},
+		},
+	}
+	gen := NewMarkdownGenerator(schema)
+
+	required := gen.getRequiredFields("aws_instance")
+	if len(required) != 2 || !contains(required, "@metadata:owner") || !contains(required, "@metadata:contact.email") {
+		t.Errorf("Unexpected required fields: %v", required)
+	}
+	optional := gen.getOptionalFields("aws_instance")
+	for _, field := range []string{"@metadata:contact.slack", "@config:autoscaling", "@config:monitoring.interval"} {
+		if !contains(optional, field) {
+			t.Errorf("Optional fields %v should contain %s", optional, field)
+		}
+	}
+
+	resource := parser.TerraformResource{
+		Type: "aws_instance",
+		Name: "web",
+		PrecedingComments: []parser.StructuredComment{
+			{Prefix: "@metadata", Fields: map[string]interface{}{
+				"owner":   "alice",
+				"contact": map[string]interface{}{"email": "alice@example.com"},
+			}},
+		},
+		InlineComments: []parser.StructuredComment{
+			{Prefix: "@config", Fields: map[string]interface{}{
+				"autoscaling": true,
+				"monitoring":  map[string]interface{}{"interval": 60},
+			}},
+			{Prefix: "@docs", Fields: map[string]interface{}{"description": "Web tier"}},
+		},
+	}
+
+	tests := map[string]string{
+		"@metadata:contact.email":     "alice@example.com",
+		"@metadata:contact.slack":     "-",
+		"@metadata:owner.email":       "-",
+		"@config:monitoring.interval": "60",
+		"autoscaling":                 "true",
+	}
+	for field, want := range tests {
+		if got := gen.extractFieldValue(resource, field); got != want {
+			t.Errorf("extractFieldValue(%q) = %q, want %q", field, got, want)
+		}
+	}
+	if desc := gen.extractDescription(resource); desc != "Web tier" {
+		t.Errorf("Expected description from inline comment, got %q", desc)
+	}
+
+	table := gen.generateSectionTable(gen.buildSection("aws_instance", []parser.TerraformResource{resource}))
+	for _, want := range []string{
+		"| `web` | alice | alice@example.com |",
+		"**Optional fields**",
+		"| `web` | @config:monitoring.interval | 60 |",
+		"| `web` | @config:autoscaling | true |",
+	} {
+		if !strings.Contains(table, want) {
+			t.Errorf("Table should contain %q, got:\n%s", want, table)
+		}
+	}
+	if strings.Contains(table, "@metadata:contact.slack |") {
+		t.Errorf("Unset optional fields should not be listed:\n%s", table)
+	}
+}
+
+func TestColumnOrderAndEscaping(t *testing.T) {
+	var schema validator.ValidationSchema
+	data := `
+global:
+  prefix_rules:
+    "@metadata":
+      required_fields: [team, owner]
+      nested_fields:
+        sla: {required_fields: [uptime]}
+        contact: {required_fields: [email]}
+    "@docs":
+      required_fields: [description]
+    "@config":
+      required_fields: [tier]
+`
+	if err := yaml.Unmarshal([]byte(data), &schema); err != nil {
+		t.Fatalf("failed to unmarshal schema: %v", err)
+	}
+	gen := NewMarkdownGenerator(schema)
+
+	want := []string{"@metadata:team", "@metadata:owner", "@metadata:sla.uptime", "@metadata:contact.email", "@docs:description", "@config:tier"}
+	for i := 0; i < 20; i++ {
+		if got := gen.getColumns("aws_vpc"); !reflect.DeepEqual(got, want) {
+			t.Fatalf("getColumns() = %v, want %v", got, want)
+		}
+	}
+
+	resource := parser.TerraformResource{
+		Type: "aws_vpc",
+		Name: "main",
+		PrecedingComments: []parser.StructuredComment{
+			{Prefix: "@metadata", Fields: map[string]interface{}{"team": "net|ops", "owner": "a\nb"}},
+		},
+	}
+	table := gen.generateSectionTable(gen.buildSection("aws_vpc", []parser.TerraformResource{resource}))
+	if !strings.Contains(table, "| `main` | net\\|ops | a b |") {
+		t.Errorf("Values should be escaped, got:\n%s", table)
+	}
+}
+
+func TestDocsColumns(t *testing.T) {
+	schema := validator.ValidationSchema{
+		Docs: validator.DocsConfig{Columns: []string{"@metadata:priority", "@metadata:owner"}},
+		Global: validator.GlobalRules{
+			PrefixRules: map[string]validator.PrefixRule{
+				"@metadata": {RequiredFields: []string{"owner"}, OptionalFields: []string{"priority", "tags"}},
+			},
+		},
+		ResourceTypes: map[string]validator.ResourceRules{
+			"aws_vpc": {Docs: validator.DocsConfig{Columns: []string{"@metadata:tags"}}},
+		},
+	}
+	gen := NewMarkdownGenerator(schema)
+
+	if got := gen.getColumns("aws_instance"); !reflect.DeepEqual(got, []string{"@metadata:priority", "@metadata:owner"}) {
+		t.Errorf("getColumns(aws_instance) = %v", got)
+	}
+	if got := gen.getColumns("aws_vpc"); !reflect.DeepEqual(got, []string{"@metadata:tags"}) {
+		t.Errorf("getColumns(aws_vpc) = %v", got)
+	}
+	if got := gen.getOptionalFields("aws_instance"); !reflect.DeepEqual(got, []string{"@metadata:tags"}) {
+		t.Errorf("Columns should not be repeated as optional fields: %v", got)
+	}
+
+	resource := parser.TerraformResource{
+		Type: "aws_instance",
+		Name: "web",
+		PrecedingComments: []parser.StructuredComment{
+			{Prefix: "@metadata", Fields: map[string]interface{}{"owner": "alice", "priority": "high"}},
+		},
+	}
+	table := gen.generateSectionTable(gen.buildSection("aws_instance", []parser.TerraformResource{resource}))
+	if !strings.Contains(table, "| Resource | @metadata:priority | @metadata:owner |\n") || !strings.Contains(table, "| `web` | high | alice |") {
+		t.Errorf("Table should use docs.columns, got:\n%s", table)
+	}
+}
+
 func TestContains(t *testing.T) {
 	tests := []struct {
 		slice    []string
@@ -0,0 +1,139 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+
+	"github.com/toozej/terranotate/internal/parser"
+	"github.com/toozej/terranotate/internal/validator"
+)
+
+// htmlTemplate renders a self-contained page: tables sort when a header is clicked and
+// the filter box hides rows that don't contain the filter text
+var htmlTemplate = template.Must(template.New("html").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Module}} - Resource Documentation</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #24292f; }
+table { border-collapse: collapse; margin-bottom: 2em; width: 100%; }
+th, td { border: 1px solid #d0d7de; padding: 6px 12px; text-align: left; }
+th { background: #f6f8fa; cursor: pointer; user-select: none; }
+th.asc::after { content: " \25B2"; }
+th.desc::after { content: " \25BC"; }
+tr:nth-child(even) td { background: #fbfcfd; }
+#filter { padding: 6px; width: 20em; margin-bottom: 1em; }
+code { font-family: SFMono-Regular, Consolas, monospace; }
+td.pass { color: #1a7f37; }
+td.fail { color: #cf222e; }
+</style>
+</head>
+<body>
+<h1>{{.Module}} - Resource Documentation</h1>
+<p>This document provides an overview of all Terraform resources with their metadata annotations.</p>
+<input id="filter" type="search" placeholder="Filter resources..." aria-label="Filter resources">
+{{with .Interface}}{{if or .RequiredVersion .Providers}}<h2>Requirements</h2>
+<table class="resources">
+<thead><tr><th>Name</th><th>Source</th><th>Version</th></tr></thead>
+<tbody>
+{{with .RequiredVersion}}<tr><td>terraform</td><td>-</td><td><code>{{.}}</code></td></tr>
+{{end}}{{range .Providers}}<tr><td>{{.Name}}</td><td>{{.SourceOrDash}}</td><td><code>{{.VersionOrDash}}</code></td></tr>
+{{end}}</tbody>
+</table>
+{{end}}{{with .Modules}}<h2>Modules</h2>
+<table class="resources">
+<thead><tr><th>Name</th><th>Source</th><th>Version</th><th>Description</th></tr></thead>
+<tbody>
+{{range .}}<tr><td><code>{{.Name}}</code></td><td><code>{{.SourceOrDash}}</code></td><td><code>{{.VersionOrDash}}</code></td><td>{{.DescriptionOrDash}}</td></tr>
+{{end}}</tbody>
+</table>
+{{end}}{{end}}{{range .ResourceTypes}}{{$section := .}}
+<h2>{{.Type}}</h2>
+<table class="resources">
+<thead><tr>{{if .Columns}}<th>Resource</th>{{range .Columns}}<th>{{.}}</th>{{end}}{{else}}<th>Resource Name</th><th>Description</th>{{end}}{{if .Coverage}}<th>Status</th><th>Missing</th>{{end}}</tr></thead>
+<tbody>
+{{range .Resources}}{{$entry := .}}<tr><td><code>{{.Label}}</code></td>{{if $section.Columns}}{{range $section.Columns}}<td>{{$entry.Value .}}</td>{{end}}{{else}}<td>{{.DescriptionOrDash}}</td>{{end}}{{with .Validation}}<td class="{{.Status}}">{{.Status}}</td><td>{{.MissingOrDash}}</td>{{end}}</tr>
+{{end}}</tbody>
+</table>
+{{with .Coverage}}<p><strong>Coverage:</strong> {{.}}</p>
+{{end}}{{with .OptionalValues}}<h3>Optional fields</h3>
+<table class="resources">
+<thead><tr><th>Resource</th><th>Field</th><th>Value</th></tr></thead>
+<tbody>
+{{range .}}<tr><td><code>{{.Resource}}</code></td><td>{{.Field}}</td><td>{{.Value}}</td></tr>
+{{end}}</tbody>
+</table>
+{{end}}{{with .AttributeValues}}<h3>Attribute annotations</h3>
+<table class="resources">
+<thead><tr><th>Resource</th><th>Attribute</th><th>Field</th><th>Value</th></tr></thead>
+<tbody>
+{{range .}}<tr><td><code>{{.Resource}}</code></td><td><code>{{.Path}}</code></td><td>{{.Field}}</td><td>{{.Value}}</td></tr>
+{{end}}</tbody>
+</table>
+{{end}}{{end}}
+{{with .Interface}}{{$interface := .}}{{with .Inputs}}<h2>Inputs</h2>
+<table class="resources">
+<thead><tr><th>Name</th><th>Description</th><th>Type</th><th>Default</th><th>Required</th><th>Sensitive</th>{{range $interface.InputColumns}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{range .}}{{$input := .}}<tr><td><code>{{.Name}}</code></td><td>{{.DescriptionOrDash}}</td><td><code>{{.TypeOrDash}}</code></td><td><code>{{.DefaultOrDash}}</code></td><td>{{.RequiredLabel}}</td><td>{{.SensitiveLabel}}</td>{{range $interface.InputColumns}}<td>{{$input.Value .}}</td>{{end}}</tr>
+{{end}}</tbody>
+</table>
+{{end}}{{with .Outputs}}<h2>Outputs</h2>
+<table class="resources">
+<thead><tr><th>Name</th><th>Description</th><th>Sensitive</th>{{range $interface.OutputColumns}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{range .}}{{$output := .}}<tr><td><code>{{.Name}}</code></td><td>{{.DescriptionOrDash}}</td><td>{{.SensitiveLabel}}</td>{{range $interface.OutputColumns}}<td>{{$output.Value .}}</td>{{end}}</tr>
+{{end}}</tbody>
+</table>
+{{end}}{{end}}
+<hr>
+<p><strong>Total Resources:</strong> {{.TotalResources}}</p>
+<p><strong>Resource Types:</strong> {{len .ResourceTypes}}</p>
+{{with .Coverage}}<p><strong>Annotation Coverage:</strong> {{.}}</p>
+{{end}}<script>
+document.querySelectorAll("table.resources th").forEach(function (th) {
+  th.addEventListener("click", function () {
+    var table = th.closest("table"), body = table.tBodies[0];
+    var index = Array.prototype.indexOf.call(th.parentNode.children, th);
+    var asc = !th.classList.contains("asc");
+    table.querySelectorAll("th").forEach(function (h) { h.classList.remove("asc", "desc"); });
+    th.classList.add(asc ? "asc" : "desc");
+    Array.from(body.rows).sort(function (a, b) {
+      var x = a.cells[index].textContent, y = b.cells[index].textContent;
+      return (asc ? 1 : -1) * x.localeCompare(y, undefined, {numeric: true});
+    }).forEach(function (row) { body.appendChild(row); });
+  });
+});
+document.getElementById("filter").addEventListener("input", function (e) {
+  var text = e.target.value.toLowerCase();
+  document.querySelectorAll("table.resources tbody tr").forEach(function (row) {
+    row.style.display = row.textContent.toLowerCase().indexOf(text) === -1 ? "none" : "";
+  });
+});
+</script>
+</body>
+</html>
+`))
+
+// HTMLGenerator generates a self-contained HTML page with sortable, filterable tables
+type HTMLGenerator struct {
+	baseGenerator
+}
+
+// NewHTMLGenerator creates a new HTML generator
+func NewHTMLGenerator(schema validator.ValidationSchema) *HTMLGenerator {
+	return &HTMLGenerator{baseGenerator: baseGenerator{schema: schema}}
+}
+
+// GenerateDocumentation generates an HTML document for the given resources
+func (hg *HTMLGenerator) GenerateDocumentation(moduleName string, resources []parser.TerraformResource) string {
+	doc := hg.buildDocument(moduleName, resources)
+
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, doc); err != nil {
+		return fmt.Sprintf("<!-- failed to render documentation: %s -->\n", template.HTMLEscapeString(err.Error()))
+	}
+	return buf.String()
+}
@@ -0,0 +1,168 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+)
+
+// IndexEntry describes one per-module document linked from the index page
+type IndexEntry struct {
+	Module        string    `json:"module"`   // Module directory relative to the documented path
+	Document      string    `json:"document"` // Document file name relative to the index
+	Resources     int       `json:"resources"`
+	ResourceTypes int       `json:"resource_types"`
+	Coverage      *Coverage `json:"coverage,omitempty"` // Set when validation is enabled
+}
+
+// FileExtension returns the conventional file extension for a format, including the dot
+func FileExtension(format string) string {
+	switch strings.ToLower(format) {
+	case FormatHTML:
+		return ".html"
+	case FormatJSON:
+		return ".json"
+	case FormatCSV:
+		return ".csv"
+	case FormatAsciiDoc, "adoc":
+		return ".adoc"
+	}
+	return ".md"
+}
+
+// indexHTMLTemplate renders the HTML index page
+var indexHTMLTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} - Module Index</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #24292f; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #d0d7de; padding: 6px 12px; text-align: left; }
+th { background: #f6f8fa; }
+</style>
+</head>
+<body>
+<h1>{{.Title}} - Module Index</h1>
+<table>
+<thead><tr><th>Module</th><th>Resources</th><th>Resource Types</th>{{if .Coverage}}<th>Coverage</th>{{end}}</tr></thead>
+<tbody>
+{{range .Entries}}<tr><td><a href="{{.Document}}">{{.Module}}</a></td><td>{{.Resources}}</td><td>{{.ResourceTypes}}</td>{{with .Coverage}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</tbody>
+</table>
+{{with .Coverage}}<p><strong>Annotation Coverage:</strong> {{.}}</p>
+{{end}}
+</body>
+</html>
+`))
+
+// GenerateIndex renders an index page in the given format linking per-module documents. When
+// entries carry coverage, the index shows it per module and in total.
+func GenerateIndex(format, title string, entries []IndexEntry) (string, error) {
+	total := 0
+	var coverage *Coverage
+	for _, entry := range entries {
+		total += entry.Resources
+		if entry.Coverage != nil {
+			if coverage == nil {
+				coverage = &Coverage{}
+			}
+			coverage.merge(*entry.Coverage)
+		}
+	}
+
+	switch strings.ToLower(format) {
+	case FormatHTML:
+		var buf bytes.Buffer
+		err := indexHTMLTemplate.Execute(&buf, struct {
+			Title    string
+			Entries  []IndexEntry
+			Coverage *Coverage
+		}{title, entries, coverage})
+		return buf.String(), err
+
+	case FormatJSON:
+		data, err := json.MarshalIndent(struct {
+			Title          string       `json:"title"`
+			TotalResources int          `json:"total_resources"`
+			Coverage       *Coverage    `json:"coverage,omitempty"`
+			Modules        []IndexEntry `json:"modules"`
+		}{title, total, coverage, entries}, "", "  ")
+		return string(data) + "\n", err
+
+	case FormatCSV:
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		header := []string{"module", "document", "resources", "resource_types"}
+		if coverage != nil {
+			header = append(header, "annotated", "passing", "coverage_percent")
+		}
+		_ = w.Write(header)
+		for _, entry := range entries {
+			row := []string{entry.Module, entry.Document, strconv.Itoa(entry.Resources), strconv.Itoa(entry.ResourceTypes)}
+			if entry.Coverage != nil {
+				row = append(row, strconv.Itoa(entry.Coverage.Annotated), strconv.Itoa(entry.Coverage.Passing), strconv.FormatFloat(entry.Coverage.Percent, 'f', 1, 64))
+			}
+			_ = w.Write(row)
+		}
+		w.Flush()
+		return buf.String(), w.Error()
+
+	case FormatAsciiDoc, "adoc":
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "= %s - Module Index\n\n", title)
+		if coverage != nil {
+			sb.WriteString("[options=\"header\",cols=\"4*\"]\n|===\n|Module |Resources |Resource Types |Coverage\n\n")
+		} else {
+			sb.WriteString("[options=\"header\",cols=\"3*\"]\n|===\n|Module |Resources |Resource Types\n\n")
+		}
+		for _, entry := range entries {
+			fmt.Fprintf(&sb, "|xref:%s[%s]\n|%d\n|%d\n", entry.Document, asciiDocEscape(entry.Module), entry.Resources, entry.ResourceTypes)
+			if coverage != nil {
+				fmt.Fprintf(&sb, "|%s\n", coverageOrDash(entry.Coverage))
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("|===\n\n")
+		fmt.Fprintf(&sb, "*Total Resources:* %d\n", total)
+		if coverage != nil {
+			fmt.Fprintf(&sb, "\n*Annotation Coverage:* %s\n", coverage)
+		}
+		return sb.String(), nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s - Module Index\n\n", title)
+	if coverage != nil {
+		sb.WriteString("| Module | Resources | Resource Types | Coverage |\n")
+		sb.WriteString("|--------|-----------|----------------|----------|\n")
+	} else {
+		sb.WriteString("| Module | Resources | Resource Types |\n")
+		sb.WriteString("|--------|-----------|----------------|\n")
+	}
+	for _, entry := range entries {
+		fmt.Fprintf(&sb, "| [%s](%s) | %d | %d |", MarkdownEscape(entry.Module), entry.Document, entry.Resources, entry.ResourceTypes)
+		if coverage != nil {
+			fmt.Fprintf(&sb, " %s |", coverageOrDash(entry.Coverage))
+		}
+		sb.WriteString("\n")
+	}
+	fmt.Fprintf(&sb, "\n**Total Resources:** %d\n", total)
+	if coverage != nil {
+		fmt.Fprintf(&sb, "\n**Annotation Coverage:** %s\n", coverage)
+	}
+	return sb.String(), nil
+}
+
+// coverageOrDash formats a module's coverage, or "-" if it has none
+func coverageOrDash(coverage *Coverage) string {
+	if coverage == nil {
+		return "-"
+	}
+	return coverage.String()
+}
@@ -0,0 +1,94 @@
+package generator
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestGenerateIndex(t *testing.T) {
+	entries := []IndexEntry{
+		{Module: "project-a/infrastructure", Document: "project-a-infrastructure.md", Resources: 2, ResourceTypes: 2},
+		{Module: "shared_modules/networking", Document: "shared_modules-networking.md", Resources: 1, ResourceTypes: 1},
+	}
+
+	tests := map[string][]string{
+		FormatMarkdown: {
+			"# mono - Module Index",
+			"| [project-a/infrastructure](project-a-infrastructure.md) | 2 | 2 |",
+			`| [shared_modules/networking](shared_modules-networking.md) | 1 | 1 |`,
+			"**Total Resources:** 3",
+		},
+		FormatHTML:     {`<a href="project-a-infrastructure.md">project-a/infrastructure</a>`},
+		FormatCSV:      {"module,document,resources,resource_types\n", "project-a/infrastructure,project-a-infrastructure.md,2,2\n"},
+		FormatAsciiDoc: {"= mono - Module Index", "|xref:project-a-infrastructure.md[project-a/infrastructure]"},
+	}
+	for format, wants := range tests {
+		out, err := GenerateIndex(format, "mono", entries)
+		if err != nil {
+			t.Fatalf("GenerateIndex(%s) failed: %v", format, err)
+		}
+		for _, want := range wants {
+			if !strings.Contains(out, want) {
+				t.Errorf("%s index should contain %q, got:\n%s", format, want, out)
+			}
+		}
+	}
+
+	out, err := GenerateIndex(FormatJSON, "mono", entries)
+	if err != nil {
+		t.Fatalf("GenerateIndex(json) failed: %v", err)
+	}
+	var index struct {
+		TotalResources int          `json:"total_resources"`
+		Modules        []IndexEntry `json:"modules"`
+	}
+	if err := json.Unmarshal([]byte(out), &index); err != nil {
+		t.Fatalf("JSON index is invalid: %v", err)
+	}
+	if index.TotalResources != 3 || len(index.Modules) != 2 {
+		t.Errorf("unexpected JSON index: %+v", index)
+	}
+}
+
+func TestGenerateIndexCoverage(t *testing.T) {
+	entries := []IndexEntry{
+		{Module: "a", Document: "a.md", Resources: 2, ResourceTypes: 1, Coverage: &Coverage{Resources: 2, Annotated: 1, Passing: 1, Percent: 50}},
+		{Module: "b", Document: "b.md", Resources: 2, ResourceTypes: 2, Coverage: &Coverage{Resources: 2, Annotated: 2, Passing: 0, Percent: 100}},
+	}
+
+	tests := map[string][]string{
+		FormatMarkdown: {"| Module | Resources | Resource Types | Coverage |", "| [a](a.md) | 2 | 1 | 50.0% (1/2 annotated, 1 passing) |", "**Annotation Coverage:** 75.0% (3/4 annotated, 1 passing)"},
+		FormatCSV:      {"module,document,resources,resource_types,annotated,passing,coverage_percent\n", "b,b.md,2,2,2,0,100.0\n"},
+		FormatHTML:     {"<th>Coverage</th>", "<td>50.0% (1/2 annotated, 1 passing)</td>"},
+		FormatAsciiDoc: {"|Module |Resources |Resource Types |Coverage", "*Annotation Coverage:* 75.0%"},
+		FormatJSON:     {`"percent": 75`},
+	}
+	for format, wants := range tests {
+		out, err := GenerateIndex(format, "mono", entries)
+		if err != nil {
+			t.Fatalf("GenerateIndex(%s) failed: %v", format, err)
+		}
+		for _, want := range wants {
+			if !strings.Contains(out, want) {
+				t.Errorf("%s index should contain %q, got:\n%s", format, want, out)
+			}
+		}
+	}
+}
+
+func TestFileExtension(t *testing.T) {
+	expected := map[string]string{
+		FormatMarkdown: ".md",
+		"":             ".md",
+		FormatHTML:     ".html",
+		FormatJSON:     ".json",
+		FormatCSV:      ".csv",
+		FormatAsciiDoc: ".adoc",
+	}
+	for format, want := range expected {
+		if got := FileExtension(format); got != want {
+			t.Errorf("FileExtension(%q) = %q, want %q", format, got, want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Markers delimiting the generated section of a hand-written document
+const (
+	BeginMarker = "<!-- BEGIN_TERRANOTATE -->"
+	EndMarker   = "<!-- END_TERRANOTATE -->"
+)
+
+// InjectDocumentation replaces the content between BeginMarker and EndMarker in document with
+// generated, leaving everything outside the markers untouched. When the document has no
+// markers the section is appended to the end.
+func InjectDocumentation(document, generated string) (string, error) {
+	section := BeginMarker + "\n" + strings.TrimRight(generated, "\n") + "\n" + EndMarker
+
+	beginCount := strings.Count(document, BeginMarker)
+	endCount := strings.Count(document, EndMarker)
+
+	if beginCount == 0 && endCount == 0 {
+		if document != "" {
+			document = strings.TrimRight(document, "\n") + "\n\n"
+		}
+		return document + section + "\n", nil
+	}
+
+	if beginCount != 1 || endCount != 1 {
+		return "", fmt.Errorf("expected exactly one %s and one %s marker, found %d and %d", BeginMarker, EndMarker, beginCount, endCount)
+	}
+
+	begin := strings.Index(document, BeginMarker)
+	end := strings.Index(document, EndMarker)
+	if end < begin {
+		return "", fmt.Errorf("%s appears before %s", EndMarker, BeginMarker)
+	}
+
+	return document[:begin] + section + document[end+len(EndMarker):], nil
+}
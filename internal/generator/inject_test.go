@@ -0,0 +1,63 @@
+package generator
+
+import "testing"
+
+func TestInjectDocumentation(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "replaces content between markers",
+			document: "# Title\n\nIntro\n\n<!-- BEGIN_TERRANOTATE -->\nold\n<!-- END_TERRANOTATE -->\n\nFooter\n",
+			want:     "# Title\n\nIntro\n\n<!-- BEGIN_TERRANOTATE -->\nnew docs\n<!-- END_TERRANOTATE -->\n\nFooter\n",
+		},
+		{
+			name:     "appends markers when missing",
+			document: "# Title\nIntro",
+			want:     "# Title\nIntro\n\n<!-- BEGIN_TERRANOTATE -->\nnew docs\n<!-- END_TERRANOTATE -->\n",
+		},
+		{
+			name:     "creates section in empty document",
+			document: "",
+			want:     "<!-- BEGIN_TERRANOTATE -->\nnew docs\n<!-- END_TERRANOTATE -->\n",
+		},
+		{
+			name:     "missing end marker",
+			document: "<!-- BEGIN_TERRANOTATE -->\nold\n",
+			wantErr:  true,
+		},
+		{
+			name:     "markers out of order",
+			document: "<!-- END_TERRANOTATE -->\nold\n<!-- BEGIN_TERRANOTATE -->\n",
+			wantErr:  true,
+		},
+		{
+			name:     "duplicate markers",
+			document: "<!-- BEGIN_TERRANOTATE --><!-- END_TERRANOTATE --><!-- BEGIN_TERRANOTATE --><!-- END_TERRANOTATE -->",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := InjectDocumentation(tt.document, "new docs\n\n")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("InjectDocumentation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("InjectDocumentation() =\n%q\nwant\n%q", got, tt.want)
+			}
+
+			// Injecting the same content again is stable
+			if !tt.wantErr {
+				again, _ := InjectDocumentation(got, "new docs\n\n")
+				if again != got {
+					t.Errorf("InjectDocumentation() is not idempotent:\n%q", again)
+				}
+			}
+		})
+	}
+}
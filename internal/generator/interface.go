@@ -0,0 +1,454 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/toozej/terranotate/internal/parser"
+)
+
+// InterfaceSection documents what a module exposes to its callers, like terraform-docs does,
+// enriched with the annotations written on variables, outputs and module calls
+type InterfaceSection struct {
+	RequiredVersion string          `json:"required_version,omitempty"`
+	Providers       []ProviderEntry `json:"providers,omitempty"`
+	Modules         []ModuleEntry   `json:"modules,omitempty"`
+	Inputs          []InputEntry    `json:"inputs,omitempty"`
+	InputColumns    []string        `json:"input_columns,omitempty"` // Annotation fields set on inputs ("prefix:field")
+	Outputs         []OutputEntry   `json:"outputs,omitempty"`
+	OutputColumns   []string        `json:"output_columns,omitempty"` // Annotation fields set on outputs ("prefix:field")
+}
+
+// ProviderEntry documents a required provider
+type ProviderEntry struct {
+	Name    string `json:"name"`
+	Source  string `json:"source,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+// ModuleEntry documents a module call
+type ModuleEntry struct {
+	Name        string                            `json:"name"`
+	Source      string                            `json:"source,omitempty"`
+	Version     string                            `json:"version,omitempty"`
+	Line        int                               `json:"line"`
+	Description string                            `json:"description,omitempty"`
+	Annotations map[string]map[string]interface{} `json:"annotations,omitempty"`
+}
+
+// InputEntry documents an input variable
+type InputEntry struct {
+	Name        string                            `json:"name"`
+	Type        string                            `json:"type,omitempty"`    // Type constraint source text
+	Default     string                            `json:"default,omitempty"` // Default value source text
+	Required    bool                              `json:"required"`          // Whether the variable has no default
+	Line        int                               `json:"line"`
+	Description string                            `json:"description,omitempty"`
+	Sensitive   bool                              `json:"sensitive,omitempty"`
+	Fields      map[string]string                 `json:"fields,omitempty"`      // Annotation column values keyed by "prefix:field"
+	Annotations map[string]map[string]interface{} `json:"annotations,omitempty"` // All parsed fields keyed by prefix
+}
+
+// OutputEntry documents an output
+type OutputEntry struct {
+	Name        string                            `json:"name"`
+	Line        int                               `json:"line"`
+	Description string                            `json:"description,omitempty"`
+	Sensitive   bool                              `json:"sensitive,omitempty"`
+	Fields      map[string]string                 `json:"fields,omitempty"`      // Annotation column values keyed by "prefix:field"
+	Annotations map[string]map[string]interface{} `json:"annotations,omitempty"` // All parsed fields keyed by prefix
+}
+
+// SetModuleInterface documents a module interface alongside the resources of the documents
+// generated next. An empty interface documents resources only.
+func (bg *baseGenerator) SetModuleInterface(iface parser.ModuleInterface) {
+	bg.iface = iface
+}
+
+// buildInterface builds the documentation model for the module interface, or nil if the module
+// declares none
+func (bg *baseGenerator) buildInterface() *InterfaceSection {
+	if bg.iface.Empty() {
+		return nil
+	}
+
+	section := &InterfaceSection{RequiredVersion: bg.iface.RequiredVersion}
+	for _, provider := range bg.iface.Providers {
+		section.Providers = append(section.Providers, ProviderEntry(provider))
+	}
+
+	for _, call := range bg.iface.ModuleCalls {
+		annotations := interfaceAnnotations(call.Comments, call.GetAnnotation)
+		description, _ := interfaceDescription("", annotations)
+		section.Modules = append(section.Modules, ModuleEntry{
+			Name:        call.Name,
+			Source:      call.Source,
+			Version:     call.Version,
+			Line:        call.StartLine,
+			Description: description,
+			Annotations: annotations,
+		})
+	}
+
+	for _, variable := range bg.iface.Variables {
+		annotations := interfaceAnnotations(variable.Comments, variable.GetAnnotation)
+		description, fromAnnotation := interfaceDescription(variable.Description, annotations)
+		sensitive, fromSensitive := interfaceSensitive(variable.Sensitive, annotations)
+		section.Inputs = append(section.Inputs, InputEntry{
+			Name:        variable.Name,
+			Type:        variable.Type,
+			Default:     variable.Default,
+			Required:    !variable.HasDefault,
+			Line:        variable.StartLine,
+			Description: description,
+			Sensitive:   sensitive,
+			Fields:      interfaceFields(annotations, fromAnnotation, fromSensitive),
+			Annotations: annotations,
+		})
+	}
+
+	for _, output := range bg.iface.Outputs {
+		annotations := interfaceAnnotations(output.Comments, output.GetAnnotation)
+		description, fromAnnotation := interfaceDescription(output.Description, annotations)
+		sensitive, fromSensitive := interfaceSensitive(output.Sensitive, annotations)
+		section.Outputs = append(section.Outputs, OutputEntry{
+			Name:        output.Name,
+			Line:        output.StartLine,
+			Description: description,
+			Sensitive:   sensitive,
+			Fields:      interfaceFields(annotations, fromAnnotation, fromSensitive),
+			Annotations: annotations,
+		})
+	}
+
+	for _, input := range section.Inputs {
+		section.InputColumns = appendColumns(section.InputColumns, input.Fields)
+	}
+	for _, output := range section.Outputs {
+		section.OutputColumns = appendColumns(section.OutputColumns, output.Fields)
+	}
+	sort.Strings(section.InputColumns)
+	sort.Strings(section.OutputColumns)
+
+	return section
+}
+
+// interfaceAnnotations collects the effective fields of each prefix written on a block
+func interfaceAnnotations(comments []parser.StructuredComment, get func(prefix string) (parser.StructuredComment, bool)) map[string]map[string]interface{} {
+	annotations := make(map[string]map[string]interface{})
+	for _, comment := range comments {
+		if _, exists := annotations[comment.Prefix]; !exists {
+			annotation, _ := get(comment.Prefix)
+			annotations[comment.Prefix] = annotation.Fields
+		}
+	}
+	return annotations
+}
+
+// interfaceDescription returns the block's description attribute, else the description
+// annotation, and the annotation column it came from, if any
+func interfaceDescription(description string, annotations map[string]map[string]interface{}) (string, string) {
+	if description != "" {
+		return description, ""
+	}
+	for _, prefix := range []string{"@docs", "@metadata"} {
+		if value, exists := annotations[prefix]["description"]; exists {
+			return fmt.Sprintf("%v", value), prefix + ":description"
+		}
+	}
+	return "", ""
+}
+
+// interfaceSensitive reports whether the block is marked sensitive by its sensitive attribute
+// or a sensitive annotation, and the annotation column it came from, if any
+func interfaceSensitive(sensitive bool, annotations map[string]map[string]interface{}) (bool, string) {
+	if sensitive {
+		return true, ""
+	}
+	for _, prefix := range sortedPrefixes(annotations) {
+		if value, exists := annotations[prefix]["sensitive"]; exists {
+			return value == true || value == "true" || value == "yes", prefix + ":sensitive"
+		}
+	}
+	return false, ""
+}
+
+// interfaceFields flattens the annotations of a block into column values keyed by
+// "prefix:field", leaving out the columns shown as the description and sensitivity
+func interfaceFields(annotations map[string]map[string]interface{}, exclude ...string) map[string]string {
+	fields := make(map[string]string)
+	for prefix, values := range annotations {
+		for _, field := range flattenAnnotation(values, "") {
+			column := prefix + ":" + field.path
+			if !contains(exclude, column) {
+				fields[column] = field.value
+			}
+		}
+	}
+	return fields
+}
+
+// appendColumns adds the columns of an entry that are not in the list yet
+func appendColumns(columns []string, fields map[string]string) []string {
+	for column := range fields {
+		if !contains(columns, column) {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// Value returns the input's value for an annotation column, or "-" if it is not set
+func (e InputEntry) Value(column string) string {
+	return valueOrDash(e.Fields, column)
+}
+
+// Value returns the output's value for an annotation column, or "-" if it is not set
+func (e OutputEntry) Value(column string) string {
+	return valueOrDash(e.Fields, column)
+}
+
+// valueOrDash returns a column value, or "-" if it is not set
+func valueOrDash(fields map[string]string, column string) string {
+	if value, ok := fields[column]; ok {
+		return value
+	}
+	return "-"
+}
+
+// SourceOrDash returns the module's source, or "-" if it is not a literal
+func (e ModuleEntry) SourceOrDash() string {
+	return orDash(e.Source)
+}
+
+// VersionOrDash returns the module's version constraint, or "-"
+func (e ModuleEntry) VersionOrDash() string {
+	return orDash(e.Version)
+}
+
+// DescriptionOrDash returns the module's description, or "-"
+func (e ModuleEntry) DescriptionOrDash() string {
+	return orDash(e.Description)
+}
+
+// SourceOrDash returns the provider's source, or "-"
+func (e ProviderEntry) SourceOrDash() string {
+	return orDash(e.Source)
+}
+
+// VersionOrDash returns the provider's version constraint, or "-"
+func (e ProviderEntry) VersionOrDash() string {
+	return orDash(e.Version)
+}
+
+// DescriptionOrDash returns the input's description, or "-"
+func (e InputEntry) DescriptionOrDash() string {
+	return orDash(e.Description)
+}
+
+// TypeOrDash returns the input's type constraint on one line, or "-"
+func (e InputEntry) TypeOrDash() string {
+	return orDash(singleLine(e.Type))
+}
+
+// DefaultOrDash returns the input's default on one line, or "-" if it is required
+func (e InputEntry) DefaultOrDash() string {
+	if e.Required {
+		return "-"
+	}
+	return singleLine(e.Default)
+}
+
+// RequiredLabel returns "yes" or "no"
+func (e InputEntry) RequiredLabel() string {
+	return yesNo(e.Required)
+}
+
+// SensitiveLabel returns "yes" or "no"
+func (e InputEntry) SensitiveLabel() string {
+	return yesNo(e.Sensitive)
+}
+
+// DescriptionOrDash returns the output's description, or "-"
+func (e OutputEntry) DescriptionOrDash() string {
+	return orDash(e.Description)
+}
+
+// SensitiveLabel returns "yes" or "no"
+func (e OutputEntry) SensitiveLabel() string {
+	return yesNo(e.Sensitive)
+}
+
+// orDash returns s, or "-" if it is empty
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// yesNo returns "yes" for true and "no" for false
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// singleLine collapses the whitespace of HCL source text so it fits a table cell
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// markdownCode formats source text as inline code in a Markdown table cell
+func markdownCode(s string) string {
+	if s == "-" {
+		return s
+	}
+	return "`" + strings.ReplaceAll(s, "|", `\|`) + "`"
+}
+
+// generateInterfaceSections generates the Markdown requirements and module sections, which
+// precede the resources, and the inputs and outputs sections, which follow them
+func generateInterfaceSections(section *InterfaceSection) (string, string) {
+	if section == nil {
+		return "", ""
+	}
+
+	var head strings.Builder
+	if section.RequiredVersion != "" || len(section.Providers) > 0 {
+		head.WriteString("## Requirements\n\n")
+		head.WriteString("| Name | Source | Version |\n")
+		head.WriteString("|------|--------|---------|\n")
+		if section.RequiredVersion != "" {
+			fmt.Fprintf(&head, "| terraform | - | %s |\n", markdownCode(section.RequiredVersion))
+		}
+		for _, provider := range section.Providers {
+			fmt.Fprintf(&head, "| %s | %s | %s |\n", provider.Name, MarkdownEscape(provider.SourceOrDash()), markdownCode(provider.VersionOrDash()))
+		}
+		head.WriteString("\n")
+	}
+	if len(section.Modules) > 0 {
+		head.WriteString("## Modules\n\n")
+		head.WriteString("| Name | Source | Version | Description |\n")
+		head.WriteString("|------|--------|---------|-------------|\n")
+		for _, module := range section.Modules {
+			fmt.Fprintf(&head, "| `%s` | %s | %s | %s |\n", module.Name, markdownCode(module.SourceOrDash()), markdownCode(module.VersionOrDash()), MarkdownEscape(module.DescriptionOrDash()))
+		}
+		head.WriteString("\n")
+	}
+
+	var tail strings.Builder
+	if len(section.Inputs) > 0 {
+		headers := []string{"Name", "Description", "Type", "Default", "Required", "Sensitive"}
+		for _, column := range section.InputColumns {
+			headers = append(headers, MarkdownEscape(column))
+		}
+		tail.WriteString("## Inputs\n\n")
+		tail.WriteString("| " + strings.Join(headers, " | ") + " |\n")
+		tail.WriteString("|" + strings.Repeat("--------|", len(headers)) + "\n")
+		for _, input := range section.Inputs {
+			cells := []string{"`" + input.Name + "`", MarkdownEscape(input.DescriptionOrDash()), markdownCode(input.TypeOrDash()), markdownCode(input.DefaultOrDash()), input.RequiredLabel(), input.SensitiveLabel()}
+			for _, column := range section.InputColumns {
+				cells = append(cells, MarkdownEscape(input.Value(column)))
+			}
+			tail.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		}
+		tail.WriteString("\n")
+	}
+	if len(section.Outputs) > 0 {
+		headers := []string{"Name", "Description", "Sensitive"}
+		for _, column := range section.OutputColumns {
+			headers = append(headers, MarkdownEscape(column))
+		}
+		tail.WriteString("## Outputs\n\n")
+		tail.WriteString("| " + strings.Join(headers, " | ") + " |\n")
+		tail.WriteString("|" + strings.Repeat("--------|", len(headers)) + "\n")
+		for _, output := range section.Outputs {
+			cells := []string{"`" + output.Name + "`", MarkdownEscape(output.DescriptionOrDash()), output.SensitiveLabel()}
+			for _, column := range section.OutputColumns {
+				cells = append(cells, MarkdownEscape(output.Value(column)))
+			}
+			tail.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		}
+		tail.WriteString("\n")
+	}
+
+	return head.String(), tail.String()
+}
+
+// generateAsciiDocInterfaceSections generates the AsciiDoc counterparts of
+// generateInterfaceSections
+func generateAsciiDocInterfaceSections(section *InterfaceSection) (string, string) {
+	if section == nil {
+		return "", ""
+	}
+
+	table := func(sb *strings.Builder, title string, headers []string, rows [][]string) {
+		fmt.Fprintf(sb, "== %s\n\n", title)
+		fmt.Fprintf(sb, "[options=\"header\",cols=\"%d*\"]\n|===\n", len(headers))
+		for _, header := range headers {
+			fmt.Fprintf(sb, "|%s ", asciiDocEscape(header))
+		}
+		sb.WriteString("\n\n")
+		for _, row := range rows {
+			for _, cell := range row {
+				fmt.Fprintf(sb, "|%s\n", cell)
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("|===\n\n")
+	}
+	code := func(s string) string {
+		if s == "-" {
+			return s
+		}
+		return "`+" + asciiDocEscape(s) + "+`"
+	}
+
+	var head strings.Builder
+	if section.RequiredVersion != "" || len(section.Providers) > 0 {
+		var rows [][]string
+		if section.RequiredVersion != "" {
+			rows = append(rows, []string{"terraform", "-", code(section.RequiredVersion)})
+		}
+		for _, provider := range section.Providers {
+			rows = append(rows, []string{asciiDocEscape(provider.Name), asciiDocEscape(provider.SourceOrDash()), code(provider.VersionOrDash())})
+		}
+		table(&head, "Requirements", []string{"Name", "Source", "Version"}, rows)
+	}
+	if len(section.Modules) > 0 {
+		var rows [][]string
+		for _, module := range section.Modules {
+			rows = append(rows, []string{code(module.Name), code(module.SourceOrDash()), code(module.VersionOrDash()), asciiDocEscape(module.DescriptionOrDash())})
+		}
+		table(&head, "Modules", []string{"Name", "Source", "Version", "Description"}, rows)
+	}
+
+	var tail strings.Builder
+	if len(section.Inputs) > 0 {
+		var rows [][]string
+		for _, input := range section.Inputs {
+			row := []string{code(input.Name), asciiDocEscape(input.DescriptionOrDash()), code(input.TypeOrDash()), code(input.DefaultOrDash()), input.RequiredLabel(), input.SensitiveLabel()}
+			for _, column := range section.InputColumns {
+				row = append(row, asciiDocEscape(input.Value(column)))
+			}
+			rows = append(rows, row)
+		}
+		table(&tail, "Inputs", append([]string{"Name", "Description", "Type", "Default", "Required", "Sensitive"}, section.InputColumns...), rows)
+	}
+	if len(section.Outputs) > 0 {
+		var rows [][]string
+		for _, output := range section.Outputs {
+			row := []string{code(output.Name), asciiDocEscape(output.DescriptionOrDash()), output.SensitiveLabel()}
+			for _, column := range section.OutputColumns {
+				row = append(row, asciiDocEscape(output.Value(column)))
+			}
+			rows = append(rows, row)
+		}
+		table(&tail, "Outputs", append([]string{"Name", "Description", "Sensitive"}, section.OutputColumns...), rows)
+	}
+
+	return head.String(), tail.String()
+}
@@ -0,0 +1,104 @@
+package generator
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/toozej/terranotate/internal/parser"
+	"github.com/toozej/terranotate/internal/validator"
+)
+
+func testInterface() parser.ModuleInterface {
+	return parser.ModuleInterface{
+		RequiredVersion: ">= 1.5",
+		Providers:       []parser.ProviderRequirement{{Name: "aws", Source: "hashicorp/aws", Version: "~> 5.0"}},
+		ModuleCalls: []parser.ModuleCall{{
+			Name:     "network",
+			Source:   "./modules/network",
+			Comments: []parser.StructuredComment{{Prefix: "@docs", Fields: map[string]interface{}{"description": "Shared network"}}},
+		}},
+		Variables: []parser.Variable{
+			{
+				Name: "db_password",
+				Type: "string",
+				Comments: []parser.StructuredComment{
+					{Prefix: "@metadata", Fields: map[string]interface{}{"owner": "platform", "sensitive": true}},
+					{Prefix: "@docs", Fields: map[string]interface{}{"description": "Admin password", "example": "s3cr3t"}},
+				},
+			},
+			{Name: "tags", Type: "map(object({\n  name = string\n}))", Default: "{}", HasDefault: true, Description: "Tags | everywhere"},
+		},
+		Outputs: []parser.Output{{Name: "endpoint", Description: "Database endpoint", Sensitive: true}},
+	}
+}
+
+func TestBuildInterface(t *testing.T) {
+	gen := NewMarkdownGenerator(validator.ValidationSchema{})
+	if gen.buildInterface() != nil {
+		t.Error("Expected no interface section before SetModuleInterface")
+	}
+
+	gen.SetModuleInterface(testInterface())
+	section := gen.buildInterface()
+
+	password := section.Inputs[0]
+	if password.Description != "Admin password" || !password.Sensitive || !password.Required {
+		t.Errorf("Expected description and sensitivity from annotations, got %+v", password)
+	}
+	expectedColumns := []string{"@docs:example", "@metadata:owner"}
+	if strings.Join(section.InputColumns, ",") != strings.Join(expectedColumns, ",") {
+		t.Errorf("InputColumns = %v, expected %v", section.InputColumns, expectedColumns)
+	}
+	if section.Modules[0].Description != "Shared network" {
+		t.Errorf("Expected the module description from its annotation, got %q", section.Modules[0].Description)
+	}
+	if tags := section.Inputs[1]; tags.TypeOrDash() != "map(object({ name = string }))" || tags.DefaultOrDash() != "{}" || tags.Value("@metadata:owner") != "-" {
+		t.Errorf("Unexpected tags cells: %q %q %q", tags.TypeOrDash(), tags.DefaultOrDash(), tags.Value("@metadata:owner"))
+	}
+}
+
+func TestGenerateDocumentationWithInterface(t *testing.T) {
+	resources := []parser.TerraformResource{{Type: "aws_db_instance", Name: "main"}}
+
+	gen := NewMarkdownGenerator(validator.ValidationSchema{})
+	gen.SetModuleInterface(testInterface())
+	doc := gen.GenerateDocumentation("db", resources)
+
+	expected := []string{
+		"## Requirements\n",
+		"| terraform | - | `>= 1.5` |\n",
+		"| aws | hashicorp/aws | `~> 5.0` |\n",
+		"| `network` | `./modules/network` | - | Shared network |\n",
+		"| Name | Description | Type | Default | Required | Sensitive | @docs:example | @metadata:owner |\n",
+		"| `db_password` | Admin password | `string` | - | yes | yes | s3cr3t | platform |\n",
+		"| `tags` | Tags \\| everywhere | `map(object({ name = string }))` | `{}` | no | no | - | - |\n",
+		"| `endpoint` | Database endpoint | yes |\n",
+	}
+	for _, want := range expected {
+		if !strings.Contains(doc, want) {
+			t.Errorf("Expected documentation to contain %q, got:\n%s", want, doc)
+		}
+	}
+	if strings.Index(doc, "## Modules") > strings.Index(doc, "## aws_db_instance") || strings.Index(doc, "## Inputs") < strings.Index(doc, "## aws_db_instance") {
+		t.Error("Expected requirements and modules before resources, inputs and outputs after them")
+	}
+
+	for _, format := range []string{FormatHTML, FormatAsciiDoc} {
+		renderer, _ := NewRenderer(format, validator.ValidationSchema{})
+		renderer.SetModuleInterface(testInterface())
+		if output := renderer.GenerateDocumentation("db", resources); !strings.Contains(output, "Inputs") || !strings.Contains(output, "db_password") {
+			t.Errorf("Expected %s documentation to include inputs, got:\n%s", format, output)
+		}
+	}
+
+	jsonGen := NewJSONGenerator(validator.ValidationSchema{})
+	jsonGen.SetModuleInterface(testInterface())
+	var parsed Document
+	if err := json.Unmarshal([]byte(jsonGen.GenerateDocumentation("db", resources)), &parsed); err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+	if parsed.Interface == nil || len(parsed.Interface.Inputs) != 2 || parsed.Interface.Outputs[0].Name != "endpoint" {
+		t.Errorf("Expected the interface in JSON output, got %+v", parsed.Interface)
+	}
+}
@@ -0,0 +1,204 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/toozej/terranotate/internal/parser"
+	"github.com/toozej/terranotate/internal/validator"
+)
+
+// DocumentationRenderer renders documentation for a set of resources in a specific format
+type DocumentationRenderer interface {
+	GenerateDocumentation(moduleName string, resources []parser.TerraformResource) string
+	EnableValidation() error
+	SetModuleInterface(iface parser.ModuleInterface)
+}
+
+// Supported documentation formats
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+	FormatJSON     = "json"
+	FormatCSV      = "csv"
+	FormatAsciiDoc = "asciidoc"
+)
+
+// Formats lists the supported documentation formats
+var Formats = []string{FormatMarkdown, FormatHTML, FormatJSON, FormatCSV, FormatAsciiDoc}
+
+// NewRenderer creates the documentation renderer for a format. An empty format selects Markdown.
+func NewRenderer(format string, schema validator.ValidationSchema) (DocumentationRenderer, error) {
+	switch strings.ToLower(format) {
+	case "", FormatMarkdown, "md":
+		return NewMarkdownGenerator(schema), nil
+	case FormatHTML:
+		return NewHTMLGenerator(schema), nil
+	case FormatJSON:
+		return NewJSONGenerator(schema), nil
+	case FormatCSV:
+		return NewCSVGenerator(schema), nil
+	case FormatAsciiDoc, "adoc":
+		return NewAsciiDocGenerator(schema), nil
+	}
+	return nil, fmt.Errorf("unsupported format '%s' (supported: %s)", format, strings.Join(Formats, ", "))
+}
+
+// JSONGenerator generates JSON documentation from resources
+type JSONGenerator struct {
+	baseGenerator
+}
+
+// NewJSONGenerator creates a new JSON generator
+func NewJSONGenerator(schema validator.ValidationSchema) *JSONGenerator {
+	return &JSONGenerator{baseGenerator: baseGenerator{schema: schema}}
+}
+
+// GenerateDocumentation generates a JSON document for the given resources
+func (jg *JSONGenerator) GenerateDocumentation(moduleName string, resources []parser.TerraformResource) string {
+	doc := jg.buildDocument(moduleName, resources)
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		// Annotation values are strings, numbers, booleans, slices and maps, which always encode
+		return fmt.Sprintf("{\"error\": %q}\n", err.Error())
+	}
+	return string(data) + "\n"
+}
+
+// CSVGenerator generates CSV documentation with one row per resource
+type CSVGenerator struct {
+	baseGenerator
+}
+
+// NewCSVGenerator creates a new CSV generator
+func NewCSVGenerator(schema validator.ValidationSchema) *CSVGenerator {
+	return &CSVGenerator{baseGenerator: baseGenerator{schema: schema}}
+}
+
+// GenerateDocumentation generates a CSV document for the given resources. Columns are the
+// module, resource type, name and description followed by every required and optional schema field,
+// then the validation status and missing fields when validation is enabled.
+func (cg *CSVGenerator) GenerateDocumentation(moduleName string, resources []parser.TerraformResource) string {
+	doc := cg.buildDocument(moduleName, resources)
+	columns := doc.allColumns()
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	header := append([]string{"module", "type", "name", "address", "description"}, columns...)
+	if doc.Coverage != nil {
+		header = append(header, "status", "missing")
+	}
+	_ = w.Write(header)
+
+	for _, section := range doc.ResourceTypes {
+		for _, entry := range section.Resources {
+			row := []string{doc.Module, entry.Type, entry.Name, entry.Address, entry.Description}
+			for _, column := range columns {
+				row = append(row, entry.Fields[column])
+			}
+			if entry.Validation != nil {
+				row = append(row, entry.Validation.Status(), strings.Join(entry.Validation.Missing, ";"))
+			}
+			_ = w.Write(row)
+		}
+	}
+
+	w.Flush()
+	return buf.String()
+}
+
+// AsciiDocGenerator generates AsciiDoc documentation from resources
+type AsciiDocGenerator struct {
+	baseGenerator
+}
+
+// NewAsciiDocGenerator creates a new AsciiDoc generator
+func NewAsciiDocGenerator(schema validator.ValidationSchema) *AsciiDocGenerator {
+	return &AsciiDocGenerator{baseGenerator: baseGenerator{schema: schema}}
+}
+
+// GenerateDocumentation generates an AsciiDoc document for the given resources
+func (ag *AsciiDocGenerator) GenerateDocumentation(moduleName string, resources []parser.TerraformResource) string {
+	doc := ag.buildDocument(moduleName, resources)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "= %s - Resource Documentation\n\n", moduleName)
+	sb.WriteString("This document provides an overview of all Terraform resources with their metadata annotations.\n\n")
+
+	head, tail := generateAsciiDocInterfaceSections(doc.Interface)
+	sb.WriteString(head)
+
+	for _, section := range doc.ResourceTypes {
+		fmt.Fprintf(&sb, "== %s\n\n", section.Type)
+
+		headers := []string{"Resource Name", "Description"}
+		if len(section.Columns) > 0 {
+			headers = append([]string{"Resource"}, section.Columns...)
+		}
+		if section.Coverage != nil {
+			headers = append(headers, "Status", "Missing")
+		}
+
+		fmt.Fprintf(&sb, "[options=\"header\",cols=\"%d*\"]\n|===\n", len(headers))
+		for _, header := range headers {
+			fmt.Fprintf(&sb, "|%s ", asciiDocEscape(header))
+		}
+		sb.WriteString("\n\n")
+
+		for _, entry := range section.Resources {
+			fmt.Fprintf(&sb, "|`%s`\n", asciiDocEscape(entry.Label()))
+			if len(section.Columns) == 0 {
+				fmt.Fprintf(&sb, "|%s\n", asciiDocEscape(entry.DescriptionOrDash()))
+			}
+			for _, column := range section.Columns {
+				fmt.Fprintf(&sb, "|%s\n", asciiDocEscape(entry.Value(column)))
+			}
+			if entry.Validation != nil {
+				fmt.Fprintf(&sb, "|%s\n|%s\n", entry.Validation.Status(), asciiDocEscape(entry.Validation.MissingOrDash()))
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("|===\n\n")
+
+		if section.Coverage != nil {
+			fmt.Fprintf(&sb, "*Coverage:* %s\n\n", section.Coverage)
+		}
+
+		if optional := section.OptionalValues(); len(optional) > 0 {
+			sb.WriteString(".Optional fields\n")
+			sb.WriteString("[options=\"header\",cols=\"3*\"]\n|===\n|Resource |Field |Value\n\n")
+			for _, value := range optional {
+				fmt.Fprintf(&sb, "|`%s`\n|%s\n|%s\n\n", asciiDocEscape(value.Resource), asciiDocEscape(value.Field), asciiDocEscape(value.Value))
+			}
+			sb.WriteString("|===\n\n")
+		}
+
+		if attributes := section.AttributeValues(); len(attributes) > 0 {
+			sb.WriteString(".Attribute annotations\n")
+			sb.WriteString("[options=\"header\",cols=\"4*\"]\n|===\n|Resource |Attribute |Field |Value\n\n")
+			for _, value := range attributes {
+				fmt.Fprintf(&sb, "|`%s`\n|`%s`\n|%s\n|%s\n\n", asciiDocEscape(value.Resource), asciiDocEscape(value.Path), asciiDocEscape(value.Field), asciiDocEscape(value.Value))
+			}
+			sb.WriteString("|===\n\n")
+		}
+	}
+
+	sb.WriteString(tail)
+	sb.WriteString("'''\n\n")
+	fmt.Fprintf(&sb, "*Total Resources:* %d\n\n", doc.TotalResources)
+	fmt.Fprintf(&sb, "*Resource Types:* %d\n", len(doc.ResourceTypes))
+	if doc.Coverage != nil {
+		fmt.Fprintf(&sb, "\n*Annotation Coverage:* %s\n", doc.Coverage)
+	}
+
+	return sb.String()
+}
+
+// asciiDocEscape escapes table cell separators in AsciiDoc text
+func asciiDocEscape(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
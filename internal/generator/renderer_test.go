@@ -0,0 +1,180 @@
+package generator
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/toozej/terranotate/internal/parser"
+	"github.com/toozej/terranotate/internal/validator"
+)
+
+func rendererTestData() (validator.ValidationSchema, []parser.TerraformResource) {
+	schema := validator.ValidationSchema{
+		ResourceTypes: map[string]validator.ResourceRules{
+			"aws_vpc": {
+				PrefixRules: map[string]validator.PrefixRule{
+					"@metadata": {RequiredFields: []string{"owner"}},
+				},
+			},
+		},
+	}
+
+	resources := []parser.TerraformResource{
+		{
+			Type:      "aws_vpc",
+			Name:      "main",
+			StartLine: 3,
+			PrecedingComments: []parser.StructuredComment{
+				{Prefix: "@metadata", Fields: map[string]interface{}{"owner": "team|<net>", "replicas": 3}},
+			},
+		},
+		{
+			Type:      "aws_s3_bucket",
+			Name:      "logs",
+			StartLine: 9,
+			PrecedingComments: []parser.StructuredComment{
+				{Prefix: "@docs", Fields: map[string]interface{}{"description": "Access, logs"}},
+			},
+		},
+	}
+
+	return schema, resources
+}
+
+func TestNewRenderer(t *testing.T) {
+	schema := validator.ValidationSchema{}
+
+	for _, format := range append(Formats, "", "MD", "adoc") {
+		if _, err := NewRenderer(format, schema); err != nil {
+			t.Errorf("NewRenderer(%q) failed: %v", format, err)
+		}
+	}
+
+	if _, err := NewRenderer("pdf", schema); err == nil {
+		t.Error("NewRenderer() should fail for unsupported format")
+	}
+}
+
+func TestJSONGenerator(t *testing.T) {
+	schema, resources := rendererTestData()
+	out := NewJSONGenerator(schema).GenerateDocumentation("net", resources)
+
+	var doc Document
+	if err := json.Unmarshal([]byte(out), &doc); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, out)
+	}
+	if doc.Module != "net" || doc.TotalResources != 2 || len(doc.ResourceTypes) != 2 {
+		t.Fatalf("unexpected document: %+v", doc)
+	}
+
+	vpc := doc.ResourceTypes[1]
+	if vpc.Type != "aws_vpc" || vpc.Resources[0].Fields["@metadata:owner"] != "team|<net>" {
+		t.Errorf("unexpected aws_vpc section: %+v", vpc)
+	}
+	if vpc.Resources[0].Annotations["@metadata"]["replicas"] != float64(3) {
+		t.Errorf("expected all annotations to be included, got %v", vpc.Resources[0].Annotations)
+	}
+}
+
+func TestCSVGenerator(t *testing.T) {
+	schema, resources := rendererTestData()
+	out := NewCSVGenerator(schema).GenerateDocumentation("net", resources)
+
+	records, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not valid CSV: %v\n%s", err, out)
+	}
+
+	expected := [][]string{
+		{"module", "type", "name", "address", "description", "@metadata:owner"},
+		{"net", "aws_s3_bucket", "logs", "aws_s3_bucket.logs", "Access, logs", ""},
+		{"net", "aws_vpc", "main", "aws_vpc.main", "", "team|<net>"},
+	}
+	if len(records) != len(expected) {
+		t.Fatalf("expected %d rows, got %d: %v", len(expected), len(records), records)
+	}
+	for i := range expected {
+		if strings.Join(records[i], ",") != strings.Join(expected[i], ",") {
+			t.Errorf("row %d = %v, want %v", i, records[i], expected[i])
+		}
+	}
+}
+
+func TestHTMLGenerator(t *testing.T) {
+	schema, resources := rendererTestData()
+	out := NewHTMLGenerator(schema).GenerateDocumentation("net", resources)
+
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		"<h2>aws_vpc</h2>",
+		"<th>@metadata:owner</th>",
+		"<td>team|&lt;net&gt;</td>",
+		"<td>Access, logs</td>",
+		`id="filter"`,
+		"<script>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("HTML output should contain %q", want)
+		}
+	}
+	if strings.Contains(out, "<net>") {
+		t.Error("HTML output should escape annotation values")
+	}
+}
+
+func TestAsciiDocGenerator(t *testing.T) {
+	schema, resources := rendererTestData()
+	out := NewAsciiDocGenerator(schema).GenerateDocumentation("net", resources)
+
+	for _, want := range []string{
+		"= net - Resource Documentation",
+		"== aws_vpc",
+		"|Resource |@metadata:owner",
+		"|`main`\n|team\\|<net>\n",
+		"|`logs`\n|Access, logs\n",
+		"*Total Resources:* 2",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("AsciiDoc output should contain %q\n%s", want, out)
+		}
+	}
+}
+
+func TestRenderersIncludeOptionalFields(t *testing.T) {
+	schema := validator.ValidationSchema{
+		Global: validator.GlobalRules{
+			PrefixRules: map[string]validator.PrefixRule{
+				"@config": {NestedFields: map[string]validator.NestedRule{
+					"backup": {OptionalFields: []string{"retention_days"}},
+				}},
+			},
+		},
+	}
+	resources := []parser.TerraformResource{
+		{
+			Type: "aws_s3_bucket",
+			Name: "logs",
+			InlineComments: []parser.StructuredComment{
+				{Prefix: "@config", Fields: map[string]interface{}{"backup": map[string]interface{}{"retention_days": 30}}},
+			},
+		},
+	}
+
+	expected := map[string]string{
+		FormatHTML:     "<td><code>logs</code></td><td>@config:backup.retention_days</td><td>30</td>",
+		FormatAsciiDoc: ".Optional fields\n[options=\"header\",cols=\"3*\"]\n|===\n|Resource |Field |Value\n\n|`logs`\n|@config:backup.retention_days\n|30\n",
+		FormatCSV:      "module,type,name,address,description,@config:backup.retention_days\nstorage,aws_s3_bucket,logs,aws_s3_bucket.logs,,30\n",
+		FormatJSON:     `"optional_columns": [` + "\n" + `        "@config:backup.retention_days"`,
+	}
+	for format, want := range expected {
+		renderer, err := NewRenderer(format, schema)
+		if err != nil {
+			t.Fatalf("NewRenderer(%s) failed: %v", format, err)
+		}
+		if out := renderer.GenerateDocumentation("storage", resources); !strings.Contains(out, want) {
+			t.Errorf("%s output should contain %q, got:\n%s", format, want, out)
+		}
+	}
+}
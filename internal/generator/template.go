@@ -0,0 +1,257 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/toozej/terranotate/internal/parser"
+	"github.com/toozej/terranotate/internal/validator"
+)
+
+// TemplateData is the data model passed to user-supplied documentation templates
+type TemplateData struct {
+	Title     string                     // Module or file name documentation is generated for
+	Modules   []TemplateModule           // Resources grouped by directory
+	Files     []TemplateFile             // Resources grouped by file
+	Resources []TemplateResource         // Every resource, sorted by type then name
+	Interface *InterfaceSection          // Inputs, outputs, requirements and module calls; nil when there are none
+	Schema    validator.ValidationSchema // The loaded schema
+	Summary   TemplateSummary
+}
+
+// TemplateModule groups the resources of a single directory
+type TemplateModule struct {
+	Name      string // Directory base name
+	Path      string // Directory path
+	Files     []TemplateFile
+	Resources []TemplateResource
+}
+
+// TemplateFile groups the resources of a single file
+type TemplateFile struct {
+	Name      string // File base name
+	Path      string // File path
+	Resources []TemplateResource
+}
+
+// TemplateResource documents a single resource. ResourceEntry supplies Type, Name, Line,
+// Description, Fields (schema field values) and Annotations (every parsed field by prefix).
+type TemplateResource struct {
+	ResourceEntry
+	File         string   // Path of the file the resource is defined in
+	Module       string   // Directory of the file
+	SchemaFields []string // Documented schema fields for the resource type ("prefix:field")
+	Valid        bool     // Whether the resource passes schema validation
+	Errors       []string // Validation error messages
+	Warnings     []string // Validation warning messages
+	Resource     parser.TerraformResource
+}
+
+// TemplateSummary counts resources by validation status
+type TemplateSummary struct {
+	Total    int
+	Valid    int
+	Invalid  int
+	Types    int
+	Coverage Coverage // Annotation coverage across every resource
+}
+
+// TemplateFuncs are the helper functions available to documentation templates
+var TemplateFuncs = template.FuncMap{
+	"field":    templateField,
+	"nested":   templateNested,
+	"join":     templateJoin,
+	"mdEscape": MarkdownEscape,
+}
+
+// TemplateGenerator renders documentation with a user-supplied text/template
+type TemplateGenerator struct {
+	baseGenerator
+	tmpl *template.Template
+}
+
+// NewTemplateGenerator parses a documentation template
+func NewTemplateGenerator(schema validator.ValidationSchema, name, text string) (*TemplateGenerator, error) {
+	tmpl, err := template.New(name).Funcs(TemplateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	return &TemplateGenerator{baseGenerator: baseGenerator{schema: schema}, tmpl: tmpl}, nil
+}
+
+// GenerateDocumentation renders the template for the given resources. Execution errors are
+// reported in the output; use Render to handle them.
+func (tg *TemplateGenerator) GenerateDocumentation(moduleName string, resources []parser.TerraformResource) string {
+	output, err := tg.Render(moduleName, resources)
+	if err != nil {
+		return fmt.Sprintf("%s\n<!-- %s -->\n", output, err)
+	}
+	return output
+}
+
+// Render renders the template for the given resources
+func (tg *TemplateGenerator) Render(moduleName string, resources []parser.TerraformResource) (string, error) {
+	data, err := tg.BuildTemplateData(moduleName, resources)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tg.tmpl.Execute(&buf, data); err != nil {
+		return buf.String(), fmt.Errorf("failed to execute template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// BuildTemplateData builds the template data model for the given resources
+func (tg *TemplateGenerator) BuildTemplateData(moduleName string, resources []parser.TerraformResource) (TemplateData, error) {
+	if tg.validator == nil {
+		if err := tg.EnableValidation(); err != nil {
+			return TemplateData{}, err
+		}
+	}
+
+	data := TemplateData{Title: moduleName, Schema: tg.schema, Interface: tg.buildInterface()}
+
+	sorted := append([]parser.TerraformResource{}, resources...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Type != sorted[j].Type {
+			return sorted[i].Type < sorted[j].Type
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	types := make(map[string]bool)
+	for _, resource := range sorted {
+		columns := tg.getColumns(resource.Type)
+		entry := TemplateResource{
+			ResourceEntry: tg.buildEntry(resource, columns),
+			File:          resource.File,
+			Module:        filepath.Dir(resource.File),
+			SchemaFields:  columns,
+			Resource:      resource,
+		}
+
+		entry.Valid = entry.Validation.Passed
+		entry.Errors = entry.Validation.Errors
+		entry.Warnings = entry.Validation.Warnings
+		data.Summary.Coverage.add(entry.Validation)
+
+		data.Resources = append(data.Resources, entry)
+		data.Summary.Total++
+		if entry.Valid {
+			data.Summary.Valid++
+		} else {
+			data.Summary.Invalid++
+		}
+		types[resource.Type] = true
+	}
+	data.Summary.Types = len(types)
+
+	data.Files = groupTemplateFiles(data.Resources)
+	moduleIndex := make(map[string]int)
+	for _, file := range data.Files {
+		dir := filepath.Dir(file.Path)
+		index, exists := moduleIndex[dir]
+		if !exists {
+			index = len(data.Modules)
+			moduleIndex[dir] = index
+			data.Modules = append(data.Modules, TemplateModule{Name: filepath.Base(dir), Path: dir})
+		}
+		data.Modules[index].Files = append(data.Modules[index].Files, file)
+		data.Modules[index].Resources = append(data.Modules[index].Resources, file.Resources...)
+	}
+	sort.SliceStable(data.Modules, func(i, j int) bool { return data.Modules[i].Path < data.Modules[j].Path })
+
+	return data, nil
+}
+
+// groupTemplateFiles groups resources by file, sorted by path
+func groupTemplateFiles(resources []TemplateResource) []TemplateFile {
+	byPath := make(map[string][]TemplateResource)
+	var paths []string
+	for _, resource := range resources {
+		if _, exists := byPath[resource.File]; !exists {
+			paths = append(paths, resource.File)
+		}
+		byPath[resource.File] = append(byPath[resource.File], resource)
+	}
+	sort.Strings(paths)
+
+	files := make([]TemplateFile, 0, len(paths))
+	for _, path := range paths {
+		files = append(files, TemplateFile{Name: filepath.Base(path), Path: path, Resources: byPath[path]})
+	}
+	return files
+}
+
+// templateField returns a field value for "prefix:field" or "field" (any prefix), or "" if unset
+func templateField(resource TemplateResource, name string) string {
+	prefix, field := "", name
+	if parts := strings.SplitN(name, ":", 2); len(parts) == 2 {
+		prefix, field = parts[0], parts[1]
+	}
+
+	for _, p := range sortedPrefixes(resource.Annotations) {
+		if prefix != "" && p != prefix {
+			continue
+		}
+		if value := resource.Resource.GetNestedField(p, field); value != nil {
+			return fmt.Sprintf("%v", value)
+		}
+	}
+	return ""
+}
+
+// templateNested returns the value at a dotted path under a prefix, or "" if unset
+func templateNested(resource TemplateResource, prefix, path string) string {
+	if value := resource.Resource.GetNestedField(prefix, path); value != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return ""
+}
+
+// templateJoin joins a list with a separator; the list comes last so it can be piped
+func templateJoin(sep string, items interface{}) string {
+	switch list := items.(type) {
+	case []string:
+		return strings.Join(list, sep)
+	case []interface{}:
+		parts := make([]string, 0, len(list))
+		for _, item := range list {
+			parts = append(parts, fmt.Sprintf("%v", item))
+		}
+		return strings.Join(parts, sep)
+	case nil:
+		return ""
+	}
+	return fmt.Sprintf("%v", items)
+}
+
+// sortedPrefixes returns the annotation prefixes of a resource in sorted order
+func sortedPrefixes(annotations map[string]map[string]interface{}) []string {
+	prefixes := make([]string, 0, len(annotations))
+	for prefix := range annotations {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+	return prefixes
+}
+
+// markdownReplacer escapes the characters that break Markdown table rows, leaving any
+// Markdown written in values intact
+var markdownReplacer = strings.NewReplacer(
+	"|", `\|`,
+	"\r\n", " ",
+	"\r", " ",
+	"\n", " ",
+)
+
+// MarkdownEscape escapes text for use in Markdown, including inside table cells
+func MarkdownEscape(s string) string {
+	return markdownReplacer.Replace(s)
+}
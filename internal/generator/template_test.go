@@ -0,0 +1,90 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/toozej/terranotate/internal/parser"
+	"github.com/toozej/terranotate/internal/validator"
+)
+
+func TestTemplateGenerator(t *testing.T) {
+	schema := validator.ValidationSchema{
+		Global: validator.GlobalRules{
+			RequiredPrefixes: []string{"@metadata"},
+			PrefixRules: map[string]validator.PrefixRule{
+				"@metadata": {RequiredFields: []string{"owner"}},
+			},
+		},
+	}
+
+	resources := []parser.TerraformResource{
+		{
+			Type: "aws_vpc",
+			Name: "main",
+			File: "infra/network/main.tf",
+			PrecedingComments: []parser.StructuredComment{
+				{Prefix: "@metadata", Fields: map[string]interface{}{
+					"owner":   "team|net",
+					"contact": map[string]interface{}{"email": "net@example.com"},
+					"tags":    []interface{}{"a", "b"},
+				}},
+			},
+		},
+		{
+			Type: "aws_instance",
+			Name: "web",
+			File: "infra/compute/main.tf",
+		},
+	}
+
+	text := `{{.Title}}: {{.Summary.Valid}}/{{.Summary.Total}} valid
+{{range .Modules}}[{{.Name}}]{{range .Resources}} {{.Type}}.{{.Name}} owner={{field . "@metadata:owner" | mdEscape}} any={{field . "owner"}} email={{nested . "@metadata" "contact.email"}} tags={{join "," (index .Annotations "@metadata").tags}} valid={{.Valid}} errors={{join "; " .Errors}}{{end}}
+{{end}}`
+
+	gen, err := NewTemplateGenerator(schema, "test.tmpl", text)
+	if err != nil {
+		t.Fatalf("NewTemplateGenerator() failed: %v", err)
+	}
+
+	output, err := gen.Render("infra", resources)
+	if err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+
+	expected := `infra: 1/2 valid
+[compute] aws_instance.web owner= any= email= tags= valid=false errors=Missing required comment prefix: @metadata
+[network] aws_vpc.main owner=team\|net any=team|net email=net@example.com tags=a,b valid=true errors=
+`
+	if output != expected {
+		t.Errorf("Render() =\n%s\nwant\n%s", output, expected)
+	}
+
+	if _, err := NewTemplateGenerator(schema, "bad.tmpl", "{{.Title"); err == nil {
+		t.Error("NewTemplateGenerator() should fail for invalid template")
+	}
+
+	bad, err := NewTemplateGenerator(schema, "bad.tmpl", "{{.Missing}}")
+	if err != nil {
+		t.Fatalf("NewTemplateGenerator() failed: %v", err)
+	}
+	if _, err := bad.Render("infra", resources); err == nil {
+		t.Error("Render() should fail for unknown fields")
+	}
+}
+
+func TestMarkdownEscape(t *testing.T) {
+	tests := map[string]string{
+		"plain":              "plain",
+		"a|b":                `a\|b`,
+		"line1\nline2":       "line1 line2",
+		"line1\r\nline2":     "line1 line2",
+		"cost_center":        "cost_center",
+		"[automation *bot*]": "[automation *bot*]",
+		"`code` back\\":      "`code` back\\",
+	}
+	for input, want := range tests {
+		if got := MarkdownEscape(input); got != want {
+			t.Errorf("MarkdownEscape(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+// Package inherit resolves the annotations resources inherit from the module calls leading to
+// them and from directory defaults
+package inherit
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+	"github.com/toozej/terranotate/internal/modules"
+	"github.com/toozej/terranotate/internal/parser"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultsFile is the name of the file holding a directory's default annotations
+const DefaultsFile = ".terranotate.yaml"
+
+// Resolver finds the annotations inherited by the resources of a file
+type Resolver struct {
+	fs       afero.Fs
+	parser   *parser.CommentParser
+	modules  *modules.Index
+	calls    map[string]map[string]parser.ModuleCall // Module calls by directory and name
+	defaults map[string][]parser.StructuredComment   // Default annotations by directory
+}
+
+// New creates a resolver. The parser parses module calls and directory defaults, so its
+// prefixes and declared types apply to inherited annotations too.
+func New(fs afero.Fs, p *parser.CommentParser, index *modules.Index) *Resolver {
+	return &Resolver{
+		fs:       fs,
+		parser:   p,
+		modules:  index,
+		calls:    make(map[string]map[string]parser.ModuleCall),
+		defaults: make(map[string][]parser.StructuredComment),
+	}
+}
+
+// Annotations returns the annotations inherited by the resources of a file, highest precedence
+// first: those on the module calls leading to the file's module, innermost call first, then the
+// defaults of its directory and each parent directory up to the repository root, closest first
+func (r *Resolver) Annotations(filename string) []parser.StructuredComment {
+	dir := filepath.Dir(filepath.Clean(filename))
+
+	var inherited []parser.StructuredComment
+
+	if r.modules != nil {
+		chain := r.modules.Chain(dir)
+		for i := len(chain) - 1; i >= 0; i-- {
+			call, exists := r.moduleCalls(chain[i].Dir)[chain[i].Name]
+			if !exists {
+				continue
+			}
+			address := "module." + call.Name
+			if parent := r.modules.Address(chain[i].Dir); parent != "" {
+				address = parent + "." + address
+			}
+			for _, comment := range call.Comments {
+				comment.Source = fmt.Sprintf("module call %s (%s:%d)", address, call.File, comment.Line)
+				inherited = append(inherited, comment)
+			}
+		}
+	}
+
+	current := dir
+	if abs, err := filepath.Abs(dir); err == nil {
+		current = abs
+	}
+	for {
+		inherited = append(inherited, r.directoryDefaults(current)...)
+
+		// Defaults stop at the repository root
+		if exists, _ := afero.Exists(r.fs, filepath.Join(current, ".git")); exists {
+			break
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			break
+		}
+		current = parent
+	}
+
+	return inherited
+}
+
+// moduleCalls returns the module calls of the Terraform files in a directory, by name
+func (r *Resolver) moduleCalls(dir string) map[string]parser.ModuleCall {
+	if calls, exists := r.calls[dir]; exists {
+		return calls
+	}
+
+	calls := make(map[string]parser.ModuleCall)
+	files, _ := afero.Glob(r.fs, filepath.Join(dir, "*.tf"))
+	for _, file := range files {
+		parsed, err := r.parser.ParseModuleCalls(file)
+		if err != nil {
+			continue // Reported when the file is parsed for its resources
+		}
+		for _, call := range parsed {
+			calls[call.Name] = call
+		}
+	}
+
+	r.calls[dir] = calls
+	return calls
+}
+
+// directoryDefaults returns the default annotations declared in a directory's DefaultsFile:
+//
+//	annotations:
+//	  "@metadata":
+//	    team: networking
+//	    contact:
+//	      email: networking@example.com
+func (r *Resolver) directoryDefaults(dir string) []parser.StructuredComment {
+	if defaults, exists := r.defaults[dir]; exists {
+		return defaults
+	}
+
+	var defaults []parser.StructuredComment
+	path := filepath.Join(dir, DefaultsFile)
+	if data, err := afero.ReadFile(r.fs, path); err == nil {
+		defaults, err = r.parseDefaults(path, data)
+		if err != nil {
+			log.Printf("Warning: Failed to load %s: %v", path, err)
+		}
+	}
+
+	r.defaults[dir] = defaults
+	return defaults
+}
+
+// parseDefaults parses the annotations section of a defaults file, one annotation per prefix in
+// the order they are written
+func (r *Resolver) parseDefaults(path string, data []byte) ([]parser.StructuredComment, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("expected a mapping")
+	}
+
+	var defaults []parser.StructuredComment
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != "annotations" {
+			continue
+		}
+		annotations := root.Content[i+1]
+		if annotations.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("line %d: annotations must map prefixes to fields", annotations.Line)
+		}
+
+		for j := 0; j+1 < len(annotations.Content); j += 2 {
+			prefix := annotations.Content[j].Value
+			annotation, err := r.parser.ParseYAMLAnnotation(prefix, annotations.Content[j+1], fmt.Sprintf("directory defaults (%s)", path))
+			if err != nil {
+				return nil, err
+			}
+			defaults = append(defaults, annotation)
+		}
+	}
+
+	return defaults, nil
+}
@@ -0,0 +1,122 @@
+package inherit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/toozej/terranotate/internal/modules"
+	"github.com/toozej/terranotate/internal/parser"
+)
+
+// newParser writes the files and returns a parser that resolves inherited annotations
+func newParser(t *testing.T, files map[string]string) *parser.CommentParser {
+	t.Helper()
+	fs := afero.NewMemMapFs()
+	var tfFiles []string
+	for path, content := range files {
+		if err := afero.WriteFile(fs, path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+		if strings.HasSuffix(path, ".tf") {
+			tfFiles = append(tfFiles, path)
+		}
+	}
+
+	p := parser.NewCommentParser(fs, []string{"@metadata", "@docs"})
+	index := modules.Resolve(fs, tfFiles)
+	p.SetModuleResolver(index.Address)
+	p.SetInheritanceResolver(New(fs, p, index).Annotations)
+	return p
+}
+
+func TestAnnotationsPrecedence(t *testing.T) {
+	p := newParser(t, map[string]string{
+		"/repo/.git/HEAD": "ref: refs/heads/main",
+		"/repo/.terranotate.yaml": `
+annotations:
+  "@metadata":
+    team: platform
+    cost_center: 100
+    zip: "01234"
+    regions: [us-east-1, us-west-2]
+    contact:
+      email: platform@example.com
+`,
+		"/repo/live/main.tf": `
+# @metadata team:networking contact.slack:#net
+module "vpc" {
+  source = "../modules/vpc"
+}
+`,
+		"/repo/modules/vpc/.terranotate.yaml": `
+annotations:
+  "@metadata":
+    cost_center: 200
+  "@docs":
+    description: VPC module
+`,
+		"/repo/modules/vpc/main.tf": `
+# @metadata(file) tier:core
+
+# @metadata owner:alice
+resource "aws_vpc" "main" {}
+`,
+	})
+
+	resources, err := p.ParseFile("/repo/modules/vpc/main.tf")
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	vpc := resources[0]
+
+	if vpc.Address() != "module.vpc.aws_vpc.main" {
+		t.Errorf("Address() = %q", vpc.Address())
+	}
+
+	expected := map[string]struct {
+		value  interface{}
+		source string
+	}{
+		"owner":         {"alice", ""},
+		"tier":          {"core", "file header (/repo/modules/vpc/main.tf:2)"},
+		"team":          {"networking", "module call module.vpc (/repo/live/main.tf:2)"},
+		"contact.slack": {"#net", "module call module.vpc (/repo/live/main.tf:2)"},
+		"cost_center":   {200, "directory defaults (/repo/modules/vpc/.terranotate.yaml)"},
+		"zip":           {"01234", "directory defaults (/repo/.terranotate.yaml)"},
+		"contact.email": {"platform@example.com", "directory defaults (/repo/.terranotate.yaml)"},
+	}
+	annotation, _ := vpc.GetAnnotation("@metadata")
+	for path, want := range expected {
+		if got := vpc.GetNestedField("@metadata", path); got != want.value {
+			t.Errorf("Effective %s = %#v, expected %#v", path, got, want.value)
+		}
+		if got := annotation.Sources[path]; got != want.source {
+			t.Errorf("Source of %s = %q, expected %q", path, got, want.source)
+		}
+	}
+
+	regions, ok := vpc.GetNestedField("@metadata", "regions").([]interface{})
+	if !ok || len(regions) != 2 || regions[1] != "us-west-2" {
+		t.Errorf("Expected regions to be inherited as an array, got %#v", vpc.GetNestedField("@metadata", "regions"))
+	}
+	if got := vpc.GetNestedField("@docs", "description"); got != "VPC module" {
+		t.Errorf("Expected @docs to be inherited from directory defaults, got %v", got)
+	}
+}
+
+func TestAnnotationsStopAtRepositoryRoot(t *testing.T) {
+	p := newParser(t, map[string]string{
+		"/.terranotate.yaml":      "annotations: {\"@metadata\": {team: outside}}",
+		"/repo/.git/HEAD":         "ref: refs/heads/main",
+		"/repo/modules/x/main.tf": `resource "aws_vpc" "main" {}`,
+	})
+
+	resources, err := p.ParseFile("/repo/modules/x/main.tf")
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	if len(resources[0].Inherited) != 0 {
+		t.Errorf("Expected defaults outside the repository to be ignored, got %+v", resources[0].Inherited)
+	}
+}
@@ -0,0 +1,304 @@
+// Package journal records the files changed by terranotate runs so they can be reverted exactly.
+//
+// Each run is stored as a single JSON document under .terranotate/journal/ at the
+// root of the enclosing git repository (or the target directory when not in a
+// repository). A run records, for every changed file, the original content, hashes
+// of the original and updated content and the line edits that were applied.
+package journal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/toozej/terranotate/internal/diff"
+	"github.com/toozej/terranotate/internal/repo"
+)
+
+// Dir is the journal directory relative to the journal root
+const Dir = ".terranotate/journal"
+
+// Run records every file changed by a single command invocation
+type Run struct {
+	ID        string       `json:"id"`
+	Command   string       `json:"command"`
+	CreatedAt time.Time    `json:"created_at"`
+	Files     []FileChange `json:"files"`
+}
+
+// FileChange records a single file changed by a run
+type FileChange struct {
+	Path         string      `json:"path"` // Relative to the journal root
+	OriginalHash string      `json:"original_hash"`
+	UpdatedHash  string      `json:"updated_hash"`
+	Original     string      `json:"original"`
+	Edits        []diff.Edit `json:"edits"`
+	Created      bool        `json:"created,omitempty"` // The run created the file
+	Reverted     bool        `json:"reverted,omitempty"`
+}
+
+// Reverted reports whether every file in the run has been reverted
+func (r *Run) Reverted() bool {
+	for _, change := range r.Files {
+		if !change.Reverted {
+			return false
+		}
+	}
+	return true
+}
+
+// Journal stores fix runs under a single root directory
+type Journal struct {
+	fs   afero.Fs
+	root string
+}
+
+// New creates a journal for the given path, rooted at the enclosing git repository
+// if there is one, otherwise at the path's directory
+func New(fs afero.Fs, path string) (*Journal, error) {
+	if fs == nil {
+		fs = afero.NewOsFs()
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve path: %w", err)
+	}
+
+	start := absPath
+	if info, err := fs.Stat(absPath); err == nil && !info.IsDir() {
+		start = filepath.Dir(absPath)
+	}
+
+	root, ok := repo.Root(fs, start)
+	if !ok {
+		root = start
+	}
+	return &Journal{fs: fs, root: root}, nil
+}
+
+// Root returns the directory the journal is stored under
+func (j *Journal) Root() string {
+	return j.root
+}
+
+// NewRun starts a new run for the given command
+func (j *Journal) NewRun(command string) *Run {
+	now := time.Now().UTC()
+	return &Run{
+		ID:        now.Format("20060102T150405Z"),
+		Command:   command,
+		CreatedAt: now,
+	}
+}
+
+// Record adds a changed file to the run
+func (j *Journal) Record(run *Run, path string, original, updated []byte) error {
+	relPath, err := j.relativePath(path)
+	if err != nil {
+		return err
+	}
+
+	run.Files = append(run.Files, FileChange{
+		Path:         relPath,
+		OriginalHash: Hash(original),
+		UpdatedHash:  Hash(updated),
+		Original:     string(original),
+		Edits:        diff.Lines(string(original), string(updated)),
+	})
+	return nil
+}
+
+// RecordCreated adds a file created by the run, which reverting removes
+func (j *Journal) RecordCreated(run *Run, path string, content []byte) error {
+	if err := j.Record(run, path, nil, content); err != nil {
+		return err
+	}
+	run.Files[len(run.Files)-1].Created = true
+	return nil
+}
+
+// Save writes the run to the journal and returns the journal file path
+func (j *Journal) Save(run *Run) (string, error) {
+	dir := filepath.Join(j.root, Dir)
+	if err := j.fs.MkdirAll(dir, 0750); err != nil {
+		return "", fmt.Errorf("failed to create journal directory: %w", err)
+	}
+
+	// Runs started within the same second get a numeric suffix
+	baseID := run.ID
+	for i := 2; ; i++ {
+		exists, err := afero.Exists(j.fs, j.runPath(run.ID))
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			break
+		}
+		run.ID = fmt.Sprintf("%s-%d", baseID, i)
+	}
+
+	return j.runPath(run.ID), j.write(run)
+}
+
+// List returns all runs in the journal, oldest first
+func (j *Journal) List() ([]*Run, error) {
+	entries, err := afero.ReadDir(j.fs, filepath.Join(j.root, Dir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	var runs []*Run
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		run, err := j.Load(strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	sort.Slice(runs, func(a, b int) bool { return runs[a].CreatedAt.Before(runs[b].CreatedAt) })
+	return runs, nil
+}
+
+// Load reads a single run from the journal
+func (j *Journal) Load(id string) (*Run, error) {
+	data, err := afero.ReadFile(j.fs, j.runPath(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal run %s: %w", id, err)
+	}
+
+	var run Run
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("failed to parse journal run %s: %w", id, err)
+	}
+	return &run, nil
+}
+
+// Latest returns the most recent run that still has unreverted files under path
+func (j *Journal) Latest(path string) (*Run, error) {
+	runs, err := j.List()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(runs) - 1; i >= 0; i-- {
+		for _, change := range runs[i].Files {
+			if !change.Reverted && j.isUnder(change.Path, path) {
+				return runs[i], nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("no fix runs to revert under %s", path)
+}
+
+// RevertResult describes the outcome of reverting a run
+type RevertResult struct {
+	Reverted []string // Files restored to their original content
+	Skipped  []string // Files not restored because they changed after the run
+}
+
+// Revert restores the original content of every file under path changed by the run.
+// Files modified since the run are left untouched and reported as skipped.
+func (j *Journal) Revert(run *Run, path string) (RevertResult, error) {
+	var result RevertResult
+
+	// Undo changes newest first, since a run may change a file more than once
+	for i := len(run.Files) - 1; i >= 0; i-- {
+		change := &run.Files[i]
+		if change.Reverted || !j.isUnder(change.Path, path) {
+			continue
+		}
+
+		fullPath := filepath.Join(j.root, change.Path)
+		current, err := afero.ReadFile(j.fs, fullPath)
+		if err != nil || Hash(current) != change.UpdatedHash {
+			result.Skipped = append(result.Skipped, fullPath)
+			continue
+		}
+
+		if change.Created {
+			if err := j.fs.Remove(fullPath); err != nil {
+				return result, fmt.Errorf("failed to remove %s: %w", fullPath, err)
+			}
+			change.Reverted = true
+			result.Reverted = append(result.Reverted, fullPath)
+			continue
+		}
+
+		// #nosec G306 - Restoring source code (Terraform), 0644 is appropriate
+		if err := afero.WriteFile(j.fs, fullPath, []byte(change.Original), 0644); err != nil {
+			return result, fmt.Errorf("failed to restore %s: %w", fullPath, err)
+		}
+		change.Reverted = true
+		result.Reverted = append(result.Reverted, fullPath)
+	}
+
+	if err := j.write(run); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// Hash returns the hex-encoded SHA-256 of content
+func Hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// write stores the run under its ID
+func (j *Journal) write(run *Run) error {
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode journal run: %w", err)
+	}
+
+	// #nosec G306 - Journal contains source code already readable in the repository
+	if err := afero.WriteFile(j.fs, j.runPath(run.ID), data, 0644); err != nil {
+		return fmt.Errorf("failed to write journal run: %w", err)
+	}
+	return nil
+}
+
+// runPath returns the journal file for a run ID
+func (j *Journal) runPath(id string) string {
+	return filepath.Join(j.root, Dir, id+".json")
+}
+
+// relativePath converts a file path to a path relative to the journal root
+func (j *Journal) relativePath(path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path: %w", err)
+	}
+
+	relPath, err := filepath.Rel(j.root, absPath)
+	if err != nil || strings.HasPrefix(relPath, "..") {
+		return "", fmt.Errorf("%s is outside the journal root %s", path, j.root)
+	}
+	return relPath, nil
+}
+
+// isUnder reports whether a journal-relative path is the given path or inside it
+func (j *Journal) isUnder(relPath, path string) bool {
+	target, err := j.relativePath(path)
+	if err != nil {
+		return false
+	}
+	return target == "." || relPath == target || strings.HasPrefix(relPath, target+string(filepath.Separator))
+}
@@ -0,0 +1,119 @@
+package journal
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestJournal(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	if err := fs.MkdirAll("/repo/.git", 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := afero.WriteFile(fs, "/repo/modules/vpc/main.tf", []byte("fixed"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	j, err := New(fs, "/repo/modules/vpc/main.tf")
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if j.Root() != "/repo" {
+		t.Errorf("Expected journal root /repo, got %s", j.Root())
+	}
+
+	run := j.NewRun("fix")
+	if err := j.Record(run, "/repo/modules/vpc/main.tf", []byte("original"), []byte("fixed")); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if err := j.Record(run, "/elsewhere/main.tf", []byte("a"), []byte("b")); err == nil {
+		t.Error("Expected error recording a file outside the journal root")
+	}
+
+	change := run.Files[0]
+	if change.Path != "modules/vpc/main.tf" || change.OriginalHash != Hash([]byte("original")) || len(change.Edits) != 2 {
+		t.Errorf("Unexpected file change: %+v", change)
+	}
+
+	first, err := j.Save(run)
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// A second run in the same second gets a distinct ID
+	second := j.NewRun("fix")
+	second.ID = run.ID
+	if _, err := j.Save(second); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if second.ID == run.ID {
+		t.Errorf("Expected distinct run IDs, both were %s", run.ID)
+	}
+	if first != "/repo/.terranotate/journal/"+run.ID+".json" {
+		t.Errorf("Unexpected journal path: %s", first)
+	}
+
+	runs, err := j.List()
+	if err != nil || len(runs) != 2 {
+		t.Fatalf("Expected 2 runs, got %d (%v)", len(runs), err)
+	}
+
+	// Second run changed no files, so the latest revertible run is the first
+	latest, err := j.Latest("/repo/modules")
+	if err != nil || latest.ID != run.ID {
+		t.Fatalf("Expected latest run %s, got %v (%v)", run.ID, latest, err)
+	}
+
+	result, err := j.Revert(latest, "/repo")
+	if err != nil {
+		t.Fatalf("Revert failed: %v", err)
+	}
+	if len(result.Reverted) != 1 || len(result.Skipped) != 0 {
+		t.Errorf("Unexpected revert result: %+v", result)
+	}
+
+	content, _ := afero.ReadFile(fs, "/repo/modules/vpc/main.tf")
+	if string(content) != "original" {
+		t.Errorf("Expected original content, got %q", string(content))
+	}
+
+	reloaded, err := j.Load(run.ID)
+	if err != nil || !reloaded.Reverted() {
+		t.Errorf("Expected run to be marked reverted (%v)", err)
+	}
+	if _, err := j.Latest("/repo"); err == nil {
+		t.Error("Expected no revertible runs after revert")
+	}
+}
+
+func TestRevertRemovesCreatedFiles(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/repo/modules/vpc/terranotate.yaml", []byte("resources: {}\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	j, err := New(fs, "/repo")
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	run := j.NewRun("fix")
+	if err := j.RecordCreated(run, "/repo/modules/vpc/terranotate.yaml", []byte("resources: {}\n")); err != nil {
+		t.Fatalf("RecordCreated failed: %v", err)
+	}
+	if _, err := j.Save(run); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	result, err := j.Revert(run, "/repo")
+	if err != nil {
+		t.Fatalf("Revert failed: %v", err)
+	}
+	if len(result.Reverted) != 1 {
+		t.Errorf("Unexpected revert result: %+v", result)
+	}
+	if exists, _ := afero.Exists(fs, "/repo/modules/vpc/terranotate.yaml"); exists {
+		t.Error("Expected the created file to be removed")
+	}
+}
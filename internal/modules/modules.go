@@ -0,0 +1,251 @@
+// Package modules resolves Terraform module calls so that resources can be identified by their
+// full address, e.g. module.vpc.module.subnets.aws_subnet.private
+package modules
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/spf13/afero"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ManifestPath is where terraform init records installed modules, relative to a root module
+var ManifestPath = filepath.Join(".terraform", "modules", "modules.json")
+
+// Call is a module block
+type Call struct {
+	Dir    string // Directory of the calling module
+	Name   string
+	Source string
+}
+
+// Index maps directories to the addresses of the module instances defined in them
+type Index struct {
+	fs        afero.Fs
+	calls     map[string][]Call   // Module calls by directory
+	instances map[string][]string // Module addresses by directory, canonical first
+	chains    map[string][]Call   // Calls leading to the canonical instance of a directory, outermost first
+}
+
+// manifest is the content of .terraform/modules/modules.json
+type manifest struct {
+	Modules []struct {
+		Key    string `json:"Key"`    // Dotted module call names from the root, e.g. "vpc.subnets"
+		Source string `json:"Source"` // Source as written in the module block
+		Dir    string `json:"Dir"`    // Directory the module was installed to, relative to the root
+	} `json:"Modules"`
+}
+
+// Resolve builds the module index for the directories of the given Terraform files. Local
+// module sources are followed directly; other sources are looked up in the root module's
+// .terraform/modules/modules.json. Directories no other directory calls are root modules.
+// A module called from several places has several addresses; the canonical one comes from
+// the first root module and call name in alphabetical order.
+func Resolve(fs afero.Fs, files []string) *Index {
+	idx := &Index{
+		fs:        fs,
+		calls:     make(map[string][]Call),
+		instances: make(map[string][]string),
+		chains:    make(map[string][]Call),
+	}
+
+	dirs := make(map[string]bool)
+	for _, file := range files {
+		dirs[filepath.Clean(filepath.Dir(file))] = true
+	}
+
+	// Directories reachable through local module calls or installed by terraform init are not
+	// root modules
+	called := make(map[string]bool)
+	visited := make(map[string]bool)
+	var explore func(dir string)
+	explore = func(dir string) {
+		if visited[dir] {
+			return
+		}
+		visited[dir] = true
+		for _, installedDir := range idx.loadManifest(dir) {
+			child := filepath.Join(dir, installedDir)
+			if child != dir {
+				called[child] = true
+				explore(child)
+			}
+		}
+		for _, call := range idx.callsOf(dir) {
+			if isLocal(call.Source) {
+				child := filepath.Join(dir, call.Source)
+				called[child] = true
+				explore(child)
+			}
+		}
+	}
+	for dir := range dirs {
+		explore(dir)
+	}
+
+	var roots []string
+	for dir := range dirs {
+		if !called[dir] {
+			roots = append(roots, dir)
+		}
+	}
+	sort.Strings(roots)
+
+	for _, root := range roots {
+		idx.walk(root, root, "", nil, nil, idx.loadManifest(root), make(map[string]bool))
+	}
+
+	// Directories only reachable through a cycle are treated as root modules
+	for dir := range dirs {
+		if _, exists := idx.instances[dir]; !exists {
+			idx.instances[dir] = []string{""}
+		}
+	}
+
+	return idx
+}
+
+// walk records the address of a module instance and follows its module calls
+func (idx *Index) walk(root, dir, address string, keys []string, chain []Call, installed map[string]string, visiting map[string]bool) {
+	if visiting[dir] {
+		return
+	}
+	visiting[dir] = true
+	defer delete(visiting, dir)
+
+	for _, existing := range idx.instances[dir] {
+		if existing == address {
+			return
+		}
+	}
+	if len(idx.instances[dir]) == 0 {
+		idx.chains[dir] = chain
+	}
+	idx.instances[dir] = append(idx.instances[dir], address)
+
+	for _, call := range idx.callsOf(dir) {
+		callKeys := append(append([]string{}, keys...), call.Name)
+
+		var child string
+		if installedDir, ok := installed[strings.Join(callKeys, ".")]; ok {
+			child = filepath.Join(root, installedDir)
+		} else if isLocal(call.Source) {
+			child = filepath.Join(dir, call.Source)
+		} else {
+			continue // Remote module that hasn't been installed
+		}
+
+		childAddress := "module." + call.Name
+		if address != "" {
+			childAddress = address + "." + childAddress
+		}
+		callChain := append(append([]Call{}, chain...), call)
+		idx.walk(root, child, childAddress, callKeys, callChain, installed, visiting)
+	}
+}
+
+// Address returns the canonical module address of a directory, or "" for root modules and
+// directories outside the index
+func (idx *Index) Address(dir string) string {
+	if instances := idx.instances[filepath.Clean(dir)]; len(instances) > 0 {
+		return instances[0]
+	}
+	return ""
+}
+
+// Chain returns the module calls leading to the canonical instance of a directory, outermost
+// first, or nil for root modules
+func (idx *Index) Chain(dir string) []Call {
+	return idx.chains[filepath.Clean(dir)]
+}
+
+// Instances returns every module address a directory is instantiated at
+func (idx *Index) Instances(dir string) []string {
+	return idx.instances[filepath.Clean(dir)]
+}
+
+// callsOf returns the module calls of the Terraform files in a directory, sorted by name
+func (idx *Index) callsOf(dir string) []Call {
+	if calls, exists := idx.calls[dir]; exists {
+		return calls
+	}
+
+	var calls []Call
+	files, _ := afero.Glob(idx.fs, filepath.Join(dir, "*.tf"))
+	for _, file := range files {
+		calls = append(calls, idx.parseCalls(file)...)
+	}
+	sort.Slice(calls, func(i, j int) bool { return calls[i].Name < calls[j].Name })
+
+	idx.calls[dir] = calls
+	return calls
+}
+
+// parseCalls returns the module blocks of a file with a literal source. Files that fail to
+// parse are skipped; parsing them again for their resources reports the error.
+func (idx *Index) parseCalls(file string) []Call {
+	f, err := idx.fs.Open(file)
+	if err != nil {
+		return nil
+	}
+	defer func() { _ = f.Close() }()
+
+	src, err := io.ReadAll(f)
+	if err != nil {
+		return nil
+	}
+
+	parsed, diags := hclsyntax.ParseConfig(src, file, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil
+	}
+
+	var calls []Call
+	for _, block := range parsed.Body.(*hclsyntax.Body).Blocks {
+		if block.Type != "module" || len(block.Labels) != 1 {
+			continue
+		}
+		attr, exists := block.Body.Attributes["source"]
+		if !exists {
+			continue
+		}
+		value, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() || value.Type() != cty.String || value.IsNull() {
+			continue
+		}
+		calls = append(calls, Call{Dir: filepath.Dir(file), Name: block.Labels[0], Source: value.AsString()})
+	}
+	return calls
+}
+
+// loadManifest returns the installed module directories of a root module by module key
+func (idx *Index) loadManifest(root string) map[string]string {
+	installed := make(map[string]string)
+
+	data, err := afero.ReadFile(idx.fs, filepath.Join(root, ManifestPath))
+	if err != nil {
+		return installed
+	}
+
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return installed
+	}
+	for _, module := range m.Modules {
+		if module.Key != "" {
+			installed[module.Key] = filepath.FromSlash(module.Dir)
+		}
+	}
+	return installed
+}
+
+// isLocal reports whether a module source is a local path
+func isLocal(source string) bool {
+	return strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../")
+}
@@ -0,0 +1,117 @@
+package modules
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func writeFiles(t *testing.T, fs afero.Fs, files map[string]string) []string {
+	t.Helper()
+	var paths []string
+	for path, content := range files {
+		if err := afero.WriteFile(fs, path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+		if filepath.Ext(path) == ".tf" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+func TestResolveLocalModules(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	files := writeFiles(t, fs, map[string]string{
+		"live/main.tf": `
+module "vpc" {
+  source = "../modules/vpc"
+}
+`,
+		"modules/vpc/main.tf": `
+module "subnets" {
+  source = "./subnets"
+}
+
+resource "aws_vpc" "main" {}
+`,
+		"modules/vpc/subnets/main.tf": `resource "aws_subnet" "private" {}`,
+	})
+
+	idx := Resolve(fs, files)
+
+	expected := map[string]string{
+		"live":                "",
+		"modules/vpc":         "module.vpc",
+		"modules/vpc/subnets": "module.vpc.module.subnets",
+		"elsewhere":           "",
+	}
+	for dir, address := range expected {
+		if got := idx.Address(dir); got != address {
+			t.Errorf("Address(%q) = %q, expected %q", dir, got, address)
+		}
+	}
+}
+
+func TestResolveInstalledModules(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	files := writeFiles(t, fs, map[string]string{
+		"live/main.tf": `
+module "network" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "5.0.0"
+}
+`,
+		"live/.terraform/modules/modules.json": `{"Modules":[
+  {"Key":"","Source":"","Dir":"."},
+  {"Key":"network","Source":"registry.terraform.io/terraform-aws-modules/vpc/aws","Dir":".terraform/modules/network"}
+]}`,
+		"live/.terraform/modules/network/main.tf": `resource "aws_vpc" "this" {}`,
+	})
+
+	idx := Resolve(fs, files)
+
+	if got := idx.Address("live/.terraform/modules/network"); got != "module.network" {
+		t.Errorf("installed module address = %q, expected %q", got, "module.network")
+	}
+	if got := idx.Address("live"); got != "" {
+		t.Errorf("root module address = %q, expected empty", got)
+	}
+}
+
+func TestResolveMultipleInstances(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	files := writeFiles(t, fs, map[string]string{
+		"prod/main.tf":        `module "web" { source = "../modules/app" }`,
+		"staging/main.tf":     `module "app" { source = "../modules/app" }`,
+		"modules/app/main.tf": `resource "aws_instance" "web" {}`,
+	})
+
+	idx := Resolve(fs, files)
+
+	expected := []string{"module.web", "module.app"}
+	if got := idx.Instances("modules/app"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Instances() = %v, expected %v", got, expected)
+	}
+	if got := idx.Address("modules/app"); got != "module.web" {
+		t.Errorf("Address() = %q, expected the first root's address %q", got, "module.web")
+	}
+}
+
+func TestResolveCycle(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	files := writeFiles(t, fs, map[string]string{
+		"a/main.tf": `module "b" { source = "../b" }`,
+		"b/main.tf": `module "a" { source = "../a" }`,
+	})
+
+	idx := Resolve(fs, files)
+
+	for _, dir := range []string{"a", "b"} {
+		if got := idx.Address(dir); got != "" {
+			t.Errorf("Address(%q) = %q, expected directories in a cycle to be root modules", dir, got)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+// Package owners aggregates ownership annotations into CODEOWNERS entries and ownership maps
+package owners
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Strategies for resolving conflicting owners within a file or directory
+const (
+	StrategyMajority = "majority" // The owner of the most resources wins, ties broken alphabetically
+	StrategyAll      = "all"      // Every owner is listed, most resources first
+)
+
+// Granularities ownership is aggregated at
+const (
+	ByDirectory = "directory"
+	ByFile      = "file"
+)
+
+// Output formats
+const (
+	FormatCODEOWNERS = "codeowners"
+	FormatJSON       = "json"
+	FormatYAML       = "yaml"
+)
+
+// Resource is the ownership of a single resource
+type Resource struct {
+	Address string      `json:"address" yaml:"address"`
+	File    string      `json:"file" yaml:"file"` // Slash-separated path relative to the repository root
+	Team    string      `json:"team,omitempty" yaml:"team,omitempty"`
+	Owner   string      `json:"owner,omitempty" yaml:"owner,omitempty"`
+	Contact interface{} `json:"contact,omitempty" yaml:"contact,omitempty"`
+}
+
+// Entry is a single CODEOWNERS rule
+type Entry struct {
+	Path      string   `json:"path" yaml:"path"`                               // CODEOWNERS pattern, e.g. "/modules/network/"
+	Owners    []string `json:"owners" yaml:"owners"`                           // Selected owners
+	Resources int      `json:"resources" yaml:"resources"`                     // Resources the rule covers
+	Conflicts []string `json:"conflicts,omitempty" yaml:"conflicts,omitempty"` // Owners annotated here but not selected
+	Override  bool     `json:"override,omitempty" yaml:"override,omitempty"`   // Owners come from a configured override
+}
+
+// Map is the aggregated ownership of a set of resources
+type Map struct {
+	Entries   []Entry    `json:"entries" yaml:"entries"`
+	Resources []Resource `json:"resources" yaml:"resources"`
+	Unowned   []string   `json:"unowned,omitempty" yaml:"unowned,omitempty"` // Addresses of resources without a team
+}
+
+// Options configures how ownership is aggregated
+type Options struct {
+	Org       string              // GitHub organization teams belong to
+	Strategy  string              // StrategyMajority (default) or StrategyAll
+	By        string              // ByDirectory (default) or ByFile
+	Overrides map[string][]string // Owners for module directories or files, replacing annotations there and below
+}
+
+// Handle converts a team annotation to a CODEOWNERS owner. Values that are already handles
+// ("@user", "@org/team") or email addresses are kept; others become "@org/team", or "@team"
+// when no organization is configured.
+func Handle(team, org string) string {
+	team = strings.TrimSpace(team)
+	if strings.Contains(team, "@") {
+		return team
+	}
+	if org != "" {
+		return "@" + strings.TrimPrefix(org, "@") + "/" + team
+	}
+	return "@" + team
+}
+
+// group collects the resources covered by one entry
+type group struct {
+	rel       string // Directory or file relative to the root; "" for the root directory
+	file      bool
+	resources int
+	counts    map[string]int
+}
+
+// Build aggregates resource ownership into CODEOWNERS entries
+func Build(resources []Resource, opts Options) (Map, error) {
+	if opts.Strategy == "" {
+		opts.Strategy = StrategyMajority
+	}
+	if opts.Strategy != StrategyMajority && opts.Strategy != StrategyAll {
+		return Map{}, fmt.Errorf("invalid strategy '%s' (expected '%s' or '%s')", opts.Strategy, StrategyMajority, StrategyAll)
+	}
+	if opts.By == "" {
+		opts.By = ByDirectory
+	}
+	if opts.By != ByDirectory && opts.By != ByFile {
+		return Map{}, fmt.Errorf("invalid --by value '%s' (expected '%s' or '%s')", opts.By, ByDirectory, ByFile)
+	}
+
+	m := Map{Resources: append([]Resource{}, resources...)}
+	sort.SliceStable(m.Resources, func(i, j int) bool {
+		if m.Resources[i].File != m.Resources[j].File {
+			return m.Resources[i].File < m.Resources[j].File
+		}
+		return m.Resources[i].Address < m.Resources[j].Address
+	})
+
+	overrides := normalizeOverrides(opts.Overrides)
+	groups := make(map[string]*group)
+	for _, resource := range m.Resources {
+		if resource.Team == "" {
+			m.Unowned = append(m.Unowned, resource.Address)
+		}
+
+		rel, isFile := resource.File, true
+		if opts.By == ByDirectory {
+			rel, isFile = path.Dir(resource.File), false
+			if rel == "." {
+				rel = ""
+			}
+		}
+		if key, ok := overrideFor(resource.File, overrides); ok {
+			rel, isFile = key, key == resource.File
+		}
+
+		g, exists := groups[rel]
+		if !exists {
+			g = &group{rel: rel, file: isFile, counts: make(map[string]int)}
+			groups[rel] = g
+		}
+		g.resources++
+		if resource.Team != "" {
+			g.counts[Handle(resource.Team, opts.Org)]++
+		}
+	}
+
+	for _, g := range groups {
+		entry := Entry{Path: pattern(g.rel, g.file), Resources: g.resources}
+		if owners, ok := overrides[g.rel]; ok {
+			entry.Override = true
+			for _, owner := range owners {
+				entry.Owners = append(entry.Owners, Handle(owner, opts.Org))
+			}
+		} else {
+			ranked := rank(g.counts)
+			if len(ranked) == 0 {
+				// Unowned paths fall back to the owners of their parent directory
+				continue
+			}
+			entry.Owners = ranked
+			if opts.Strategy == StrategyMajority {
+				entry.Owners, entry.Conflicts = ranked[:1], ranked[1:]
+			}
+		}
+		m.Entries = append(m.Entries, entry)
+	}
+
+	// CODEOWNERS applies the last matching rule, so parents must come before their children
+	sort.Slice(m.Entries, func(i, j int) bool { return m.Entries[i].Path < m.Entries[j].Path })
+
+	return m, nil
+}
+
+// normalizeOverrides cleans override paths so they compare against resource files
+func normalizeOverrides(overrides map[string][]string) map[string][]string {
+	normalized := make(map[string][]string, len(overrides))
+	for key, owners := range overrides {
+		key = strings.Trim(path.Clean("/"+strings.ReplaceAll(key, "\\", "/")), "/")
+		normalized[key] = owners
+	}
+	return normalized
+}
+
+// overrideFor returns the most specific override covering file
+func overrideFor(file string, overrides map[string][]string) (string, bool) {
+	best, found := "", false
+	for key := range overrides {
+		if key == "" || key == file || strings.HasPrefix(file, key+"/") {
+			if !found || len(key) > len(best) {
+				best, found = key, true
+			}
+		}
+	}
+	return best, found
+}
+
+// rank returns owners by number of resources owned, then alphabetically
+func rank(counts map[string]int) []string {
+	owners := make([]string, 0, len(counts))
+	for owner := range counts {
+		owners = append(owners, owner)
+	}
+	sort.Slice(owners, func(i, j int) bool {
+		if counts[owners[i]] != counts[owners[j]] {
+			return counts[owners[i]] > counts[owners[j]]
+		}
+		return owners[i] < owners[j]
+	})
+	return owners
+}
+
+// pattern returns the CODEOWNERS pattern for a directory or file relative to the root
+func pattern(rel string, file bool) string {
+	switch {
+	case rel == "":
+		return "*"
+	case file:
+		return "/" + rel
+	}
+	return "/" + rel + "/"
+}
+
+// CODEOWNERS renders the entries as a CODEOWNERS file
+func (m Map) CODEOWNERS() string {
+	var sb strings.Builder
+	sb.WriteString("# Generated by terranotate from @metadata annotations. Do not edit by hand.\n")
+	for _, entry := range m.Entries {
+		sb.WriteString("\n")
+		if entry.Override {
+			sb.WriteString("# Owners set by override\n")
+		}
+		if len(entry.Conflicts) > 0 {
+			fmt.Fprintf(&sb, "# Also annotated: %s\n", strings.Join(entry.Conflicts, " "))
+		}
+		fmt.Fprintf(&sb, "%s %s\n", entry.Path, strings.Join(entry.Owners, " "))
+	}
+	return sb.String()
+}
+
+// Render renders the ownership map in the given format
+func (m Map) Render(format string) (string, error) {
+	switch strings.ToLower(format) {
+	case "", FormatCODEOWNERS:
+		return m.CODEOWNERS(), nil
+	case FormatJSON:
+		data, err := json.MarshalIndent(m, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to encode ownership map: %w", err)
+		}
+		return string(data) + "\n", nil
+	case FormatYAML, "yml":
+		data, err := yaml.Marshal(m)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode ownership map: %w", err)
+		}
+		return string(data), nil
+	}
+	return "", fmt.Errorf("unsupported format '%s' (supported: %s, %s, %s)", format, FormatCODEOWNERS, FormatJSON, FormatYAML)
+}
@@ -0,0 +1,123 @@
+package owners
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestHandle(t *testing.T) {
+	tests := []struct {
+		team, org, expected string
+	}{
+		{"network", "", "@network"},
+		{"network", "acme", "@acme/network"},
+		{"network", "@acme", "@acme/network"},
+		{"@acme/platform", "acme", "@acme/platform"},
+		{"ops@example.com", "acme", "ops@example.com"},
+	}
+	for _, tt := range tests {
+		if got := Handle(tt.team, tt.org); got != tt.expected {
+			t.Errorf("Handle(%q, %q) = %q, expected %q", tt.team, tt.org, got, tt.expected)
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	resources := []Resource{
+		{Address: "aws_vpc.main", File: "modules/network/main.tf", Team: "network"},
+		{Address: "aws_subnet.a", File: "modules/network/subnets.tf", Team: "network"},
+		{Address: "aws_route.r", File: "modules/network/subnets.tf", Team: "platform"},
+		{Address: "aws_s3_bucket.logs", File: "modules/storage/main.tf", Team: "storage"},
+		{Address: "aws_iam_role.ci", File: "main.tf", Team: "platform"},
+		{Address: "aws_kms_key.k", File: "modules/storage/kms.tf"},
+	}
+
+	m, err := Build(resources, Options{Org: "acme"})
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	expected := []Entry{
+		{Path: "*", Owners: []string{"@acme/platform"}, Resources: 1},
+		{Path: "/modules/network/", Owners: []string{"@acme/network"}, Resources: 3, Conflicts: []string{"@acme/platform"}},
+		{Path: "/modules/storage/", Owners: []string{"@acme/storage"}, Resources: 2},
+	}
+	assertEntries(t, m.Entries, expected)
+	if len(m.Unowned) != 1 || m.Unowned[0] != "aws_kms_key.k" {
+		t.Errorf("Expected aws_kms_key.k to be unowned, got %v", m.Unowned)
+	}
+
+	// Every owner is listed, most resources first
+	m, _ = Build(resources, Options{Org: "acme", Strategy: StrategyAll})
+	if owners := m.Entries[1].Owners; strings.Join(owners, " ") != "@acme/network @acme/platform" || len(m.Entries[1].Conflicts) != 0 {
+		t.Errorf("Unexpected owners with strategy all: %+v", m.Entries[1])
+	}
+
+	// Per-file entries
+	m, _ = Build(resources, Options{By: ByFile})
+	if len(m.Entries) != 4 || m.Entries[0].Path != "/main.tf" || m.Entries[2].Path != "/modules/network/subnets.tf" {
+		t.Errorf("Unexpected per-file entries: %+v", m.Entries)
+	}
+
+	// A module-level override replaces annotations in and below the module
+	m, _ = Build(resources, Options{Org: "acme", Overrides: map[string][]string{"./modules/network/": {"netops", "@alice"}}})
+	if entry := m.Entries[1]; entry.Path != "/modules/network/" || !entry.Override || strings.Join(entry.Owners, " ") != "@acme/netops @alice" || len(entry.Conflicts) != 0 {
+		t.Errorf("Expected override for modules/network, got %+v", entry)
+	}
+
+	if _, err := Build(resources, Options{Strategy: "random"}); err == nil {
+		t.Error("Expected error for an invalid strategy")
+	}
+	if _, err := Build(resources, Options{By: "team"}); err == nil {
+		t.Error("Expected error for an invalid --by value")
+	}
+}
+
+func TestRender(t *testing.T) {
+	m, _ := Build([]Resource{
+		{Address: "aws_vpc.main", File: "net/main.tf", Team: "network", Owner: "jane.doe", Contact: map[string]interface{}{"email": "net@example.com"}},
+		{Address: "aws_subnet.a", File: "net/main.tf", Team: "platform"},
+	}, Options{Org: "acme"})
+
+	codeowners, err := m.Render(FormatCODEOWNERS)
+	if err != nil {
+		t.Fatalf("Render(codeowners) failed: %v", err)
+	}
+	if !strings.Contains(codeowners, "# Also annotated: @acme/platform\n/net/ @acme/network\n") {
+		t.Errorf("Unexpected CODEOWNERS:\n%s", codeowners)
+	}
+
+	data, err := m.Render(FormatJSON)
+	if err != nil {
+		t.Fatalf("Render(json) failed: %v", err)
+	}
+	var decoded Map
+	if err := json.Unmarshal([]byte(data), &decoded); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if decoded.Resources[1].Address != "aws_vpc.main" || decoded.Resources[1].Owner != "jane.doe" {
+		t.Errorf("Unexpected resources in ownership map: %+v", decoded.Resources)
+	}
+
+	yamlData, err := m.Render(FormatYAML)
+	if err != nil || !strings.Contains(yamlData, "email: net@example.com") {
+		t.Errorf("Unexpected YAML (err %v):\n%s", err, yamlData)
+	}
+
+	if _, err := m.Render("xml"); err == nil {
+		t.Error("Expected error for an unsupported format")
+	}
+}
+
+func assertEntries(t *testing.T, got, expected []Entry) {
+	t.Helper()
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d entries, got %d: %+v", len(expected), len(got), got)
+	}
+	for i := range expected {
+		g, e := got[i], expected[i]
+		if g.Path != e.Path || strings.Join(g.Owners, " ") != strings.Join(e.Owners, " ") || g.Resources != e.Resources || strings.Join(g.Conflicts, " ") != strings.Join(e.Conflicts, " ") {
+			t.Errorf("Entry %d = %+v, expected %+v", i, g, e)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package parser
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/spf13/afero"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ModuleInterface is what a module exposes to its callers: its input variables, outputs,
+// Terraform and provider requirements, and the modules it calls
+type ModuleInterface struct {
+	RequiredVersion string // Terraform version constraint from terraform { required_version }
+	Providers       []ProviderRequirement
+	Variables       []Variable
+	Outputs         []Output
+	ModuleCalls     []ModuleCall
+}
+
+// ProviderRequirement is an entry of terraform { required_providers }
+type ProviderRequirement struct {
+	Name    string
+	Source  string // e.g. "hashicorp/aws"; "" when not declared
+	Version string // Version constraint; "" when not declared
+}
+
+// Variable is a variable block with the annotations written on it
+type Variable struct {
+	Name        string
+	File        string
+	StartLine   int
+	EndLine     int
+	Type        string // Type constraint source text; "" when not declared
+	Default     string // Default value source text; "" when there is no default
+	HasDefault  bool   // Whether a default is declared, which makes the variable optional
+	Description string
+	Sensitive   bool
+	Comments    []StructuredComment // Comments directly above the block or inside it
+}
+
+// Output is an output block with the annotations written on it
+type Output struct {
+	Name        string
+	File        string
+	StartLine   int
+	EndLine     int
+	Description string
+	Sensitive   bool
+	Comments    []StructuredComment // Comments directly above the block or inside it
+}
+
+// GetAnnotation merges the variable's comments with a prefix
+func (v Variable) GetAnnotation(prefix string) (StructuredComment, bool) {
+	return annotation(v.Comments, prefix)
+}
+
+// GetAnnotation merges the output's comments with a prefix
+func (o Output) GetAnnotation(prefix string) (StructuredComment, bool) {
+	return annotation(o.Comments, prefix)
+}
+
+// GetAnnotation merges the module call's comments with a prefix
+func (m ModuleCall) GetAnnotation(prefix string) (StructuredComment, bool) {
+	return annotation(m.Comments, prefix)
+}
+
+// Merge adds the interface declared in another file of the same module. Variables, outputs,
+// providers and module calls are sorted by name, and the first declared required_version and
+// provider requirement win.
+func (m *ModuleInterface) Merge(other ModuleInterface) {
+	if m.RequiredVersion == "" {
+		m.RequiredVersion = other.RequiredVersion
+	}
+
+	for _, provider := range other.Providers {
+		exists := false
+		for _, existing := range m.Providers {
+			if existing.Name == provider.Name {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			m.Providers = append(m.Providers, provider)
+		}
+	}
+
+	m.Variables = append(m.Variables, other.Variables...)
+	m.Outputs = append(m.Outputs, other.Outputs...)
+	m.ModuleCalls = append(m.ModuleCalls, other.ModuleCalls...)
+
+	sort.SliceStable(m.Providers, func(i, j int) bool { return m.Providers[i].Name < m.Providers[j].Name })
+	sort.SliceStable(m.Variables, func(i, j int) bool { return m.Variables[i].Name < m.Variables[j].Name })
+	sort.SliceStable(m.Outputs, func(i, j int) bool { return m.Outputs[i].Name < m.Outputs[j].Name })
+	sort.SliceStable(m.ModuleCalls, func(i, j int) bool { return m.ModuleCalls[i].Name < m.ModuleCalls[j].Name })
+}
+
+// Empty reports whether the module declares no interface
+func (m ModuleInterface) Empty() bool {
+	return m.RequiredVersion == "" && len(m.Providers) == 0 && len(m.Variables) == 0 && len(m.Outputs) == 0 && len(m.ModuleCalls) == 0
+}
+
+// ParseInterface parses the variable, output, module and terraform blocks of a Terraform file,
+// in source order, with the annotations written on them
+func (cp *CommentParser) ParseInterface(filename string) (ModuleInterface, error) {
+	filename = filepath.Clean(filename)
+
+	src, err := afero.ReadFile(cp.fs, filename)
+	if err != nil {
+		return ModuleInterface{}, err
+	}
+
+	body, comments, err := cp.parseConfig(filename, src)
+	if err != nil {
+		return ModuleInterface{}, err
+	}
+
+	var iface ModuleInterface
+	previousEnd := 0
+	for _, block := range body.Blocks {
+		start, end := block.DefRange().Start.Line, block.Range().End.Line
+
+		switch {
+		case block.Type == "variable" && len(block.Labels) == 1:
+			variable := Variable{
+				Name:        block.Labels[0],
+				File:        filename,
+				StartLine:   start,
+				EndLine:     end,
+				Description: stringAttribute(block.Body, "description"),
+				Sensitive:   boolAttribute(block.Body, "sensitive"),
+				Comments:    blockComments(comments, previousEnd, start, end),
+			}
+			if attr, exists := block.Body.Attributes["type"]; exists {
+				variable.Type = string(attr.Expr.Range().SliceBytes(src))
+			}
+			if attr, exists := block.Body.Attributes["default"]; exists {
+				variable.Default = string(attr.Expr.Range().SliceBytes(src))
+				variable.HasDefault = true
+			}
+			iface.Variables = append(iface.Variables, variable)
+
+		case block.Type == "output" && len(block.Labels) == 1:
+			iface.Outputs = append(iface.Outputs, Output{
+				Name:        block.Labels[0],
+				File:        filename,
+				StartLine:   start,
+				EndLine:     end,
+				Description: stringAttribute(block.Body, "description"),
+				Sensitive:   boolAttribute(block.Body, "sensitive"),
+				Comments:    blockComments(comments, previousEnd, start, end),
+			})
+
+		case block.Type == "module" && len(block.Labels) == 1:
+			iface.ModuleCalls = append(iface.ModuleCalls, ModuleCall{
+				Name:      block.Labels[0],
+				File:      filename,
+				StartLine: start,
+				EndLine:   end,
+				Source:    stringAttribute(block.Body, "source"),
+				Version:   stringAttribute(block.Body, "version"),
+				Comments:  blockComments(comments, previousEnd, start, end),
+			})
+
+		case block.Type == "terraform":
+			if iface.RequiredVersion == "" {
+				iface.RequiredVersion = stringAttribute(block.Body, "required_version")
+			}
+			for _, nested := range block.Body.Blocks {
+				if nested.Type == "required_providers" {
+					iface.Providers = append(iface.Providers, providerRequirements(nested.Body)...)
+				}
+			}
+		}
+
+		previousEnd = end
+	}
+
+	return iface, nil
+}
+
+// blockComments returns the comments directly above a block, after the previous block, or
+// inside it. File-level annotations belong to no block.
+func blockComments(comments []StructuredComment, previousEnd, start, end int) []StructuredComment {
+	var matching []StructuredComment
+	for _, comment := range comments {
+		if comment.Scope == ScopeFile {
+			continue
+		}
+		if comment.Line > previousEnd && comment.EndLine >= start-5 && comment.Line <= end {
+			matching = append(matching, comment)
+		}
+	}
+	return matching
+}
+
+// providerRequirements parses the entries of a required_providers block, both the
+// { source, version } form and the legacy version string form, sorted by name
+func providerRequirements(body *hclsyntax.Body) []ProviderRequirement {
+	var providers []ProviderRequirement
+	for name, attr := range body.Attributes {
+		provider := ProviderRequirement{Name: name}
+		value, diags := attr.Expr.Value(nil)
+		if !diags.HasErrors() && !value.IsNull() && value.IsWhollyKnown() {
+			switch {
+			case value.Type() == cty.String:
+				provider.Version = value.AsString()
+			case value.Type().IsObjectType():
+				provider.Source = objectString(value, "source")
+				provider.Version = objectString(value, "version")
+			}
+		}
+		providers = append(providers, provider)
+	}
+	sort.Slice(providers, func(i, j int) bool { return providers[i].Name < providers[j].Name })
+	return providers
+}
+
+// objectString returns a string attribute of an object value, or ""
+func objectString(value cty.Value, name string) string {
+	if !value.Type().HasAttribute(name) {
+		return ""
+	}
+	attr := value.GetAttr(name)
+	if attr.IsNull() || attr.Type() != cty.String {
+		return ""
+	}
+	return attr.AsString()
+}
+
+// stringAttribute returns the value of a literal string attribute, or ""
+func stringAttribute(body *hclsyntax.Body, name string) string {
+	value, ok := literalAttribute(body, name)
+	if !ok || value.Type() != cty.String {
+		return ""
+	}
+	return value.AsString()
+}
+
+// boolAttribute returns the value of a literal bool attribute, or false
+func boolAttribute(body *hclsyntax.Body, name string) bool {
+	value, ok := literalAttribute(body, name)
+	if !ok || value.Type() != cty.Bool {
+		return false
+	}
+	return value.True()
+}
+
+// literalAttribute evaluates an attribute that doesn't reference anything
+func literalAttribute(body *hclsyntax.Body, name string) (cty.Value, bool) {
+	attr, exists := body.Attributes[name]
+	if !exists {
+		return cty.NilVal, false
+	}
+	value, diags := attr.Expr.Value(&hcl.EvalContext{})
+	if diags.HasErrors() || value.IsNull() || !value.IsWhollyKnown() {
+		return cty.NilVal, false
+	}
+	return value, true
+}
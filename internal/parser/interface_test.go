@@ -0,0 +1,128 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestParseInterface(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	content := `
+terraform {
+  required_version = ">= 1.5"
+  required_providers {
+    random = "~> 3.0"
+    aws = {
+      source  = "hashicorp/aws"
+      version = "~> 5.0"
+    }
+  }
+}
+
+# @metadata owner:platform
+# @docs example:"s3cr3t"
+variable "db_password" {
+  type      = string
+  sensitive = true
+}
+
+variable "tags" {
+  description = "Tags for every resource"
+  type        = map(string)
+  default     = {}
+}
+
+# @docs description:"Database endpoint"
+output "endpoint" {
+  value = aws_db_instance.main.endpoint
+}
+
+# @docs description:"Network"
+module "network" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "5.0.0"
+}
+
+resource "aws_db_instance" "main" {}
+`
+	if err := afero.WriteFile(fs, "/db/main.tf", []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	p := NewCommentParser(fs, []string{"@metadata", "@docs"})
+	iface, err := p.ParseInterface("/db/main.tf")
+	if err != nil {
+		t.Fatalf("ParseInterface failed: %v", err)
+	}
+
+	if iface.RequiredVersion != ">= 1.5" {
+		t.Errorf("RequiredVersion = %q", iface.RequiredVersion)
+	}
+	expectedProviders := []ProviderRequirement{
+		{Name: "aws", Source: "hashicorp/aws", Version: "~> 5.0"},
+		{Name: "random", Version: "~> 3.0"},
+	}
+	if !reflect.DeepEqual(iface.Providers, expectedProviders) {
+		t.Errorf("Providers = %+v", iface.Providers)
+	}
+
+	if len(iface.Variables) != 2 {
+		t.Fatalf("Expected 2 variables, got %d", len(iface.Variables))
+	}
+	password, tags := iface.Variables[0], iface.Variables[1]
+	if password.Type != "string" || password.HasDefault || !password.Sensitive {
+		t.Errorf("Unexpected db_password: %+v", password)
+	}
+	if got := password.Comments; len(got) != 2 {
+		t.Errorf("Expected both annotations on db_password, got %d", len(got))
+	}
+	if example, _ := password.GetAnnotation("@docs"); example.Fields["example"] != "s3cr3t" {
+		t.Errorf("Expected the @docs example of db_password, got %v", example.Fields)
+	}
+	if tags.Description != "Tags for every resource" || tags.Type != "map(string)" || tags.Default != "{}" || !tags.HasDefault {
+		t.Errorf("Unexpected tags: %+v", tags)
+	}
+	if len(tags.Comments) != 0 {
+		t.Errorf("Expected no annotations on tags, got %+v", tags.Comments)
+	}
+
+	if len(iface.Outputs) != 1 || iface.Outputs[0].Name != "endpoint" || len(iface.Outputs[0].Comments) != 1 {
+		t.Errorf("Unexpected outputs: %+v", iface.Outputs)
+	}
+
+	if len(iface.ModuleCalls) != 1 {
+		t.Fatalf("Expected 1 module call, got %d", len(iface.ModuleCalls))
+	}
+	if call := iface.ModuleCalls[0]; call.Source != "terraform-aws-modules/vpc/aws" || call.Version != "5.0.0" || len(call.Comments) != 1 {
+		t.Errorf("Unexpected module call: %+v", call)
+	}
+}
+
+func TestModuleInterfaceMerge(t *testing.T) {
+	iface := ModuleInterface{
+		RequiredVersion: ">= 1.5",
+		Providers:       []ProviderRequirement{{Name: "aws", Version: "~> 5.0"}},
+		Variables:       []Variable{{Name: "zone"}},
+	}
+	iface.Merge(ModuleInterface{
+		RequiredVersion: ">= 1.0",
+		Providers:       []ProviderRequirement{{Name: "aws", Version: "~> 4.0"}, {Name: "archive"}},
+		Variables:       []Variable{{Name: "name"}},
+		Outputs:         []Output{{Name: "id"}},
+	})
+
+	if iface.RequiredVersion != ">= 1.5" {
+		t.Errorf("Expected the first required_version to win, got %q", iface.RequiredVersion)
+	}
+	if len(iface.Providers) != 2 || iface.Providers[0].Name != "archive" || iface.Providers[1].Version != "~> 5.0" {
+		t.Errorf("Unexpected providers: %+v", iface.Providers)
+	}
+	if iface.Variables[0].Name != "name" || iface.Variables[1].Name != "zone" {
+		t.Errorf("Expected variables sorted by name, got %+v", iface.Variables)
+	}
+	if iface.Empty() || !(ModuleInterface{}).Empty() {
+		t.Error("Unexpected Empty() result")
+	}
+}
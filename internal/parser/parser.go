@@ -5,6 +5,8 @@ import (
 	"io"
 	"path/filepath"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/hashicorp/hcl/v2"
@@ -14,28 +16,97 @@ import (
 
 // StructuredComment represents a parsed comment with prefix-based fields
 type StructuredComment struct {
-	Prefix  string                 // e.g., "metadata", "docs", "validation"
-	Fields  map[string]interface{} // Parsed key-value pairs (supports nested structures)
-	Raw     string                 // Original comment text
-	Line    int                    // Starting line number in file
-	EndLine int                    // Ending line number (for multi-line comments)
+	Prefix     string                 // e.g., "metadata", "docs", "validation"
+	Fields     map[string]interface{} // Parsed key-value pairs (supports nested structures)
+	RawValues  map[string]string      // Values exactly as written, by dotted field path
+	FieldLines map[string]int         // Line each field (or nested structure) was set on, by dotted path
+	Conflicts  []FieldConflict        // Keys that were repeated or collided and were not stored
+	Raw        string                 // Original comment text
+	Line       int                    // Starting line number in file
+	EndLine    int                    // Ending line number (for multi-line comments)
+	Scope      string                 // ScopeFile for file-level annotations, e.g. "# @metadata(file) team:networking"
+	Source     string                 // Where a sidecar or inherited annotation comes from; "" for comments in the file
+	Sources    map[string]string      // Source of each sidecar or inherited field of an effective annotation, by dotted path
+}
+
+// ScopeFile marks an annotation that applies to every resource in its file
+const ScopeFile = "file"
+
+// Kinds of FieldConflict
+const (
+	ConflictDuplicate = "duplicate" // The same key was set twice
+	ConflictNesting   = "nesting"   // A value and a nested structure were both set at the same path
+)
+
+// FieldConflict describes a key whose value was dropped because it was already set. The first
+// value always wins.
+type FieldConflict struct {
+	Kind      string
+	Field     string // Dotted path of the dropped key
+	Line      int    // Line the dropped key is on
+	FirstLine int    // Line the existing value was set on
+	Message   string
 }
 
 // TerraformResource represents a parsed resource with associated comments
 type TerraformResource struct {
 	Type              string
 	Name              string
+	Module            string // Address of the module instance the resource belongs to, e.g. "module.vpc"; "" in the root module
+	File              string // Path of the file the resource was parsed from
 	StartLine         int
 	EndLine           int
-	Attributes        map[string]interface{}
+	Attributes        map[string]interface{} // Attribute source text, by name
 	PrecedingComments []StructuredComment
-	InlineComments    []StructuredComment
+	InlineComments    []StructuredComment // Comments inside the block that don't belong to an attribute or nested block
+	SidecarComments   []StructuredComment // Annotations for the resource in sidecar files next to its file
+	// AttributeComments holds trailing comments on attributes, by attribute path, e.g.
+	// "instance_type" or "ingress[1].cidr_blocks"
+	AttributeComments map[string][]StructuredComment
+	Blocks            []NestedBlock // Nested blocks at any depth, in source order
+	// Inherited holds the annotations the resource inherits from its file header, the module
+	// calls leading to it and directory defaults, highest precedence first
+	Inherited []StructuredComment
+}
+
+// ModuleCall is a module block with the annotations written on it
+type ModuleCall struct {
+	Name      string
+	File      string
+	StartLine int
+	EndLine   int
+	Source    string              // Literal source; "" when not a string
+	Version   string              // Literal version constraint; "" when not declared
+	Comments  []StructuredComment // Comments directly above the block or inside it
+}
+
+// NestedBlock is a block nested inside a resource, such as ingress {} or lifecycle {}
+type NestedBlock struct {
+	Type       string
+	Labels     []string
+	Path       string // e.g. "lifecycle", "ingress[1]" or "dynamic.setting.content"; repeated block types are indexed
+	StartLine  int
+	EndLine    int
+	Attributes map[string]interface{} // Attribute source text, by name
+	Comments   []StructuredComment    // Comments directly above the block or inside it
+}
+
+// Address returns the resource's full address, e.g. "module.vpc.aws_subnet.private"
+func (r *TerraformResource) Address() string {
+	if r.Module == "" {
+		return r.Type + "." + r.Name
+	}
+	return r.Module + "." + r.Type + "." + r.Name
 }
 
 // CommentParser handles parsing of Terraform files with comment extraction
 type CommentParser struct {
 	fs       afero.Fs
-	prefixes []string // Comment prefixes to look for (e.g., "@metadata", "@docs")
+	prefixes []string                // Comment prefixes to look for (e.g., "@metadata", "@docs")
+	types    TypeResolver            // Declared field types used to coerce values, if set
+	modules  func(dir string) string // Module address of a directory, if set
+	inherit  func(filename string) []StructuredComment
+	sidecar  func(resource TerraformResource) []StructuredComment
 }
 
 func NewCommentParser(fs afero.Fs, prefixes []string) *CommentParser {
@@ -45,6 +116,31 @@ func NewCommentParser(fs afero.Fs, prefixes []string) *CommentParser {
 	return &CommentParser{fs: fs, prefixes: prefixes}
 }
 
+// SetTypeResolver makes the parser coerce values to their declared types instead of inferring
+// them from the literal, e.g. so that zip:01234 stays a string when declared as one
+func (cp *CommentParser) SetTypeResolver(types TypeResolver) {
+	cp.types = types
+}
+
+// SetModuleResolver makes the parser set the module address of resources from the directory of
+// the file they are defined in
+func (cp *CommentParser) SetModuleResolver(modules func(dir string) string) {
+	cp.modules = modules
+}
+
+// SetInheritanceResolver makes the parser add the annotations returned for a file, such as those
+// of module calls and directory defaults, to the inherited annotations of its resources. They
+// rank below the file's own header annotations, in the order returned.
+func (cp *CommentParser) SetInheritanceResolver(inherit func(filename string) []StructuredComment) {
+	cp.inherit = inherit
+}
+
+// SetSidecarResolver makes the parser attach the sidecar annotations returned for each resource.
+// They are merged with the resource's comments, which take precedence.
+func (cp *CommentParser) SetSidecarResolver(sidecar func(resource TerraformResource) []StructuredComment) {
+	cp.sidecar = sidecar
+}
+
 // ParseFile parses a Terraform file and extracts resources with their comments
 func (cp *CommentParser) ParseFile(filename string) ([]TerraformResource, error) {
 	// Clean the path
@@ -63,68 +159,111 @@ func (cp *CommentParser) ParseFile(filename string) ([]TerraformResource, error)
 		return nil, err
 	}
 
-	// Parse the HCL file
-	file, diags := hclsyntax.ParseConfig(src, filename, hcl.Pos{Line: 1, Column: 1})
-	if diags.HasErrors() {
-		return nil, fmt.Errorf("parse error: %s", diags.Error())
-	}
+	return cp.ParseSource(filename, src)
+}
 
-	// Get all tokens including comments
-	tokens, diags := hclsyntax.LexConfig(src, filename, hcl.Pos{Line: 1, Column: 1})
-	if diags.HasErrors() {
-		return nil, fmt.Errorf("lex error: %s", diags.Error())
+// ParseSource parses Terraform source held in memory and extracts resources with their comments
+func (cp *CommentParser) ParseSource(filename string, src []byte) ([]TerraformResource, error) {
+	body, comments, err := cp.parseConfig(filename, src)
+	if err != nil {
+		return nil, err
 	}
 
-	// Extract all comments with their positions
-	comments := cp.extractComments(tokens)
+	// File-level annotations are inherited by every resource rather than attached to one
+	var inherited, blockComments []StructuredComment
+	for _, comment := range comments {
+		if comment.Scope == ScopeFile {
+			comment.Source = fmt.Sprintf("file header (%s:%d)", filename, comment.Line)
+			inherited = append(inherited, comment)
+			continue
+		}
+		blockComments = append(blockComments, comment)
+	}
+	comments = blockComments
+	if cp.inherit != nil {
+		inherited = append(inherited, cp.inherit(filename)...)
+	}
 
 	// Parse resources from the syntax tree
-	body := file.Body.(*hclsyntax.Body)
 	var resources []TerraformResource
 
+	// Comments above a block only belong to it if they follow the previous block
+	previousEnd := 0
 	for _, block := range body.Blocks {
 		if block.Type == "resource" {
-			resource := cp.parseResource(block, comments)
+			resource := cp.parseResource(block, src, comments, previousEnd)
+			resource.File = filename
+			resource.Inherited = inherited
+			if cp.modules != nil {
+				resource.Module = cp.modules(filepath.Dir(filename))
+			}
+			if cp.sidecar != nil {
+				resource.SidecarComments = cp.sidecar(resource)
+			}
 			resources = append(resources, resource)
 		}
+		previousEnd = block.Range().End.Line
 	}
 
 	return resources, nil
 }
 
+// ParseModuleCalls parses the module blocks of a Terraform file with the annotations written on
+// them
+func (cp *CommentParser) ParseModuleCalls(filename string) ([]ModuleCall, error) {
+	iface, err := cp.ParseInterface(filename)
+	if err != nil {
+		return nil, err
+	}
+	return iface.ModuleCalls, nil
+}
+
+// parseConfig parses Terraform source into its body and structured comments
+func (cp *CommentParser) parseConfig(filename string, src []byte) (*hclsyntax.Body, []StructuredComment, error) {
+	// Parse the HCL file
+	file, diags := hclsyntax.ParseConfig(src, filename, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, nil, fmt.Errorf("parse error: %s", diags.Error())
+	}
+
+	// Get all tokens including comments
+	tokens, diags := hclsyntax.LexConfig(src, filename, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, nil, fmt.Errorf("lex error: %s", diags.Error())
+	}
+
+	// Extract all comments with their positions
+	return file.Body.(*hclsyntax.Body), cp.extractComments(tokens), nil
+}
+
 // extractComments extracts all comments from tokens and parses structured fields
 func (cp *CommentParser) extractComments(tokens hclsyntax.Tokens) []StructuredComment {
 	var comments []StructuredComment
 	var commentBuffer []string
-	var bufferStartLine int
-	var inMultiLine bool
+	var bufferLines []int
 
 	for i, token := range tokens {
 		if token.Type == hclsyntax.TokenComment {
 			text := string(token.Bytes)
 			line := token.Range.Start.Line
 
-			// Check if this starts a new comment block
-			if !inMultiLine {
-				bufferStartLine = line
-				inMultiLine = true
-			}
-
 			commentBuffer = append(commentBuffer, text)
+			bufferLines = append(bufferLines, line)
 
 			// Check if next token is also a comment on the next line (continuation)
 			isLastToken := i == len(tokens)-1
 			nextIsComment := !isLastToken && tokens[i+1].Type == hclsyntax.TokenComment
 			nextIsAdjacent := !isLastToken && tokens[i+1].Range.Start.Line == line+1
 
+			// A comment trailing code on its line, e.g. after an attribute, stands alone
+			trailing := i > 0 && tokens[i-1].Type != hclsyntax.TokenComment &&
+				tokens[i-1].Type != hclsyntax.TokenNewline && tokens[i-1].Range.End.Line == line
+
 			// If this is the end of a comment block, process it
-			if isLastToken || !nextIsComment || !nextIsAdjacent {
-				structured := cp.parseMultiLineComment(commentBuffer, bufferStartLine, line)
-				if structured != nil {
-					comments = append(comments, *structured)
-				}
+			if isLastToken || !nextIsComment || !nextIsAdjacent || trailing {
+				comments = append(comments, cp.splitCommentRun(commentBuffer, bufferLines)...)
 				commentBuffer = nil
-				inMultiLine = false
+				bufferLines = nil
 			}
 		}
 	}
@@ -132,20 +271,71 @@ func (cp *CommentParser) extractComments(tokens hclsyntax.Tokens) []StructuredCo
 	return comments
 }
 
-// parseMultiLineComment processes a buffer of comment lines
-func (cp *CommentParser) parseMultiLineComment(lines []string, startLine, endLine int) *StructuredComment {
+// splitCommentRun splits a run of adjacent comment lines into one structured comment per
+// prefix. A line starting with a known prefix begins a new comment; other lines continue the
+// nearest preceding one, and lines before the first prefix are ignored.
+func (cp *CommentParser) splitCommentRun(lines []string, lineNumbers []int) []StructuredComment {
+	var comments []StructuredComment
+	var part []string
+
+	var partLines []int
+
+	flush := func() {
+		if structured := cp.parseMultiLineComment(part, partLines); structured != nil {
+			comments = append(comments, *structured)
+		}
+		part, partLines = nil, nil
+	}
+
+	for i, line := range lines {
+		if cp.matchPrefix(cleanCommentLine(line)) != "" {
+			if part != nil {
+				flush()
+			}
+		} else if part == nil {
+			continue
+		}
+		part = append(part, line)
+		partLines = append(partLines, lineNumbers[i])
+	}
+	if part != nil {
+		flush()
+	}
+
+	return comments
+}
+
+// cleanCommentLine strips the comment marker and surrounding whitespace from a comment line
+func cleanCommentLine(line string) string {
+	cleaned := strings.TrimPrefix(line, "//")
+	cleaned = strings.TrimPrefix(cleaned, "#")
+	return strings.TrimSpace(cleaned)
+}
+
+// matchPrefix returns the known prefix a cleaned comment line starts with, or ""
+func (cp *CommentParser) matchPrefix(line string) string {
+	for _, prefix := range cp.prefixes {
+		if strings.HasPrefix(line, prefix) {
+			return prefix
+		}
+	}
+	return ""
+}
+
+// parseMultiLineComment processes a buffer of comment lines and their line numbers
+func (cp *CommentParser) parseMultiLineComment(lines []string, lineNumbers []int) *StructuredComment {
 	if len(lines) == 0 {
 		return nil
 	}
 
 	// Clean and combine all lines
 	var cleanedLines []string
-	for _, line := range lines {
-		cleaned := strings.TrimPrefix(line, "//")
-		cleaned = strings.TrimPrefix(cleaned, "#")
-		cleaned = strings.TrimSpace(cleaned)
+	var cleanedNumbers []int
+	for i, line := range lines {
+		cleaned := cleanCommentLine(line)
 		if cleaned != "" {
 			cleanedLines = append(cleanedLines, cleaned)
+			cleanedNumbers = append(cleanedNumbers, lineNumbers[i])
 		}
 	}
 
@@ -154,14 +344,7 @@ func (cp *CommentParser) parseMultiLineComment(lines []string, startLine, endLin
 	}
 
 	// Check if first line starts with any of our prefixes
-	var matchedPrefix string
-	for _, prefix := range cp.prefixes {
-		if strings.HasPrefix(cleanedLines[0], prefix) {
-			matchedPrefix = prefix
-			break
-		}
-	}
-
+	matchedPrefix := cp.matchPrefix(cleanedLines[0])
 	if matchedPrefix == "" {
 		return nil
 	}
@@ -170,200 +353,285 @@ func (cp *CommentParser) parseMultiLineComment(lines []string, startLine, endLin
 	fullText := strings.Join(cleanedLines, "\n")
 
 	// Parse fields with support for nested structures
-	fields := cp.parseCommentFields(fullText)
-
-	return &StructuredComment{
+	comment := &StructuredComment{
 		Prefix:  matchedPrefix,
-		Fields:  fields,
 		Raw:     fullText,
-		Line:    startLine,
-		EndLine: endLine,
+		Line:    lineNumbers[0],
+		EndLine: lineNumbers[len(lineNumbers)-1],
+	}
+	if strings.HasPrefix(cleanedLines[0][len(matchedPrefix):], "("+ScopeFile+")") {
+		comment.Scope = ScopeFile
 	}
+	cp.parseCommentFields(comment, cleanedLines, cleanedNumbers)
+
+	return comment
 }
 
-// parseCommentFields extracts key:value pairs from a comment with nested structure support
-// Supports formats like:
+// parseCommentFields extracts key:value pairs from a comment's cleaned lines with nested
+// structure support. Supports formats like:
 //
 //	Simple: @metadata owner:john.doe team:platform priority:high
 //	Nested: @metadata owner:john.doe contact.email:john@example.com contact.slack:@john
 //	Multi-line with indentation for nested fields
-func (cp *CommentParser) parseCommentFields(text string) map[string]interface{} {
-	fields := make(map[string]interface{})
+//	Quoted: @docs description:"Primary web server"
+func (cp *CommentParser) parseCommentFields(comment *StructuredComment, lines []string, lineNumbers []int) {
+	comment.Fields = make(map[string]interface{})
+	comment.RawValues = make(map[string]string)
+	comment.FieldLines = make(map[string]int)
 
-	lines := strings.Split(text, "\n")
 	if len(lines) == 0 {
-		return fields
+		return
 	}
 
-	// Remove prefix from first line
-	firstLine := lines[0]
-	for _, prefix := range cp.prefixes {
-		if strings.HasPrefix(firstLine, prefix) {
-			firstLine = strings.TrimSpace(strings.TrimPrefix(firstLine, prefix))
-			lines[0] = firstLine
-			break
-		}
+	// Remove prefix and scope from first line
+	lines = append([]string{}, lines...)
+	lines[0] = strings.TrimPrefix(lines[0], comment.Prefix)
+	if comment.Scope != "" {
+		lines[0] = strings.TrimPrefix(lines[0], "("+comment.Scope+")")
 	}
+	lines[0] = strings.TrimSpace(lines[0])
 
 	// Parse all lines for key:value pairs
-	for _, line := range lines {
+	for i, line := range lines {
 		line = strings.TrimSpace(line)
 		if line == "" {
 			continue
 		}
 
 		// Extract all key:value pairs from this line
-		cp.extractKeyValuePairs(line, fields)
+		cp.extractKeyValuePairs(comment, line, lineNumbers[i])
 	}
 
 	// Store the full content
 	fullContent := strings.TrimSpace(strings.Join(lines, "\n"))
 	if fullContent != "" {
-		fields["_content"] = fullContent
+		comment.Fields["_content"] = fullContent
 	}
-
-	return fields
 }
 
-// extractKeyValuePairs extracts key:value pairs and handles nested keys
-func (cp *CommentParser) extractKeyValuePairs(line string, fields map[string]interface{}) {
-	// Pattern to match key:value pairs (supports nested keys with dots)
-	// Matches word.word:value where value stops before the next word.word: pattern
-	fieldRegex := regexp.MustCompile(`([\w\.]+):(\S+)`)
+// ValuePattern matches an annotation value. Values run to the next whitespace unless quoted.
+const ValuePattern = `("[^"]*"|'[^']*'|\S+)`
+
+// fieldRegex matches key:value pairs (supports nested keys with dots).
+var fieldRegex = regexp.MustCompile(`([\w\.]+):` + ValuePattern)
+
+// extractKeyValuePairs extracts the key:value pairs on one line into the comment
+func (cp *CommentParser) extractKeyValuePairs(comment *StructuredComment, line string, lineNumber int) {
 	matches := fieldRegex.FindAllStringSubmatch(line, -1)
 
 	for _, match := range matches {
 		if len(match) == 3 {
 			key := match[1]
-			value := strings.TrimSpace(match[2])
-
-			// Handle nested keys (e.g., "contact.email" or "config.db.host")
-			if strings.Contains(key, ".") {
-				cp.setNestedField(fields, key, value)
-			} else {
-				// Try to parse value as different types
-				fields[key] = cp.parseValue(value)
-			}
+			raw := strings.TrimSpace(match[2])
+			comment.setField(key, raw, cp.parseValue(comment.Prefix, key, raw), lineNumber)
 		}
 	}
 }
 
-// setNestedField sets a value in a nested map structure based on dot notation
-func (cp *CommentParser) setNestedField(fields map[string]interface{}, key string, value string) {
+// setField stores a value at a dotted path (e.g., "contact.email" or "config.db.host"),
+// creating nested structures as needed. A key that is already set, or that collides with a
+// value or nested structure at the same path, is recorded as a conflict instead.
+func (c *StructuredComment) setField(key, raw string, value interface{}, line int) {
+	if firstLine, exists := c.FieldLines[key]; exists {
+		kind, message := ConflictDuplicate, fmt.Sprintf("Duplicate field '%s' (first set on line %d)", key, firstLine)
+		if _, nested := c.RawValues[key]; !nested {
+			kind, message = ConflictNesting, fmt.Sprintf("Field '%s' conflicts with nested fields under '%s' (first set on line %d)", key, key, firstLine)
+		}
+		c.Conflicts = append(c.Conflicts, FieldConflict{Kind: kind, Field: key, Line: line, FirstLine: firstLine, Message: message})
+		return
+	}
+
 	parts := strings.Split(key, ".")
-	current := fields
+	current := c.Fields
 
 	// Navigate/create nested structure
 	for i := 0; i < len(parts)-1; i++ {
-		part := parts[i]
-		if _, exists := current[part]; !exists {
-			current[part] = make(map[string]interface{})
+		path := strings.Join(parts[:i+1], ".")
+		if _, exists := current[parts[i]]; !exists {
+			current[parts[i]] = make(map[string]interface{})
+			c.FieldLines[path] = line
 		}
 
-		// Type assertion to navigate deeper
-		if nested, ok := current[part].(map[string]interface{}); ok {
-			current = nested
-		} else {
-			// If it's not a map, we can't nest further, so store at current level
-			current[key] = cp.parseValue(value)
+		nested, ok := current[parts[i]].(map[string]interface{})
+		if !ok {
+			// A value is already set here, so the key can't nest below it
+			c.Conflicts = append(c.Conflicts, FieldConflict{
+				Kind:      ConflictNesting,
+				Field:     key,
+				Line:      line,
+				FirstLine: c.FieldLines[path],
+				Message:   fmt.Sprintf("Field '%s' conflicts with value '%s' (first set on line %d)", key, path, c.FieldLines[path]),
+			})
 			return
 		}
+		current = nested
 	}
 
 	// Set the final value
-	finalKey := parts[len(parts)-1]
-	current[finalKey] = cp.parseValue(value)
+	current[parts[len(parts)-1]] = value
+	c.RawValues[key] = raw
+	c.FieldLines[key] = line
 }
 
-// parseValue attempts to parse a string value into appropriate types
-func (cp *CommentParser) parseValue(value string) interface{} {
-	value = strings.TrimSpace(value)
-
-	// Try to parse as boolean
-	if value == "true" {
-		return true
-	}
-	if value == "false" {
-		return false
+// parseValue converts a raw value, using the field's declared type when one is known
+func (cp *CommentParser) parseValue(prefix, path, raw string) interface{} {
+	if cp.types != nil {
+		if fieldType, ok := cp.types(prefix, path); ok {
+			return CoerceValue(raw, fieldType)
+		}
 	}
+	return ParseValue(raw)
+}
 
-	// Try to parse as number
-	if num, err := fmt.Sscanf(value, "%d", new(int)); err == nil && num == 1 {
-		var i int
-		if _, err := fmt.Sscanf(value, "%d", &i); err == nil {
-			return i
-		}
+// parseResource extracts resource information and associates comments. Comments on or before
+// line previousEnd belong to an earlier block.
+func (cp *CommentParser) parseResource(block *hclsyntax.Block, src []byte, comments []StructuredComment, previousEnd int) TerraformResource {
+	resource := TerraformResource{
+		Type:              block.Labels[0],
+		Name:              block.Labels[1],
+		StartLine:         block.DefRange().Start.Line,
+		EndLine:           block.Range().End.Line,
+		Attributes:        cp.extractAttributes(block.Body, src),
+		AttributeComments: make(map[string][]StructuredComment),
 	}
 
-	if num, err := fmt.Sscanf(value, "%f", new(float64)); err == nil && num == 1 {
-		var f float64
-		if _, err := fmt.Sscanf(value, "%f", &f); err == nil {
-			return f
+	// Preceding comments: walking up from the resource, each comment must end within 5 lines
+	// of the resource or of the comment below it, so split comment runs stay together
+	boundary := resource.StartLine
+	for i := len(comments) - 1; i >= 0; i-- {
+		comment := comments[i]
+		if comment.Line >= resource.StartLine {
+			continue
 		}
+		if comment.Line <= previousEnd || comment.EndLine < boundary-5 {
+			break
+		}
+		resource.PrecedingComments = append([]StructuredComment{comment}, resource.PrecedingComments...)
+		boundary = comment.Line
 	}
 
-	// Check for array notation [item1,item2,item3]
-	if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
-		value = strings.TrimPrefix(value, "[")
-		value = strings.TrimSuffix(value, "]")
-		items := strings.Split(value, ",")
-		var result []interface{}
-		for _, item := range items {
-			result = append(result, strings.TrimSpace(item))
+	// Comments on attributes and nested blocks belong to them rather than to the resource
+	claimed := make(map[int]bool)
+	cp.parseBody(&resource, block.Body, src, "", resource.StartLine, comments, claimed)
+	sort.SliceStable(resource.Blocks, func(i, j int) bool { return resource.Blocks[i].StartLine < resource.Blocks[j].StartLine })
+
+	// Inline comments: within the resource block
+	for i, comment := range comments {
+		if !claimed[i] && comment.Line >= resource.StartLine && comment.Line <= resource.EndLine {
+			resource.InlineComments = append(resource.InlineComments, comment)
 		}
-		return result
 	}
 
-	// Return as string
-	return value
+	return resource
 }
 
-// parseResource extracts resource information and associates comments
-func (cp *CommentParser) parseResource(block *hclsyntax.Block, comments []StructuredComment) TerraformResource {
-	resource := TerraformResource{
-		Type:       block.Labels[0],
-		Name:       block.Labels[1],
-		StartLine:  block.DefRange().Start.Line,
-		EndLine:    block.Range().End.Line,
-		Attributes: make(map[string]interface{}),
+// parseBody attaches trailing comments to the attributes of a resource or nested block body
+// and collects its nested blocks, recursively. Attached comments are marked as claimed.
+func (cp *CommentParser) parseBody(resource *TerraformResource, body *hclsyntax.Body, src []byte, parentPath string, parentStart int, comments []StructuredComment, claimed map[int]bool) {
+	for name, attr := range body.Attributes {
+		path := joinPath(parentPath, name)
+		start, end := attr.SrcRange.Start.Line, attr.SrcRange.End.Line
+		for i, comment := range comments {
+			if !claimed[i] && comment.Line >= start && comment.Line <= end {
+				claimed[i] = true
+				resource.AttributeComments[path] = append(resource.AttributeComments[path], comment)
+			}
+		}
 	}
 
-	// Extract attributes
-	for name, attr := range block.Body.Attributes {
-		resource.Attributes[name] = cp.extractAttributeValue(attr)
+	// Repeated block types are addressed by index, e.g. ingress[0] and ingress[1]
+	counts := make(map[string]int)
+	for _, block := range body.Blocks {
+		counts[blockName(block)]++
 	}
+	seen := make(map[string]int)
+	previousEnd := parentStart
 
-	// Associate comments with this resource
-	for _, comment := range comments {
-		// Preceding comments: within 5 lines before the resource
-		if comment.Line < resource.StartLine && comment.Line >= resource.StartLine-5 {
-			resource.PrecedingComments = append(resource.PrecedingComments, comment)
+	for _, block := range body.Blocks {
+		name := blockName(block)
+		path := joinPath(parentPath, name)
+		if counts[name] > 1 {
+			path += "[" + strconv.Itoa(seen[name]) + "]"
 		}
-
-		// Inline comments: within the resource block
-		if comment.Line >= resource.StartLine && comment.Line <= resource.EndLine {
-			resource.InlineComments = append(resource.InlineComments, comment)
+		seen[name]++
+
+		nested := NestedBlock{
+			Type:       block.Type,
+			Labels:     block.Labels,
+			Path:       path,
+			StartLine:  block.DefRange().Start.Line,
+			EndLine:    block.Range().End.Line,
+			Attributes: cp.extractAttributes(block.Body, src),
 		}
+		cp.parseBody(resource, block.Body, src, path, nested.StartLine, comments, claimed)
+
+		// Comments directly above the block, then the remaining comments inside it
+		boundary := nested.StartLine
+		for i := len(comments) - 1; i >= 0; i-- {
+			comment := comments[i]
+			if comment.Line >= nested.StartLine || claimed[i] {
+				continue
+			}
+			if comment.Line <= previousEnd || comment.EndLine < boundary-1 {
+				break
+			}
+			claimed[i] = true
+			nested.Comments = append([]StructuredComment{comment}, nested.Comments...)
+			boundary = comment.Line
+		}
+		for i, comment := range comments {
+			if !claimed[i] && comment.Line >= nested.StartLine && comment.Line <= nested.EndLine {
+				claimed[i] = true
+				nested.Comments = append(nested.Comments, comment)
+			}
+		}
+
+		resource.Blocks = append(resource.Blocks, nested)
+		previousEnd = nested.EndLine
 	}
+}
 
-	return resource
+// blockName returns a nested block's type followed by its labels, e.g. "dynamic.setting"
+func blockName(block *hclsyntax.Block) string {
+	return strings.Join(append([]string{block.Type}, block.Labels...), ".")
 }
 
-// extractAttributeValue extracts the value from an attribute
-func (cp *CommentParser) extractAttributeValue(attr *hclsyntax.Attribute) interface{} {
-	// This is a simplified version - you might want more sophisticated extraction
-	tokens := attr.Expr.Range().SliceBytes(attr.Expr.StartRange().SliceBytes([]byte{}))
-	return string(tokens)
+// joinPath appends a name to a dotted attribute or block path
+func joinPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
 }
 
-// GetCommentsByPrefix filters comments by prefix for a resource
+// extractAttributes returns the source text of a body's attribute values, by name
+func (cp *CommentParser) extractAttributes(body *hclsyntax.Body, src []byte) map[string]interface{} {
+	attributes := make(map[string]interface{}, len(body.Attributes))
+	for name, attr := range body.Attributes {
+		attributes[name] = cp.extractAttributeValue(attr, src)
+	}
+	return attributes
+}
+
+// extractAttributeValue extracts the source text of an attribute's value
+func (cp *CommentParser) extractAttributeValue(attr *hclsyntax.Attribute, src []byte) interface{} {
+	return string(attr.Expr.Range().SliceBytes(src))
+}
+
+// Comments returns the annotations written for the resource: its preceding and inline comments
+// followed by its sidecar annotations
+func (r *TerraformResource) Comments() []StructuredComment {
+	comments := make([]StructuredComment, 0, len(r.PrecedingComments)+len(r.InlineComments)+len(r.SidecarComments))
+	comments = append(comments, r.PrecedingComments...)
+	comments = append(comments, r.InlineComments...)
+	return append(comments, r.SidecarComments...)
+}
+
+// GetCommentsByPrefix filters comments by prefix for a resource, including sidecar annotations
 func (r *TerraformResource) GetCommentsByPrefix(prefix string) []StructuredComment {
 	var result []StructuredComment
 
-	allComments := make([]StructuredComment, 0, len(r.PrecedingComments)+len(r.InlineComments))
-	allComments = append(allComments, r.PrecedingComments...)
-	allComments = append(allComments, r.InlineComments...)
-	for _, comment := range allComments {
+	for _, comment := range r.Comments() {
 		if comment.Prefix == prefix {
 			result = append(result, comment)
 		}
@@ -372,36 +640,275 @@ func (r *TerraformResource) GetCommentsByPrefix(prefix string) []StructuredComme
 	return result
 }
 
-// GetNestedField retrieves a nested field value using dot notation
-func (r *TerraformResource) GetNestedField(commentPrefix, fieldPath string) interface{} {
-	comments := r.GetCommentsByPrefix(commentPrefix)
+// MergeComments combines the comments of one prefix into a single comment, in order. Fields
+// set by an earlier comment win; keys repeated by later comments are recorded as conflicts
+// alongside the conflicts of each comment. Fields of comments with a Source, such as sidecar
+// annotations, are listed in Sources, and their conflicts name the source.
+func MergeComments(comments []StructuredComment) StructuredComment {
 	if len(comments) == 0 {
-		return nil
+		return StructuredComment{}
 	}
 
-	// Use the first matching comment
-	comment := comments[0]
+	merged := StructuredComment{
+		Prefix:     comments[0].Prefix,
+		Fields:     make(map[string]interface{}),
+		RawValues:  make(map[string]string),
+		FieldLines: make(map[string]int),
+		Line:       comments[0].Line,
+		EndLine:    comments[0].EndLine,
+	}
 
-	// Navigate nested fields
-	parts := strings.Split(fieldPath, ".")
-	current := comment.Fields
+	var contents, raws []string
+	for _, comment := range comments {
+		values := make(map[string]interface{})
+		flattenFields(comment.Fields, "", values)
 
-	for i, part := range parts {
-		if val, exists := current[part]; exists {
-			if i == len(parts)-1 {
-				// This is the final key
-				return val
+		lineOf := func(path string) int {
+			if line, ok := comment.FieldLines[path]; ok {
+				return line
 			}
-			// Navigate deeper if it's a nested map
-			if nested, ok := val.(map[string]interface{}); ok {
-				current = nested
-			} else {
-				return nil
+			return comment.Line
+		}
+		paths := make([]string, 0, len(values))
+		for path := range values {
+			paths = append(paths, path)
+		}
+		sort.Slice(paths, func(i, j int) bool {
+			if lineOf(paths[i]) != lineOf(paths[j]) {
+				return lineOf(paths[i]) < lineOf(paths[j])
+			}
+			return paths[i] < paths[j]
+		})
+
+		conflicts := len(merged.Conflicts)
+		for _, path := range paths {
+			raw, ok := comment.RawValues[path]
+			if !ok {
+				raw = fmt.Sprintf("%v", values[path])
+			}
+			before := len(merged.Conflicts)
+			merged.setField(path, raw, values[path], lineOf(path))
+			if comment.Source != "" && len(merged.Conflicts) == before {
+				if merged.Sources == nil {
+					merged.Sources = make(map[string]string)
+				}
+				merged.Sources[path] = comment.Source
 			}
-		} else {
+		}
+		merged.Conflicts = append(merged.Conflicts, comment.Conflicts...)
+		if comment.Source != "" {
+			for i := conflicts; i < len(merged.Conflicts); i++ {
+				merged.Conflicts[i].Message += " in " + comment.Source
+			}
+		}
+
+		if content, ok := comment.Fields["_content"].(string); ok {
+			contents = append(contents, content)
+		}
+		raws = append(raws, comment.Raw)
+		if comment.EndLine > merged.EndLine {
+			merged.EndLine = comment.EndLine
+		}
+	}
+
+	if len(contents) > 0 {
+		merged.Fields["_content"] = strings.Join(contents, "\n")
+	}
+	merged.Raw = strings.Join(raws, "\n")
+
+	return merged
+}
+
+// flattenFields collects the values under nested fields by dotted path. Empty nested
+// structures are kept as values.
+func flattenFields(fields map[string]interface{}, parentPath string, values map[string]interface{}) {
+	for key, value := range fields {
+		if parentPath == "" && key == "_content" {
+			continue
+		}
+		path := key
+		if parentPath != "" {
+			path = parentPath + "." + key
+		}
+		if nested, ok := value.(map[string]interface{}); ok && len(nested) > 0 {
+			flattenFields(nested, path, values)
+			continue
+		}
+		values[path] = value
+	}
+}
+
+// lookupField returns the value at a dotted path, or nil if it isn't set
+func lookupField(fields map[string]interface{}, fieldPath string) interface{} {
+	parts := strings.Split(fieldPath, ".")
+	current := fields
+
+	for i, part := range parts {
+		val, exists := current[part]
+		if !exists {
 			return nil
 		}
+		if i == len(parts)-1 {
+			return val
+		}
+		nested, ok := val.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = nested
 	}
 
 	return nil
 }
+
+// GetAnnotation returns the resource's effective annotation for a prefix: its comments merged
+// into one, so that fields split across repeated comments are all visible, followed by the
+// fields it inherits and doesn't set itself. Inherited fields are listed in Sources.
+func (r *TerraformResource) GetAnnotation(prefix string) (StructuredComment, bool) {
+	comments := r.GetCommentsByPrefix(prefix)
+
+	var inherited []StructuredComment
+	for _, comment := range r.Inherited {
+		if comment.Prefix == prefix {
+			inherited = append(inherited, comment)
+		}
+	}
+
+	if len(comments) == 0 && len(inherited) == 0 {
+		return StructuredComment{}, false
+	}
+
+	merged := StructuredComment{
+		Prefix:     prefix,
+		Fields:     make(map[string]interface{}),
+		RawValues:  make(map[string]string),
+		FieldLines: make(map[string]int),
+		Line:       r.StartLine,
+		EndLine:    r.StartLine,
+	}
+	if len(comments) > 0 {
+		merged = MergeComments(comments)
+		if comments[0].Source != "" {
+			// Sidecar lines are in another file, so errors point at the resource instead
+			merged.Line, merged.EndLine = r.StartLine, r.StartLine
+		}
+	}
+	for _, comment := range inherited {
+		merged.inherit(comment)
+	}
+	return merged, true
+}
+
+// inherit adds the fields of an inherited annotation that aren't already set. Fields that
+// collide with a value or nested structure already set are overridden rather than conflicts.
+func (c *StructuredComment) inherit(comment StructuredComment) {
+	if c.Sources == nil {
+		c.Sources = make(map[string]string)
+	}
+
+	values := make(map[string]interface{})
+	flattenFields(comment.Fields, "", values)
+	paths := make([]string, 0, len(values))
+	for path := range values {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		raw, ok := comment.RawValues[path]
+		if !ok {
+			raw = fmt.Sprintf("%v", values[path])
+		}
+		line, ok := comment.FieldLines[path]
+		if !ok {
+			line = comment.Line
+		}
+
+		conflicts := len(c.Conflicts)
+		c.setField(path, raw, values[path], line)
+		if len(c.Conflicts) > conflicts {
+			c.Conflicts = c.Conflicts[:conflicts]
+			continue
+		}
+		c.Sources[path] = comment.Source
+	}
+}
+
+// BuildAnnotation builds an annotation from fields written outside of comments, such as in
+// directory defaults. Values are raw text by dotted path and are parsed like comment values.
+func (cp *CommentParser) BuildAnnotation(prefix string, values map[string]string, lines map[string]int, source string) StructuredComment {
+	comment := StructuredComment{
+		Prefix:     prefix,
+		Fields:     make(map[string]interface{}),
+		RawValues:  make(map[string]string),
+		FieldLines: make(map[string]int),
+		Source:     source,
+	}
+
+	paths := make([]string, 0, len(values))
+	for path := range values {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var raws []string
+	for _, path := range paths {
+		raw := values[path]
+		comment.setField(path, raw, cp.parseValue(prefix, path, raw), lines[path])
+		raws = append(raws, path+":"+raw)
+		if comment.Line == 0 || (lines[path] > 0 && lines[path] < comment.Line) {
+			comment.Line = lines[path]
+		}
+		if lines[path] > comment.EndLine {
+			comment.EndLine = lines[path]
+		}
+	}
+	comment.Raw = strings.TrimSpace(prefix + " " + strings.Join(raws, " "))
+
+	return comment
+}
+
+// GetAttributeAnnotation returns the comments with a prefix on an attribute path merged into one
+func (r *TerraformResource) GetAttributeAnnotation(path, prefix string) (StructuredComment, bool) {
+	return annotation(r.AttributeComments[path], prefix)
+}
+
+// GetAnnotation returns the block's comments with a prefix merged into one
+func (b NestedBlock) GetAnnotation(prefix string) (StructuredComment, bool) {
+	return annotation(b.Comments, prefix)
+}
+
+// annotation merges the comments with a prefix
+func annotation(comments []StructuredComment, prefix string) (StructuredComment, bool) {
+	var matching []StructuredComment
+	for _, comment := range comments {
+		if comment.Prefix == prefix {
+			matching = append(matching, comment)
+		}
+	}
+	if len(matching) == 0 {
+		return StructuredComment{}, false
+	}
+	return MergeComments(matching), true
+}
+
+// GetNestedField retrieves a nested field value using dot notation. Repeated comments with the
+// same prefix are merged, the first value of a field winning.
+func (r *TerraformResource) GetNestedField(commentPrefix, fieldPath string) interface{} {
+	comment, exists := r.GetAnnotation(commentPrefix)
+	if !exists {
+		return nil
+	}
+	return lookupField(comment.Fields, fieldPath)
+}
+
+// GetRawField returns a field value exactly as written, using dot notation for nested fields
+func (r *TerraformResource) GetRawField(commentPrefix, fieldPath string) (string, bool) {
+	comment, exists := r.GetAnnotation(commentPrefix)
+	if !exists {
+		return "", false
+	}
+
+	raw, exists := comment.RawValues[fieldPath]
+	return raw, exists
+}
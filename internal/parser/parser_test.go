@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/spf13/afero"
@@ -78,6 +79,336 @@ resource "test_resource" "nested" {
 	}
 }
 
+func TestParseFile_CoercesValuesByDeclaredType(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	content := `
+resource "test_resource" "typed" {
+  # @metadata zip:01234 retries:007 phone:555-0123 name:"Jane Doe" address.zip:98101
+  attribute = "value"
+}
+`
+	filename := "typed.tf"
+	_ = afero.WriteFile(fs, filename, []byte(content), 0644)
+
+	p := NewCommentParser(fs, []string{"@metadata"})
+	p.SetTypeResolver(func(prefix, path string) (FieldType, bool) {
+		switch path {
+		case "zip", "address.zip":
+			return FieldType{Type: "string"}, true
+		case "retries":
+			return FieldType{Type: "integer"}, true
+		}
+		return FieldType{}, false
+	})
+
+	resources, err := p.ParseFile(filename)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	if len(resources) != 1 {
+		t.Fatalf("Expected 1 resource, got %d", len(resources))
+	}
+
+	res := resources[0]
+	expected := map[string]interface{}{
+		"zip":         "01234",
+		"retries":     7,
+		"phone":       "555-0123",
+		"name":        "Jane Doe",
+		"address.zip": "98101",
+	}
+	for path, want := range expected {
+		if got := res.GetNestedField("@metadata", path); got != want {
+			t.Errorf("Expected %s to be %#v, got %#v", path, want, got)
+		}
+	}
+
+	if raw, ok := res.GetRawField("@metadata", "retries"); !ok || raw != "007" {
+		t.Errorf("Expected raw retries to be 007, got %q (found %v)", raw, ok)
+	}
+	if raw, ok := res.GetRawField("@metadata", "name"); !ok || raw != `"Jane Doe"` {
+		t.Errorf("Expected raw name to keep its quotes, got %q (found %v)", raw, ok)
+	}
+}
+
+func TestParseFile_DuplicateAndConflictingKeys(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	content := `
+# @metadata owner:a contact:x
+# contact.email:y owner:b
+# team.name:net team:ops
+resource "test_resource" "conflicts" {
+  attribute = "value"
+}
+`
+	filename := "conflicts.tf"
+	_ = afero.WriteFile(fs, filename, []byte(content), 0644)
+
+	p := NewCommentParser(fs, []string{"@metadata"})
+	resources, err := p.ParseFile(filename)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+
+	res := resources[0]
+	// The first value wins
+	if got := res.GetNestedField("@metadata", "owner"); got != "a" {
+		t.Errorf("Expected owner to be a, got %v", got)
+	}
+	if got := res.GetNestedField("@metadata", "contact"); got != "x" {
+		t.Errorf("Expected contact to be x, got %v", got)
+	}
+	if got := res.GetNestedField("@metadata", "team.name"); got != "net" {
+		t.Errorf("Expected team.name to be net, got %v", got)
+	}
+
+	conflicts := res.PrecedingComments[0].Conflicts
+	expected := []FieldConflict{
+		{Kind: ConflictNesting, Field: "contact.email", Line: 3, FirstLine: 2},
+		{Kind: ConflictDuplicate, Field: "owner", Line: 3, FirstLine: 2},
+		{Kind: ConflictNesting, Field: "team", Line: 4, FirstLine: 4},
+	}
+	if len(conflicts) != len(expected) {
+		t.Fatalf("Expected %d conflicts, got %d: %+v", len(expected), len(conflicts), conflicts)
+	}
+	for i, want := range expected {
+		got := conflicts[i]
+		if got.Kind != want.Kind || got.Field != want.Field || got.Line != want.Line || got.FirstLine != want.FirstLine {
+			t.Errorf("Conflict %d = %+v, expected %+v", i, got, want)
+		}
+	}
+}
+
+func TestParseFile_MergesRepeatedPrefixes(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	content := `
+# @metadata owner:a
+# @config size:small
+# @metadata team:net owner:b
+resource "test_resource" "repeated" {
+  # @metadata contact.email:a@example.com
+  attribute = "value"
+}
+`
+	filename := "repeated.tf"
+	_ = afero.WriteFile(fs, filename, []byte(content), 0644)
+
+	p := NewCommentParser(fs, []string{"@metadata", "@config"})
+	resources, err := p.ParseFile(filename)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+
+	res := resources[0]
+	expected := map[string]interface{}{
+		"owner":         "a",
+		"team":          "net",
+		"contact.email": "a@example.com",
+	}
+	for path, want := range expected {
+		if got := res.GetNestedField("@metadata", path); got != want {
+			t.Errorf("Expected %s to be %v, got %v", path, want, got)
+		}
+	}
+
+	merged, ok := res.GetAnnotation("@metadata")
+	if !ok {
+		t.Fatal("Expected a merged @metadata annotation")
+	}
+	if merged.Line != 2 || merged.EndLine != 6 {
+		t.Errorf("Expected merged annotation to span lines 2-6, got %d-%d", merged.Line, merged.EndLine)
+	}
+	if len(merged.Conflicts) != 1 || merged.Conflicts[0].Field != "owner" || merged.Conflicts[0].Line != 4 || merged.Conflicts[0].FirstLine != 2 {
+		t.Errorf("Expected the repeated owner to be a conflict, got %+v", merged.Conflicts)
+	}
+
+	// Merging leaves the parsed comments untouched
+	if _, exists := res.PrecedingComments[0].Fields["team"]; exists {
+		t.Error("Merging should not modify the first comment")
+	}
+}
+
+func TestParseFile_AttributeAndBlockComments(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	content := `# @metadata owner:net
+resource "aws_security_group" "web" {
+  # @config tier:frontend
+  name          = "web"
+  instance_type = "m5.4xlarge" # @docs reason:"bursty batch jobs"
+
+  # @validation justification:"public https"
+  ingress {
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+
+  ingress {
+    cidr_blocks = ["10.0.0.0/8"] # @docs note:internal
+  }
+
+  lifecycle {
+    # @docs reason:migrations
+    prevent_destroy = true
+  }
+}
+`
+	filename := "sg.tf"
+	_ = afero.WriteFile(fs, filename, []byte(content), 0644)
+
+	p := NewCommentParser(fs, []string{"@metadata", "@docs", "@validation", "@config"})
+	resources, err := p.ParseFile(filename)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+
+	res := resources[0]
+	if len(res.InlineComments) != 1 || res.InlineComments[0].Prefix != "@config" {
+		t.Errorf("Expected only the @config comment to be a resource inline comment, got %+v", res.InlineComments)
+	}
+	if got := res.Attributes["instance_type"]; got != `"m5.4xlarge"` {
+		t.Errorf("Expected instance_type source text, got %v", got)
+	}
+
+	if comment, ok := res.GetAttributeAnnotation("instance_type", "@docs"); !ok || comment.Fields["reason"] != "bursty batch jobs" {
+		t.Errorf("Expected @docs reason on instance_type, got %+v", comment)
+	}
+	if comment, ok := res.GetAttributeAnnotation("ingress[1].cidr_blocks", "@docs"); !ok || comment.Fields["note"] != "internal" {
+		t.Errorf("Expected @docs note on ingress[1].cidr_blocks, got %+v", comment)
+	}
+
+	expected := []struct {
+		path      string
+		startLine int
+		prefix    string
+	}{
+		{"ingress[0]", 8, "@validation"},
+		{"ingress[1]", 12, ""},
+		{"lifecycle", 16, "@docs"},
+	}
+	if len(res.Blocks) != len(expected) {
+		t.Fatalf("Expected %d nested blocks, got %d: %+v", len(expected), len(res.Blocks), res.Blocks)
+	}
+	for i, want := range expected {
+		block := res.Blocks[i]
+		if block.Path != want.path || block.StartLine != want.startLine {
+			t.Errorf("Block %d = %s at line %d, expected %s at line %d", i, block.Path, block.StartLine, want.path, want.startLine)
+		}
+		if want.prefix == "" {
+			if len(block.Comments) != 0 {
+				t.Errorf("Expected no comments on %s, got %+v", block.Path, block.Comments)
+			}
+			continue
+		}
+		if _, ok := block.GetAnnotation(want.prefix); !ok || len(block.Comments) != 1 {
+			t.Errorf("Expected a single %s comment on %s, got %+v", want.prefix, block.Path, block.Comments)
+		}
+	}
+	if got := res.Blocks[0].Attributes["cidr_blocks"]; got != `["0.0.0.0/0"]` {
+		t.Errorf("Expected ingress[0] cidr_blocks source text, got %v", got)
+	}
+}
+
+func TestParseFile_ModuleAddress(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	content := `resource "aws_subnet" "private" {}`
+	for _, path := range []string{"/live/main.tf", "/modules/vpc/main.tf"} {
+		if err := afero.WriteFile(fs, path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	p := NewCommentParser(fs, []string{"@metadata"})
+	p.SetModuleResolver(func(dir string) string {
+		if dir == "/modules/vpc" {
+			return "module.vpc"
+		}
+		return ""
+	})
+
+	expected := map[string]string{
+		"/live/main.tf":        "aws_subnet.private",
+		"/modules/vpc/main.tf": "module.vpc.aws_subnet.private",
+	}
+	for path, address := range expected {
+		resources, err := p.ParseFile(path)
+		if err != nil {
+			t.Fatalf("ParseFile(%s) failed: %v", path, err)
+		}
+		if got := resources[0].Address(); got != address {
+			t.Errorf("Address() in %s = %q, expected %q", path, got, address)
+		}
+	}
+}
+
+func TestParseFile_InheritsFileHeaderAnnotations(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	content := `# @metadata(file) team:networking contact.email:net@example.com
+
+# @metadata owner:alice contact.slack:"#net"
+resource "aws_vpc" "main" {}
+
+# @metadata team:platform
+resource "aws_subnet" "a" {}
+`
+	if err := afero.WriteFile(fs, "/net/main.tf", []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	p := NewCommentParser(fs, []string{"@metadata"})
+	p.SetInheritanceResolver(func(filename string) []StructuredComment {
+		return []StructuredComment{{
+			Prefix: "@metadata",
+			Fields: map[string]interface{}{"team": "defaults", "cost_center": 42},
+			Source: "directory defaults",
+		}}
+	})
+
+	resources, err := p.ParseFile("/net/main.tf")
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	if len(resources) != 2 {
+		t.Fatalf("Expected 2 resources, got %d", len(resources))
+	}
+
+	vpc := resources[0]
+	if len(vpc.PrecedingComments) != 1 || vpc.PrecedingComments[0].Scope != "" {
+		t.Fatalf("Expected the file header to stay off the resource, got %+v", vpc.PrecedingComments)
+	}
+	if len(vpc.Inherited) != 2 || vpc.Inherited[0].Scope != ScopeFile || vpc.Inherited[0].Source != "file header (/net/main.tf:1)" {
+		t.Fatalf("Expected the file header and then the resolved annotations to be inherited, got %+v", vpc.Inherited)
+	}
+	if got := vpc.Inherited[0].Fields["_content"]; got != "team:networking contact.email:net@example.com" {
+		t.Errorf("Expected the scope to be stripped from the content, got %v", vpc.Inherited[0].Fields["_content"])
+	}
+
+	expected := map[string]struct {
+		value  interface{}
+		source string
+	}{
+		"owner":         {"alice", ""},
+		"contact.slack": {"#net", ""},
+		"contact.email": {"net@example.com", "file header (/net/main.tf:1)"},
+		"team":          {"networking", "file header (/net/main.tf:1)"},
+		"cost_center":   {42, "directory defaults"},
+	}
+	annotation, _ := vpc.GetAnnotation("@metadata")
+	for path, want := range expected {
+		if got := vpc.GetNestedField("@metadata", path); got != want.value {
+			t.Errorf("Effective %s = %v, expected %v", path, got, want.value)
+		}
+		if got := annotation.Sources[path]; got != want.source {
+			t.Errorf("Source of %s = %q, expected %q", path, got, want.source)
+		}
+	}
+	if len(annotation.Conflicts) != 0 {
+		t.Errorf("Expected inherited values to be overridden without conflicts, got %+v", annotation.Conflicts)
+	}
+
+	if got := resources[1].GetNestedField("@metadata", "team"); got != "platform" {
+		t.Errorf("Expected the resource's own team to win, got %v", got)
+	}
+}
+
 func TestParseFile_FileNotFound(t *testing.T) {
 	fs := afero.NewMemMapFs()
 	p := NewCommentParser(fs, []string{"@metadata"})
@@ -87,3 +418,133 @@ func TestParseFile_FileNotFound(t *testing.T) {
 		t.Error("Expected error for non-existent file, got nil")
 	}
 }
+
+func TestParseFile_SplitsCommentRunsAtPrefixes(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	content := `# Leading text without a prefix
+# @metadata owner:team-a
+# contact.email:a@example.com
+# @validation environment:production
+#
+# compliance.soc2:true
+resource "aws_instance" "example" {
+  ami = "ami-123456"
+}
+`
+	_ = afero.WriteFile(fs, "main.tf", []byte(content), 0644)
+
+	p := NewCommentParser(fs, []string{"@metadata", "@validation"})
+	resources, err := p.ParseFile("main.tf")
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+
+	comments := resources[0].PrecedingComments
+	if len(comments) != 2 {
+		t.Fatalf("Expected 2 preceding comments, got %d", len(comments))
+	}
+
+	metadata, validation := comments[0], comments[1]
+	if metadata.Prefix != "@metadata" || metadata.Line != 2 || metadata.EndLine != 3 {
+		t.Errorf("Unexpected @metadata comment: %s lines %d-%d", metadata.Prefix, metadata.Line, metadata.EndLine)
+	}
+	if validation.Prefix != "@validation" || validation.Line != 4 || validation.EndLine != 6 {
+		t.Errorf("Unexpected @validation comment: %s lines %d-%d", validation.Prefix, validation.Line, validation.EndLine)
+	}
+
+	if _, ok := metadata.Fields["environment"]; ok {
+		t.Error("Expected @validation fields not to be merged into @metadata")
+	}
+	if got := resources[0].GetNestedField("@metadata", "contact.email"); got != "a@example.com" {
+		t.Errorf("Expected continuation line to belong to @metadata, got %v", got)
+	}
+	if got := resources[0].GetNestedField("@validation", "compliance.soc2"); got != true {
+		t.Errorf("Expected compliance.soc2 under @validation, got %v", got)
+	}
+}
+
+func TestParseFile_CommentsBelongToNextResourceOnly(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	content := `
+# @metadata owner:network
+resource "aws_vpc" "main" {}
+resource "aws_s3_bucket" "logs" {}
+`
+	_ = afero.WriteFile(fs, "main.tf", []byte(content), 0644)
+
+	p := NewCommentParser(fs, []string{"@metadata"})
+	resources, err := p.ParseFile("main.tf")
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+
+	if len(resources[0].PrecedingComments) != 1 {
+		t.Errorf("Expected aws_vpc.main to have 1 preceding comment, got %d", len(resources[0].PrecedingComments))
+	}
+	if len(resources[1].PrecedingComments) != 0 {
+		t.Errorf("Expected aws_s3_bucket.logs to have no preceding comments, got %d", len(resources[1].PrecedingComments))
+	}
+}
+
+func TestParseFile_Example(t *testing.T) {
+	p := NewCommentParser(afero.NewOsFs(), []string{"@metadata", "@docs", "@validation", "@config"})
+	resources, err := p.ParseFile("../../examples/example.tf")
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+
+	byName := make(map[string]TerraformResource)
+	for _, resource := range resources {
+		byName[resource.Type+"."+resource.Name] = resource
+	}
+
+	tests := []struct {
+		resource string
+		prefixes []string
+	}{
+		{"aws_instance.web_server", []string{"@metadata", "@docs", "@validation"}},
+		{"aws_s3_bucket.data_lake", []string{"@metadata", "@config"}},
+		{"aws_rds_cluster.customer_db", []string{"@docs", "@metadata", "@config", "@validation"}},
+		{"aws_iam_role.app_role", []string{"@metadata", "@validation"}},
+		{"aws_cloudwatch_dashboard.main", []string{"@metadata", "@config", "@validation", "@docs"}},
+	}
+	for _, tt := range tests {
+		resource, ok := byName[tt.resource]
+		if !ok {
+			t.Errorf("Resource %s not found", tt.resource)
+			continue
+		}
+		var prefixes []string
+		for _, comment := range resource.PrecedingComments {
+			prefixes = append(prefixes, comment.Prefix)
+		}
+		if strings.Join(prefixes, " ") != strings.Join(tt.prefixes, " ") {
+			t.Errorf("%s: expected preceding prefixes %v, got %v", tt.resource, tt.prefixes, prefixes)
+		}
+	}
+
+	web := byName["aws_instance.web_server"]
+	if got := web.GetNestedField("@validation", "environment"); got != "production" {
+		t.Errorf("Expected @validation environment:production, got %v", got)
+	}
+	if got := web.GetNestedField("@metadata", "environment"); got != nil {
+		t.Errorf("Expected no environment in @metadata, got %v", got)
+	}
+	if got := web.GetNestedField("@metadata", "contact.email"); got != "alice@example.com" {
+		t.Errorf("Expected @metadata contact.email:alice@example.com, got %v", got)
+	}
+	if got := web.GetNestedField("@docs", "description"); got == nil {
+		t.Error("Expected @docs description on web_server")
+	}
+	if got := web.GetNestedField("@config", "autoscaling"); got != true {
+		t.Errorf("Expected inline @config autoscaling:true, got %v", got)
+	}
+
+	db := byName["aws_rds_cluster.customer_db"]
+	if got := db.GetNestedField("@docs", "_content"); got == nil || !strings.Contains(got.(string), "DBA team") {
+		t.Errorf("Expected @docs continuation lines to belong to @docs, got %v", got)
+	}
+	if got := db.GetNestedField("@validation", "security.encryption_at_rest"); got != true {
+		t.Errorf("Expected @validation security.encryption_at_rest:true, got %v", got)
+	}
+}
@@ -0,0 +1,128 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FieldType is the declared type of an annotation field, used to coerce its value
+type FieldType struct {
+	Type  string // string, integer, float, number, boolean or array; anything else parses literals
+	Items string // Element type of arrays
+}
+
+// TypeResolver returns the declared type of the field at a dotted path under a prefix
+type TypeResolver func(prefix, path string) (FieldType, bool)
+
+var (
+	intLiteral    = regexp.MustCompile(`^[+-]?(0|[1-9][0-9]*)$`)
+	numberLiteral = regexp.MustCompile(`^[+-]?[0-9]+(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+	floatLiteral  = regexp.MustCompile(`^[+-]?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+)
+
+// ParseValue converts a raw annotation value into a bool, int, float64, []interface{} or string.
+// Only whole tokens are converted: true and false, integers without leading zeros, decimals,
+// and [a,b] arrays whose items are parsed the same way. Quoted values ("..." or '...') are
+// always strings, and anything else, such as 555-0123, 02:00, 01234 or 2024-01-31, is kept as
+// written.
+func ParseValue(value string) interface{} {
+	return CoerceValue(value, FieldType{})
+}
+
+// CoerceValue converts a raw annotation value to the declared type. Values that are not valid
+// for the type are returned as the raw string, so validation reports them. An empty type infers
+// the type from the literal like ParseValue.
+func CoerceValue(value string, fieldType FieldType) interface{} {
+	value = strings.TrimSpace(value)
+
+	if unquoted, ok := unquote(value); ok {
+		return unquoted
+	}
+
+	switch fieldType.Type {
+	case "string":
+		return value
+	case "boolean":
+		if value == "true" || value == "false" {
+			return value == "true"
+		}
+		return value
+	case "integer":
+		if numberLiteral.MatchString(value) && !strings.ContainsAny(value, ".eE") {
+			if i, err := strconv.Atoi(value); err == nil {
+				return i
+			}
+		}
+		return value
+	case "float":
+		if numberLiteral.MatchString(value) {
+			if f, err := strconv.ParseFloat(value, 64); err == nil {
+				return f
+			}
+		}
+		return value
+	case "number":
+		if number, ok := parseNumber(value); ok {
+			return number
+		}
+		return value
+	case "array":
+		if items, ok := parseArray(value, FieldType{Type: fieldType.Items}); ok {
+			return items
+		}
+		return value
+	}
+
+	if value == "true" || value == "false" {
+		return value == "true"
+	}
+	if number, ok := parseNumber(value); ok {
+		return number
+	}
+	if items, ok := parseArray(value, FieldType{Type: fieldType.Items}); ok {
+		return items
+	}
+	return value
+}
+
+// parseNumber converts integer and decimal literals, rejecting leading zeros so that
+// identifiers such as zip codes stay strings
+func parseNumber(value string) (interface{}, bool) {
+	if intLiteral.MatchString(value) {
+		if i, err := strconv.Atoi(value); err == nil {
+			return i, true
+		}
+	}
+	if floatLiteral.MatchString(value) {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+// parseArray converts [a,b,c] notation, coercing every item to the item type
+func parseArray(value string, itemType FieldType) ([]interface{}, bool) {
+	if !strings.HasPrefix(value, "[") || !strings.HasSuffix(value, "]") {
+		return nil, false
+	}
+
+	inner := strings.TrimSpace(value[1 : len(value)-1])
+	result := []interface{}{}
+	if inner == "" {
+		return result, true
+	}
+	for _, item := range strings.Split(inner, ",") {
+		result = append(result, CoerceValue(item, itemType))
+	}
+	return result, true
+}
+
+// unquote returns the content of a "double" or 'single' quoted value
+func unquote(value string) (string, bool) {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1], true
+	}
+	return "", false
+}
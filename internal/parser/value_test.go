@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseValue(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected interface{}
+	}{
+		{"true", true},
+		{"false", false},
+		{"42", 42},
+		{"-3", -3},
+		{"0", 0},
+		{"99.99", 99.99},
+		{"1e3", 1000.0},
+		{"infrastructure", "infrastructure"},
+		{"555-0123", "555-0123"},
+		{"02:00", "02:00"},
+		{"01234", "01234"},
+		{"1.2.3", "1.2.3"},
+		{"2024-01-15", "2024-01-15"},
+		{"10s", "10s"},
+		{"True", "True"},
+		{`"42"`, "42"},
+		{`'true'`, "true"},
+		{`"Jane Doe"`, "Jane Doe"},
+		{"[]", []interface{}{}},
+		{"[a,2,true]", []interface{}{"a", 2, true}},
+		{"[01,02]", []interface{}{"01", "02"}},
+	}
+
+	for _, tt := range tests {
+		if got := ParseValue(tt.value); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("ParseValue(%q) = %#v, expected %#v", tt.value, got, tt.expected)
+		}
+	}
+}
+
+func TestCoerceValue(t *testing.T) {
+	tests := []struct {
+		value     string
+		fieldType FieldType
+		expected  interface{}
+	}{
+		{"01234", FieldType{Type: "string"}, "01234"},
+		{"true", FieldType{Type: "string"}, "true"},
+		{"42", FieldType{Type: "string"}, "42"},
+		{"007", FieldType{Type: "integer"}, 7},
+		{"4.5", FieldType{Type: "integer"}, "4.5"},
+		{"abc", FieldType{Type: "integer"}, "abc"},
+		{"99", FieldType{Type: "float"}, 99.0},
+		{"99", FieldType{Type: "number"}, 99},
+		{"yes", FieldType{Type: "boolean"}, "yes"},
+		{"false", FieldType{Type: "boolean"}, false},
+		{"[80,443]", FieldType{Type: "array", Items: "string"}, []interface{}{"80", "443"}},
+		{"[1,2]", FieldType{Type: "array", Items: "float"}, []interface{}{1.0, 2.0}},
+		{"single", FieldType{Type: "array"}, "single"},
+		{`"42"`, FieldType{Type: "integer"}, "42"},
+	}
+
+	for _, tt := range tests {
+		if got := CoerceValue(tt.value, tt.fieldType); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("CoerceValue(%q, %+v) = %#v, expected %#v", tt.value, tt.fieldType, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParseYAMLAnnotation builds an annotation from a YAML mapping of fields, as used by directory
+// defaults and sidecar files. Nested mappings and dotted keys become nested fields, quoted
+// scalars stay strings and sequences become arrays; values are otherwise parsed like comment
+// values. Field lines are the lines of the YAML keys.
+func (cp *CommentParser) ParseYAMLAnnotation(prefix string, node *yaml.Node, source string) (StructuredComment, error) {
+	if node.Kind != yaml.MappingNode {
+		return StructuredComment{}, fmt.Errorf("line %d: fields of %s must be a mapping", node.Line, prefix)
+	}
+
+	values := make(map[string]string)
+	lines := make(map[string]int)
+	if err := flattenYAML(node, "", values, lines); err != nil {
+		return StructuredComment{}, err
+	}
+
+	comment := cp.BuildAnnotation(prefix, values, lines, source)
+	if comment.Line == 0 {
+		comment.Line, comment.EndLine = node.Line, node.Line
+	}
+	return comment, nil
+}
+
+// flattenYAML collects the raw values of a YAML mapping by dotted path. Quoted scalars stay
+// quoted so they parse as strings, and sequences are written in [a,b] notation.
+func flattenYAML(node *yaml.Node, parentPath string, values map[string]string, lines map[string]int) error {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		path := key.Value
+		if parentPath != "" {
+			path = parentPath + "." + key.Value
+		}
+
+		switch value.Kind {
+		case yaml.MappingNode:
+			if err := flattenYAML(value, path, values, lines); err != nil {
+				return err
+			}
+			continue
+		case yaml.SequenceNode:
+			items := make([]string, 0, len(value.Content))
+			for _, item := range value.Content {
+				if item.Kind != yaml.ScalarNode {
+					return fmt.Errorf("line %d: items of '%s' must be scalars", item.Line, path)
+				}
+				items = append(items, yamlScalar(item))
+			}
+			values[path] = "[" + strings.Join(items, ",") + "]"
+		case yaml.ScalarNode:
+			values[path] = yamlScalar(value)
+		default:
+			return fmt.Errorf("line %d: unsupported value for '%s'", value.Line, path)
+		}
+		lines[path] = key.Line
+	}
+	return nil
+}
+
+// yamlScalar returns the raw text of a YAML scalar, quoting quoted strings
+func yamlScalar(node *yaml.Node) string {
+	if node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+		return `"` + node.Value + `"`
+	}
+	return node.Value
+}
@@ -0,0 +1,39 @@
+// Package repo locates the git repository enclosing a path
+package repo
+
+import (
+	"path/filepath"
+
+	"github.com/spf13/afero"
+)
+
+// Root returns the closest directory at or above dir that contains a .git entry, in the same
+// form as dir, relative or absolute. It reports false outside a repository.
+func Root(fs afero.Fs, dir string) (string, bool) {
+	current := filepath.Clean(dir)
+	for {
+		if exists, _ := afero.Exists(fs, filepath.Join(current, ".git")); exists {
+			return current, true
+		}
+		parent := Parent(current)
+		if sameDir(parent, current) {
+			return "", false
+		}
+		current = parent
+	}
+}
+
+// Parent returns the parent directory of dir, keeping relative paths relative, e.g. ".." for "."
+func Parent(dir string) string {
+	if filepath.IsAbs(dir) {
+		return filepath.Dir(dir)
+	}
+	return filepath.Join(dir, "..")
+}
+
+// sameDir reports whether two directories are the same once made absolute
+func sameDir(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
@@ -0,0 +1,52 @@
+package repo
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestRoot(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/repo/.git/HEAD", []byte("ref: refs/heads/main\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := fs.MkdirAll("/repo/modules/vpc", 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := fs.MkdirAll("/elsewhere", 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+
+	tests := []struct {
+		dir  string
+		root string
+		ok   bool
+	}{
+		{"/repo", "/repo", true},
+		{"/repo/modules/vpc", "/repo", true},
+		{"/repo/modules/vpc/", "/repo", true},
+		{"/elsewhere", "", false},
+	}
+	for _, tt := range tests {
+		root, ok := Root(fs, tt.dir)
+		if root != tt.root || ok != tt.ok {
+			t.Errorf("Root(%q) = %q, %v; want %q, %v", tt.dir, root, ok, tt.root, tt.ok)
+		}
+	}
+}
+
+func TestParent(t *testing.T) {
+	tests := map[string]string{
+		"/repo/modules": "/repo",
+		"/":             "/",
+		"modules/vpc":   "modules",
+		".":             "..",
+		"..":            "../..",
+	}
+	for dir, want := range tests {
+		if got := Parent(dir); got != want {
+			t.Errorf("Parent(%q) = %q, want %q", dir, got, want)
+		}
+	}
+}
@@ -0,0 +1,507 @@
+// Package sidecar reads and writes sidecar annotation files, which annotate the resources of a
+// directory without comments in its Terraform files
+package sidecar
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/toozej/terranotate/internal/parser"
+	"gopkg.in/yaml.v3"
+)
+
+// Sidecar file formats
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+)
+
+// FileNames are the sidecar files looked for in a directory, in order
+var FileNames = []string{"terranotate.yaml", "terranotate.json"}
+
+// File is a sidecar file mapping resource addresses to annotations by prefix:
+//
+//	resources:
+//	  aws_vpc.main:
+//	    "@metadata":
+//	      owner: alice
+//	      contact:
+//	        email: alice@example.com
+//
+// Addresses are either relative to the directory's module ("aws_vpc.main") or full
+// ("module.vpc.aws_vpc.main").
+type File struct {
+	Path     string
+	Format   string
+	doc      *yaml.Node
+	modified bool
+}
+
+// Load reads a sidecar file. A missing file loads as an empty file that Bytes creates.
+func Load(fs afero.Fs, path string) (*File, error) {
+	f := &File{Path: path, Format: FormatYAML}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		f.Format = FormatJSON
+	}
+
+	data, err := afero.ReadFile(fs, path)
+	if err != nil {
+		if exists, _ := afero.Exists(fs, path); exists {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		return f, nil
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(doc.Content) > 0 {
+		if doc.Content[0].Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("failed to parse %s: expected a mapping", path)
+		}
+		f.doc = &doc
+	}
+	return f, nil
+}
+
+// Find loads the first sidecar file that exists in a directory, or a new YAML sidecar file
+func Find(fs afero.Fs, dir string) (*File, error) {
+	for _, name := range FileNames {
+		path := filepath.Join(dir, name)
+		if exists, _ := afero.Exists(fs, path); exists {
+			return Load(fs, path)
+		}
+	}
+	return Load(fs, filepath.Join(dir, FileNames[0]))
+}
+
+// Annotations returns the annotations of the entries for any of the given addresses, one per
+// prefix and entry in file order
+func (f *File) Annotations(p *parser.CommentParser, addresses ...string) ([]parser.StructuredComment, error) {
+	var annotations []parser.StructuredComment
+
+	for _, address := range addresses {
+		_, entry := lookup(f.resources(), address)
+		if entry == nil {
+			continue
+		}
+		if entry.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("%s:%d: annotations of %s must map prefixes to fields", f.Path, entry.Line, address)
+		}
+
+		for i := 0; i+1 < len(entry.Content); i += 2 {
+			key := entry.Content[i]
+			source := fmt.Sprintf("sidecar (%s:%d)", f.Path, key.Line)
+			annotation, err := p.ParseYAMLAnnotation(key.Value, entry.Content[i+1], source)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", f.Path, err)
+			}
+			annotations = append(annotations, annotation)
+		}
+	}
+
+	return annotations, nil
+}
+
+// Set adds a field to the annotation of the first given address that has an entry, or of the
+// first address if none has. Fields that are already set are left unchanged, and Set reports
+// whether the field was added.
+func (f *File) Set(addresses []string, prefix, field, value string) bool {
+	resources := f.resources()
+	if resources == nil {
+		if f.doc == nil {
+			f.doc = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+		}
+		resources = appendKey(f.doc.Content[0], "resources", &yaml.Node{Kind: yaml.MappingNode})
+	}
+
+	var entry *yaml.Node
+	for _, address := range addresses {
+		if _, entry = lookup(resources, address); entry != nil {
+			break
+		}
+	}
+	if entry == nil {
+		entry = appendKey(resources, addresses[0], &yaml.Node{Kind: yaml.MappingNode})
+	}
+
+	fields, _ := lookupNode(entry, prefix)
+	if fields == nil {
+		fields = appendKey(entry, prefix, &yaml.Node{Kind: yaml.MappingNode})
+	}
+
+	// Fields are nested under their parent paths, reusing mappings and dotted keys that exist
+	parts := strings.Split(field, ".")
+	current := fields
+	for len(parts) > 1 {
+		if _, exists := lookupNode(current, strings.Join(parts, ".")); exists {
+			return false
+		}
+		next, _ := lookupNode(current, parts[0])
+		if next == nil {
+			next = appendKey(current, parts[0], &yaml.Node{Kind: yaml.MappingNode})
+		}
+		if next.Kind != yaml.MappingNode {
+			return false
+		}
+		current, parts = next, parts[1:]
+	}
+	if _, exists := lookupNode(current, parts[0]); exists {
+		return false
+	}
+
+	appendKey(current, parts[0], valueNode(value))
+	f.modified = true
+	return true
+}
+
+// Has reports whether the entries for any of the given addresses annotate a prefix
+func (f *File) Has(addresses []string, prefix string) bool {
+	return len(f.prefixFields(addresses, prefix)) > 0
+}
+
+// Replace changes the value of a field that is already set in the entries for any of the given
+// addresses and reports whether a value changed
+func (f *File) Replace(addresses []string, prefix, field, value string) bool {
+	changed := false
+	for _, fields := range f.prefixFields(addresses, prefix) {
+		mapping, index := findField(fields, strings.Split(field, "."))
+		if mapping == nil {
+			continue
+		}
+		current := mapping.Content[index+1]
+		if current.Kind == yaml.ScalarNode && current.Value == value {
+			continue
+		}
+		mapping.Content[index+1] = valueNode(value)
+		changed = true
+	}
+	f.modified = f.modified || changed
+	return changed
+}
+
+// Unset removes a field, and the fields nested below it, from the entries for any of the given
+// addresses and reports whether anything was removed
+func (f *File) Unset(addresses []string, prefix, field string) bool {
+	changed := false
+	for _, fields := range f.prefixFields(addresses, prefix) {
+		for {
+			mapping, index := findField(fields, strings.Split(field, "."))
+			if mapping == nil {
+				break
+			}
+			mapping.Content = append(mapping.Content[:index], mapping.Content[index+2:]...)
+			changed = true
+		}
+		changed = removeDottedKeys(fields, field+".") || changed
+	}
+	f.modified = f.modified || changed
+	return changed
+}
+
+// Rename moves a field, and the fields nested below it, to a new name in the entries for any of
+// the given addresses and reports whether anything moved. Fields already set under the new
+// name are left unchanged.
+func (f *File) Rename(addresses []string, prefix, field, name string) bool {
+	changed := false
+	for _, fields := range f.prefixFields(addresses, prefix) {
+		// Dotted keys of fields nested below, e.g. "contact.email" when renaming "contact"
+		for i := 0; i+1 < len(fields.Content); i += 2 {
+			if key := fields.Content[i]; strings.HasPrefix(key.Value, field+".") {
+				key.Value = name + strings.TrimPrefix(key.Value, field)
+				changed = true
+			}
+		}
+
+		mapping, index := findField(fields, strings.Split(field, "."))
+		if mapping == nil {
+			continue
+		}
+		if existing, _ := findField(fields, strings.Split(name, ".")); existing != nil {
+			continue
+		}
+
+		// Keys under the same parent path are renamed in place, keeping their position
+		key, value := mapping.Content[index], mapping.Content[index+1]
+		fieldParts, nameParts := strings.Split(field, "."), strings.Split(name, ".")
+		keyParts := len(strings.Split(key.Value, "."))
+		if len(nameParts) >= keyParts &&
+			strings.Join(fieldParts[:len(fieldParts)-keyParts], ".") == strings.Join(nameParts[:len(nameParts)-keyParts], ".") {
+			key.Value = strings.Join(nameParts[len(nameParts)-keyParts:], ".")
+			changed = true
+			continue
+		}
+
+		// Otherwise the field moves to the new path, unless a value is in the way
+		parent := fields
+		for _, part := range nameParts[:len(nameParts)-1] {
+			next, _ := lookupNode(parent, part)
+			if next == nil {
+				next = appendKey(parent, part, &yaml.Node{Kind: yaml.MappingNode})
+			}
+			if parent = next; parent.Kind != yaml.MappingNode {
+				break
+			}
+		}
+		if parent.Kind != yaml.MappingNode {
+			continue
+		}
+		mapping.Content = append(mapping.Content[:index], mapping.Content[index+2:]...)
+		appendKey(parent, nameParts[len(nameParts)-1], value)
+		changed = true
+	}
+	f.modified = f.modified || changed
+	return changed
+}
+
+// prefixFields returns the field mappings of a prefix in the entries for the given addresses
+func (f *File) prefixFields(addresses []string, prefix string) []*yaml.Node {
+	var mappings []*yaml.Node
+	resources := f.resources()
+	for _, address := range addresses {
+		_, entry := lookup(resources, address)
+		if entry == nil || entry.Kind != yaml.MappingNode {
+			continue
+		}
+		fields, _ := lookupNode(entry, prefix)
+		if fields == nil || fields.Kind != yaml.MappingNode {
+			continue
+		}
+		duplicate := false
+		for _, existing := range mappings {
+			duplicate = duplicate || existing == fields
+		}
+		if !duplicate {
+			mappings = append(mappings, fields)
+		}
+	}
+	return mappings
+}
+
+// findField returns the mapping holding the key of a field path and the index of the key,
+// following nested mappings as well as dotted keys, or nil if the field isn't set
+func findField(mapping *yaml.Node, parts []string) (*yaml.Node, int) {
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		return nil, -1
+	}
+	for i := len(parts); i > 0; i-- {
+		key := strings.Join(parts[:i], ".")
+		for j := 0; j+1 < len(mapping.Content); j += 2 {
+			if mapping.Content[j].Value != key {
+				continue
+			}
+			if i == len(parts) {
+				return mapping, j
+			}
+			if found, index := findField(mapping.Content[j+1], parts[i:]); found != nil {
+				return found, index
+			}
+		}
+	}
+	return nil, -1
+}
+
+// removeDottedKeys removes the keys of a mapping that start with a dotted prefix and reports
+// whether any were removed
+func removeDottedKeys(mapping *yaml.Node, prefix string) bool {
+	removed := false
+	for i := 0; i+1 < len(mapping.Content); {
+		if strings.HasPrefix(mapping.Content[i].Value, prefix) {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			removed = true
+			continue
+		}
+		i += 2
+	}
+	return removed
+}
+
+// Modified reports whether the file was changed since it was loaded
+func (f *File) Modified() bool {
+	return f.modified
+}
+
+// valueNode returns the node for a field value written in comment syntax, where "[a,b]" is an
+// array
+func valueNode(value string) *yaml.Node {
+	if !strings.HasPrefix(value, "[") || !strings.HasSuffix(value, "]") {
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+	}
+
+	node := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+	for _, item := range strings.Split(strings.Trim(value, "[]"), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: item})
+		}
+	}
+	return node
+}
+
+// Bytes encodes the file in its format
+func (f *File) Bytes() ([]byte, error) {
+	if f.doc == nil {
+		f.doc = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+
+	if f.Format == FormatJSON {
+		var buf bytes.Buffer
+		writeJSON(&buf, f.doc.Content[0], "")
+		buf.WriteString("\n")
+		return buf.Bytes(), nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(f.doc); err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", f.Path, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// resources returns the resources mapping, or nil if there is none
+func (f *File) resources() *yaml.Node {
+	if f.doc == nil {
+		return nil
+	}
+	resources, _ := lookupNode(f.doc.Content[0], "resources")
+	if resources == nil || resources.Kind != yaml.MappingNode {
+		return nil
+	}
+	return resources
+}
+
+// lookup returns the key and value nodes of an entry in a mapping
+func lookup(mapping *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if mapping == nil {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i], mapping.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// lookupNode returns the value of an entry in a mapping and whether it exists
+func lookupNode(mapping *yaml.Node, key string) (*yaml.Node, bool) {
+	_, value := lookup(mapping, key)
+	return value, value != nil
+}
+
+// appendKey adds an entry to a mapping and returns its value
+func appendKey(mapping *yaml.Node, key string, value *yaml.Node) *yaml.Node {
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	return value
+}
+
+// writeJSON writes a YAML node as indented JSON, keeping the order of keys. Plain scalars that
+// YAML resolves to numbers, booleans or null are written as such; everything else is a string.
+func writeJSON(buf *bytes.Buffer, node *yaml.Node, indent string) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("{}")
+			return
+		}
+		buf.WriteString("{\n")
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, _ := json.Marshal(node.Content[i].Value)
+			fmt.Fprintf(buf, "%s  %s: ", indent, key)
+			writeJSON(buf, node.Content[i+1], indent+"  ")
+			if i+2 < len(node.Content) {
+				buf.WriteString(",")
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString(indent + "}")
+	case yaml.SequenceNode:
+		buf.WriteString("[")
+		for i, item := range node.Content {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			writeJSON(buf, item, indent)
+		}
+		buf.WriteString("]")
+	default:
+		if node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) == 0 {
+			switch node.ShortTag() {
+			case "!!int", "!!float", "!!bool", "!!null":
+				buf.WriteString(node.Value)
+				return
+			}
+		}
+		value, _ := json.Marshal(node.Value)
+		buf.Write(value)
+	}
+}
+
+// Index resolves the sidecar annotations of resources, loading each directory's sidecar files
+// once
+type Index struct {
+	fs     afero.Fs
+	parser *parser.CommentParser
+	files  map[string][]*File // Sidecar files by directory
+}
+
+// New creates a sidecar index. The parser parses sidecar values, so its declared types apply.
+func New(fs afero.Fs, p *parser.CommentParser) *Index {
+	return &Index{fs: fs, parser: p, files: make(map[string][]*File)}
+}
+
+// Addresses returns the addresses a resource's sidecar entries may use: relative to its
+// directory's module, then full if different
+func Addresses(resource parser.TerraformResource) []string {
+	addresses := []string{resource.Type + "." + resource.Name}
+	if address := resource.Address(); address != addresses[0] {
+		addresses = append(addresses, address)
+	}
+	return addresses
+}
+
+// Annotations returns the sidecar annotations of a resource from the sidecar files in the
+// directory of its file, matching either its relative or its full address
+func (idx *Index) Annotations(resource parser.TerraformResource) []parser.StructuredComment {
+	var annotations []parser.StructuredComment
+	for _, f := range idx.load(filepath.Dir(resource.File)) {
+		found, err := f.Annotations(idx.parser, Addresses(resource)...)
+		if err != nil {
+			log.Printf("Warning: Failed to load sidecar annotations: %v", err)
+			continue
+		}
+		annotations = append(annotations, found...)
+	}
+	return annotations
+}
+
+// load returns the sidecar files that exist in a directory
+func (idx *Index) load(dir string) []*File {
+	if files, exists := idx.files[dir]; exists {
+		return files
+	}
+
+	var files []*File
+	for _, name := range FileNames {
+		path := filepath.Join(dir, name)
+		if exists, _ := afero.Exists(idx.fs, path); !exists {
+			continue
+		}
+		f, err := Load(idx.fs, path)
+		if err != nil {
+			log.Printf("Warning: %v", err)
+			continue
+		}
+		files = append(files, f)
+	}
+
+	idx.files[dir] = files
+	return files
+}
@@ -0,0 +1,226 @@
+package sidecar
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/toozej/terranotate/internal/parser"
+)
+
+// newParser writes the files and returns a parser that resolves sidecar annotations
+func newParser(t *testing.T, files map[string]string) (afero.Fs, *parser.CommentParser) {
+	t.Helper()
+	fs := afero.NewMemMapFs()
+	for path, content := range files {
+		if err := afero.WriteFile(fs, path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+
+	p := parser.NewCommentParser(fs, []string{"@metadata", "@docs"})
+	p.SetSidecarResolver(New(fs, p).Annotations)
+	return fs, p
+}
+
+func TestAnnotationsMergeWithComments(t *testing.T) {
+	_, p := newParser(t, map[string]string{
+		"/vpc/main.tf": `
+# @metadata owner:alice
+resource "aws_vpc" "main" {}
+
+resource "aws_subnet" "private" {}
+`,
+		"/vpc/terranotate.yaml": `
+resources:
+  aws_vpc.main:
+    "@metadata":
+      team: networking
+      contact:
+        email: net@example.com
+  aws_subnet.private:
+    "@docs":
+      description: Private subnet
+`,
+	})
+
+	resources, err := p.ParseFile("/vpc/main.tf")
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	vpc, subnet := resources[0], resources[1]
+
+	if got := vpc.GetNestedField("@metadata", "owner"); got != "alice" {
+		t.Errorf("owner = %v, expected the comment value", got)
+	}
+	if got := vpc.GetNestedField("@metadata", "contact.email"); got != "net@example.com" {
+		t.Errorf("contact.email = %v, expected the sidecar value", got)
+	}
+	annotation, _ := vpc.GetAnnotation("@metadata")
+	if got := annotation.Sources["team"]; got != "sidecar (/vpc/terranotate.yaml:4)" {
+		t.Errorf("Source of team = %q", got)
+	}
+	if annotation.Line != vpc.StartLine-1 {
+		t.Errorf("Expected the annotation to keep the comment's line, got %d", annotation.Line)
+	}
+
+	if got := subnet.GetNestedField("@docs", "description"); got != "Private subnet" {
+		t.Errorf("description = %v, expected a sidecar-only annotation", got)
+	}
+	if desc, _ := subnet.GetAnnotation("@docs"); desc.Line != subnet.StartLine {
+		t.Errorf("Expected a sidecar-only annotation at the resource's line, got %d", desc.Line)
+	}
+}
+
+func TestAnnotationsReportConflicts(t *testing.T) {
+	_, p := newParser(t, map[string]string{
+		"/vpc/main.tf": `
+# @metadata owner:alice
+resource "aws_vpc" "main" {}
+`,
+		"/vpc/terranotate.json": `{"resources": {"module.vpc.aws_vpc.main": {"@metadata": {"owner": "bob"}}}}`,
+	})
+	p.SetModuleResolver(func(dir string) string { return "module.vpc" })
+
+	resources, err := p.ParseFile("/vpc/main.tf")
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+
+	annotation, _ := resources[0].GetAnnotation("@metadata")
+	if annotation.Fields["owner"] != "alice" {
+		t.Errorf("Expected the comment value to win, got %v", annotation.Fields["owner"])
+	}
+	if len(annotation.Conflicts) != 1 || !strings.Contains(annotation.Conflicts[0].Message, "sidecar (/vpc/terranotate.json:1)") {
+		t.Errorf("Expected a conflict naming the sidecar file, got %v", annotation.Conflicts)
+	}
+}
+
+func TestSet(t *testing.T) {
+	fs, _ := newParser(t, map[string]string{
+		"/vpc/terranotate.yaml": `
+resources:
+  aws_vpc.main:
+    "@metadata":
+      owner: alice
+`,
+	})
+
+	f, err := Find(fs, "/vpc")
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	addresses := []string{"aws_vpc.main", "module.vpc.aws_vpc.main"}
+	if f.Set(addresses, "@metadata", "owner", "bob") {
+		t.Error("Expected an existing field to be left unchanged")
+	}
+	if !f.Set(addresses, "@metadata", "contact.email", "changeme@example.com") {
+		t.Error("Expected a missing field to be added")
+	}
+	f.Set(addresses, "@metadata", "contact.slack", "@changeme")
+	f.Set([]string{"aws_subnet.private"}, "@docs", "tags", "[a,b]")
+
+	content, err := f.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes failed: %v", err)
+	}
+	expected := `resources:
+  aws_vpc.main:
+    "@metadata":
+      owner: alice
+      contact:
+        email: changeme@example.com
+        slack: '@changeme'
+  aws_subnet.private:
+    '@docs':
+      tags: [a, b]
+`
+	if string(content) != expected {
+		t.Errorf("Unexpected YAML:\n%s", content)
+	}
+}
+
+func TestReplaceUnsetRename(t *testing.T) {
+	fs, _ := newParser(t, map[string]string{
+		"/vpc/terranotate.yaml": `
+resources:
+  aws_vpc.main:
+    "@metadata":
+      owner: alice
+      cost_center: cc-1
+      contact:
+        email: net@example.com
+      contact.slack: "#net"
+`,
+	})
+
+	f, err := Find(fs, "/vpc")
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	addresses := []string{"aws_vpc.main", "module.vpc.aws_vpc.main"}
+
+	if !f.Replace(addresses, "@metadata", "owner", "bob") {
+		t.Error("Expected an existing field to be replaced")
+	}
+	if f.Replace(addresses, "@metadata", "team", "net") {
+		t.Error("Expected a missing field to be left unset")
+	}
+	if !f.Rename(addresses, "@metadata", "cost_center", "costcenter") {
+		t.Error("Expected cost_center to be renamed")
+	}
+	if !f.Rename(addresses, "@metadata", "contact", "support") {
+		t.Error("Expected contact and the fields below it to be renamed")
+	}
+	if !f.Unset(addresses, "@metadata", "support.slack") {
+		t.Error("Expected a dotted key to be removed")
+	}
+	if f.Unset([]string{"aws_subnet.private"}, "@metadata", "owner") {
+		t.Error("Expected nothing to be removed for a resource without an entry")
+	}
+
+	content, err := f.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes failed: %v", err)
+	}
+	expected := `resources:
+  aws_vpc.main:
+    "@metadata":
+      owner: bob
+      costcenter: cc-1
+      support:
+        email: net@example.com
+`
+	if string(content) != expected {
+		t.Errorf("Unexpected YAML:\n%s", content)
+	}
+}
+
+func TestSetJSON(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	f, err := Load(fs, "/vpc/terranotate.json")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	f.Set([]string{"aws_vpc.main"}, "@metadata", "owner", "CHANGEME")
+	f.Set([]string{"aws_vpc.main"}, "@metadata", "replicas", "3")
+
+	content, err := f.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes failed: %v", err)
+	}
+	expected := `{
+  "resources": {
+    "aws_vpc.main": {
+      "@metadata": {
+        "owner": "CHANGEME",
+        "replicas": 3
+      }
+    }
+  }
+}
+`
+	if string(content) != expected {
+		t.Errorf("Unexpected JSON:\n%s", content)
+	}
+}
@@ -0,0 +1,195 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/toozej/terranotate/internal/parser"
+)
+
+// AttributeRule requires annotations on the attributes or nested blocks of resources, e.g. every
+// ingress block open to 0.0.0.0/0 must carry a @validation justification
+type AttributeRule struct {
+	ResourceTypes    []string              `yaml:"resource_types"` // Resource types the rule applies to; empty for all
+	Block            string                `yaml:"block"`          // Nested block type, e.g. "ingress"
+	Attribute        string                `yaml:"attribute"`      // Attribute name; inside Block when both are set
+	When             []AttributeCondition  `yaml:"when"`           // Conditions that must all hold for the rule to apply
+	RequiredPrefixes []string              `yaml:"required_prefixes"`
+	PrefixRules      map[string]PrefixRule `yaml:"prefix_rules"`
+	PrefixOrder      []string              `yaml:"-"` // Declaration order of PrefixRules, recorded when decoding
+}
+
+// AttributeCondition tests the source text of an attribute next to the annotated one
+type AttributeCondition struct {
+	Attribute string `yaml:"attribute"` // Attribute to test; defaults to the rule's attribute
+	Contains  string `yaml:"contains"`  // Text the value must contain
+	Matches   string `yaml:"matches"`   // Regular expression the value must match
+}
+
+// check reports rules that can never apply or have invalid patterns
+func (rule AttributeRule) check() error {
+	if rule.Block == "" && rule.Attribute == "" {
+		return fmt.Errorf("a block or an attribute is required")
+	}
+	for _, condition := range rule.When {
+		if condition.Attribute == "" && rule.Attribute == "" {
+			return fmt.Errorf("conditions on block '%s' need an attribute", rule.Block)
+		}
+		if condition.Matches != "" {
+			if _, err := regexp.Compile(condition.Matches); err != nil {
+				return fmt.Errorf("invalid pattern '%s': %w", condition.Matches, err)
+			}
+		}
+	}
+	return nil
+}
+
+// appliesTo reports whether the rule applies to a resource type
+func (rule AttributeRule) appliesTo(resourceType string) bool {
+	if len(rule.ResourceTypes) == 0 {
+		return true
+	}
+	for _, t := range rule.ResourceTypes {
+		if t == resourceType {
+			return true
+		}
+	}
+	return false
+}
+
+// attributeTarget is an attribute or nested block an attribute rule applies to
+type attributeTarget struct {
+	path       string
+	line       int
+	attributes map[string]interface{} // Attributes the rule's conditions are tested against
+	comments   []parser.StructuredComment
+}
+
+// targets returns the attributes or nested blocks of a resource the rule selects
+func (rule AttributeRule) targets(resource parser.TerraformResource) []attributeTarget {
+	var targets []attributeTarget
+
+	if rule.Block == "" {
+		if _, exists := resource.Attributes[rule.Attribute]; exists {
+			targets = append(targets, attributeTarget{
+				path:       rule.Attribute,
+				line:       resource.StartLine,
+				attributes: resource.Attributes,
+				comments:   resource.AttributeComments[rule.Attribute],
+			})
+		}
+		return targets
+	}
+
+	for _, block := range resource.Blocks {
+		if block.Type != rule.Block && strings.Join(append([]string{block.Type}, block.Labels...), ".") != rule.Block {
+			continue
+		}
+		target := attributeTarget{path: block.Path, line: block.StartLine, attributes: block.Attributes, comments: block.Comments}
+		if rule.Attribute != "" {
+			if _, exists := block.Attributes[rule.Attribute]; !exists {
+				continue
+			}
+			target.path = block.Path + "." + rule.Attribute
+			target.comments = resource.AttributeComments[target.path]
+		}
+		targets = append(targets, target)
+	}
+	return targets
+}
+
+// matches reports whether every condition holds for the target
+func (rule AttributeRule) matches(target attributeTarget) bool {
+	for _, condition := range rule.When {
+		name := condition.Attribute
+		if name == "" {
+			name = rule.Attribute
+		}
+		value, exists := target.attributes[name]
+		if !exists {
+			return false
+		}
+		text := fmt.Sprintf("%v", value)
+		if condition.Contains != "" && !strings.Contains(text, condition.Contains) {
+			return false
+		}
+		if condition.Matches != "" {
+			if matched, _ := regexp.MatchString(condition.Matches, text); !matched {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// validateAttributes validates the annotations on a resource's attributes and nested blocks:
+// the field values and keys of every annotation, and the schema's attribute rules
+func (sv *SchemaValidator) validateAttributes(resource parser.TerraformResource) []ValidationError {
+	var errors []ValidationError
+
+	paths := make([]string, 0, len(resource.AttributeComments))
+	for path := range resource.AttributeComments {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		errors = append(errors, sv.validateAnnotations(resource, path, resource.AttributeComments[path])...)
+	}
+	for _, block := range resource.Blocks {
+		errors = append(errors, sv.validateAnnotations(resource, block.Path, block.Comments)...)
+	}
+
+	for _, rule := range sv.schema.AttributeRules {
+		if !rule.appliesTo(resource.Type) {
+			continue
+		}
+		for _, target := range rule.targets(resource) {
+			if rule.matches(target) {
+				errors = append(errors, sv.validateAttributeRule(resource, rule, target)...)
+			}
+		}
+	}
+
+	return errors
+}
+
+// validateAnnotations validates the field values and keys of the comments on an attribute or
+// nested block
+func (sv *SchemaValidator) validateAnnotations(resource parser.TerraformResource, path string, comments []parser.StructuredComment) []ValidationError {
+	var errors []ValidationError
+	scoped := parser.TerraformResource{Type: resource.Type, Name: resource.Name, InlineComments: comments}
+
+	for _, comment := range comments {
+		errors = append(errors, sv.validateFieldValues(resource, comment, comment.Prefix)...)
+	}
+	errors = append(errors, sv.checkKeys(scoped)...)
+
+	return qualify(path, errors)
+}
+
+// validateAttributeRule validates the annotations of an attribute or nested block an attribute
+// rule applies to
+func (sv *SchemaValidator) validateAttributeRule(resource parser.TerraformResource, rule AttributeRule, target attributeTarget) []ValidationError {
+	var errors []ValidationError
+	scoped := parser.TerraformResource{Type: resource.Type, Name: resource.Name, StartLine: target.line, InlineComments: target.comments}
+
+	errors = append(errors, sv.checkRequiredPrefixes(scoped, ResourceRules{RequiredPrefixes: rule.RequiredPrefixes})...)
+	for _, prefix := range rule.OrderedPrefixes() {
+		if comment, exists := scoped.GetAnnotation(prefix); exists {
+			errors = append(errors, sv.validatePrefixFields(resource, comment, prefix, rule.PrefixRules[prefix])...)
+		}
+	}
+
+	return qualify(target.path, errors)
+}
+
+// qualify scopes errors to an attribute or nested block path. Fields become "path/prefix:field".
+func qualify(path string, errors []ValidationError) []ValidationError {
+	for i := range errors {
+		errors[i].Field = path + "/" + errors[i].Field
+		errors[i].Message = path + ": " + errors[i].Message
+	}
+	return errors
+}
@@ -0,0 +1,109 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/toozej/terranotate/internal/parser"
+)
+
+func TestValidateResources_AttributeRules(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	schemaContent := `attribute_rules:
+  - block: ingress
+    resource_types: [aws_security_group]
+    when:
+      - attribute: cidr_blocks
+        contains: 0.0.0.0/0
+    required_prefixes: ["@validation"]
+    prefix_rules:
+      "@validation":
+        required_fields: [justification]
+  - attribute: instance_type
+    when:
+      - matches: '"m5\.(4|8)xlarge"'
+    prefix_rules:
+      "@docs":
+        required_fields: [reason]
+field_validations:
+  "@docs:reason":
+    type: string
+    min_length: 5
+`
+	if err := afero.WriteFile(fs, "/schema.yaml", []byte(schemaContent), 0644); err != nil {
+		t.Fatalf("failed to write schema file: %v", err)
+	}
+	validator, err := NewSchemaValidator(fs, "/schema.yaml")
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+
+	src := []byte(`resource "aws_security_group" "web" {
+  instance_type = "m5.4xlarge" # @docs why:batch
+
+  # @validation justification:"public https"
+  ingress {
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+
+  ingress {
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+
+  ingress {
+    cidr_blocks = ["10.0.0.0/8"]
+  }
+
+  lifecycle {
+    prevent_destroy = true # @docs reason:ok
+  }
+}
+`)
+	p := parser.NewCommentParser(fs, []string{"@docs", "@validation"})
+	resources, err := p.ParseSource("main.tf", src)
+	if err != nil {
+		t.Fatalf("ParseSource failed: %v", err)
+	}
+
+	result := validator.ValidateResources(resources)
+	expected := []struct {
+		field string
+		rule  string
+		line  int
+	}{
+		{"lifecycle.prevent_destroy/@docs:reason", RuleMinLength, 18},
+		{"ingress[1]/@validation", RuleMissingPrefix, 9},
+		{"instance_type/@docs:reason", RuleMissingField, 2},
+	}
+	if len(result.Errors) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(expected), len(result.Errors), result.Errors)
+	}
+	for i, want := range expected {
+		got := result.Errors[i]
+		if got.Field != want.field || got.Rule != want.rule || got.Line != want.line {
+			t.Errorf("Error %d = %s %s on line %d, expected %s %s on line %d", i, got.Field, got.Rule, got.Line, want.field, want.rule, want.line)
+		}
+	}
+
+	// Rules only apply to their resource types
+	resources[0].Type = "aws_instance"
+	if result := validator.ValidateResources(resources); len(result.Errors) != 2 {
+		t.Errorf("Expected the ingress rule to be skipped for aws_instance, got %v", result.Errors)
+	}
+}
+
+func TestNewSchemaValidator_InvalidAttributeRules(t *testing.T) {
+	tests := []AttributeRule{
+		{RequiredPrefixes: []string{"@docs"}},
+		{Block: "ingress", When: []AttributeCondition{{Contains: "0.0.0.0/0"}}},
+		{Attribute: "instance_type", When: []AttributeCondition{{Matches: "("}}},
+	}
+
+	for i, rule := range tests {
+		schema := ValidationSchema{AttributeRules: []AttributeRule{rule}}
+		if _, err := NewSchemaValidatorFromSchema(nil, schema); err == nil {
+			t.Errorf("Expected error for invalid attribute rule %d", i)
+		}
+	}
+}
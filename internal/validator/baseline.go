@@ -0,0 +1,144 @@
+package validator
+
+import (
+	"path/filepath"
+	"sort"
+)
+
+// Baseline records known violations so that only new ones fail validation. Violations are
+// identified by file, resource address, rule and field, never by line number or message, so
+// unrelated edits do not invalidate the baseline.
+type Baseline struct {
+	Violations []BaselineEntry `json:"violations"`
+}
+
+// BaselineEntry is a known violation and how many times it occurs
+type BaselineEntry struct {
+	File     string `json:"file"`     // Slash-separated path relative to the baseline's root
+	Resource string `json:"resource"` // Resource address, e.g. "aws_vpc.main" or "module.vpc.aws_subnet.private"
+	Rule     string `json:"rule"`
+	Field    string `json:"field,omitempty"`
+	Count    int    `json:"count"`
+}
+
+// baselineKey identifies a violation independently of its line number and message
+type baselineKey struct {
+	File, Resource, Rule, Field string
+}
+
+// key returns the identity of a baseline entry
+func (e BaselineEntry) key() baselineKey {
+	return baselineKey{File: e.File, Resource: e.Resource, Rule: e.Rule, Field: e.Field}
+}
+
+// violationKey returns the identity of a validation error, with its file relative to root
+func violationKey(root string, err ValidationError) baselineKey {
+	file := err.File
+	if root != "" {
+		if abs, absErr := filepath.Abs(file); absErr == nil {
+			if rel, relErr := filepath.Rel(root, abs); relErr == nil {
+				file = rel
+			}
+		}
+	}
+	return baselineKey{
+		File:     filepath.ToSlash(file),
+		Resource: err.Address(),
+		Rule:     err.Rule,
+		Field:    err.Field,
+	}
+}
+
+// NewBaseline records the given errors. File paths are stored relative to root.
+func NewBaseline(root string, errors []ValidationError) Baseline {
+	counts := make(map[baselineKey]int)
+	for _, err := range errors {
+		counts[violationKey(root, err)]++
+	}
+	return baselineFromCounts(counts)
+}
+
+// Filter removes errors recorded in the baseline from result, returning the remaining result
+// and the number of errors suppressed. Warnings are kept as they never fail validation.
+func (b Baseline) Filter(root string, result ValidationResult) (ValidationResult, int) {
+	remaining := b.counts()
+	filtered := ValidationResult{Passed: true, Warnings: result.Warnings}
+	suppressed := 0
+
+	for _, err := range result.Errors {
+		key := violationKey(root, err)
+		if remaining[key] > 0 {
+			remaining[key]--
+			suppressed++
+			continue
+		}
+		filtered.Errors = append(filtered.Errors, err)
+		filtered.Passed = false
+	}
+
+	return filtered, suppressed
+}
+
+// Prune returns the baseline without the violations that no longer occur in result, and the
+// number of violations removed. New violations are not added.
+func (b Baseline) Prune(root string, result ValidationResult) (Baseline, int) {
+	current := make(map[baselineKey]int)
+	for _, err := range result.Errors {
+		current[violationKey(root, err)]++
+	}
+
+	counts := make(map[baselineKey]int)
+	pruned := 0
+	for key, count := range b.counts() {
+		kept := count
+		if current[key] < kept {
+			kept = current[key]
+		}
+		pruned += count - kept
+		if kept > 0 {
+			counts[key] = kept
+		}
+	}
+
+	return baselineFromCounts(counts), pruned
+}
+
+// Size returns the total number of violations recorded in the baseline
+func (b Baseline) Size() int {
+	size := 0
+	for _, entry := range b.Violations {
+		size += entry.Count
+	}
+	return size
+}
+
+// counts returns the baseline's violation counts by identity
+func (b Baseline) counts() map[baselineKey]int {
+	counts := make(map[baselineKey]int)
+	for _, entry := range b.Violations {
+		counts[entry.key()] += entry.Count
+	}
+	return counts
+}
+
+// baselineFromCounts builds a baseline with entries sorted for stable diffs
+func baselineFromCounts(counts map[baselineKey]int) Baseline {
+	b := Baseline{Violations: []BaselineEntry{}}
+	for key, count := range counts {
+		b.Violations = append(b.Violations, BaselineEntry{File: key.File, Resource: key.Resource, Rule: key.Rule, Field: key.Field, Count: count})
+	}
+	sort.Slice(b.Violations, func(i, j int) bool {
+		a, c := b.Violations[i], b.Violations[j]
+		if a.File != c.File {
+			return a.File < c.File
+		}
+		if a.Resource != c.Resource {
+			return a.Resource < c.Resource
+		}
+		if a.Rule != c.Rule {
+			return a.Rule < c.Rule
+		}
+		return a.Field < c.Field
+	})
+	return b
+}
@@ -0,0 +1,48 @@
+package validator
+
+import "testing"
+
+func TestBaselineFilterAndPrune(t *testing.T) {
+	missingOwner := ValidationError{File: "/repo/main.tf", ResourceType: "aws_vpc", ResourceName: "main", Line: 3, Rule: RuleMissingField, Field: "@metadata:owner", Message: "missing owner", Severity: "error"}
+	missingDocs := ValidationError{File: "/repo/main.tf", ResourceType: "aws_s3_bucket", ResourceName: "logs", Line: 9, Rule: RuleMissingPrefix, Field: "@docs", Message: "missing @docs", Severity: "error"}
+
+	baseline := NewBaseline("/repo", []ValidationError{missingOwner, missingDocs})
+	if baseline.Size() != 2 {
+		t.Fatalf("Expected 2 violations in baseline, got %d", baseline.Size())
+	}
+	if entry := baseline.Violations[0]; entry.File != "main.tf" || entry.Resource != "aws_s3_bucket.logs" || entry.Rule != RuleMissingPrefix {
+		t.Errorf("Unexpected first baseline entry: %+v", entry)
+	}
+
+	// Known violations are suppressed even when their line changes
+	moved := missingOwner
+	moved.Line = 42
+	newViolation := ValidationError{File: "/repo/network.tf", ResourceType: "aws_subnet", ResourceName: "a", Rule: RuleMissingField, Field: "@metadata:owner", Severity: "error"}
+	warning := ValidationError{File: "/repo/main.tf", ResourceType: "aws_vpc", ResourceName: "main", Rule: RulePlaceholder, Severity: "warning"}
+
+	filtered, suppressed := baseline.Filter("/repo", ValidationResult{
+		Errors:   []ValidationError{moved, missingDocs, newViolation},
+		Warnings: []ValidationError{warning},
+	})
+	if suppressed != 2 {
+		t.Errorf("Expected 2 suppressed violations, got %d", suppressed)
+	}
+	if filtered.Passed || len(filtered.Errors) != 1 || filtered.Errors[0].ResourceName != "a" {
+		t.Errorf("Expected only the new violation to remain, got %+v", filtered.Errors)
+	}
+	if len(filtered.Warnings) != 1 {
+		t.Errorf("Expected warnings to be kept, got %d", len(filtered.Warnings))
+	}
+
+	// A violation recorded once only suppresses one occurrence
+	filtered, _ = baseline.Filter("/repo", ValidationResult{Errors: []ValidationError{missingOwner, missingOwner}})
+	if len(filtered.Errors) != 1 {
+		t.Errorf("Expected the second occurrence to be reported, got %d errors", len(filtered.Errors))
+	}
+
+	// Fixed violations are pruned and new ones are not added
+	pruned, removed := baseline.Prune("/repo", ValidationResult{Errors: []ValidationError{missingOwner, newViolation}})
+	if removed != 1 || pruned.Size() != 1 || pruned.Violations[0].Resource != "aws_vpc.main" {
+		t.Errorf("Expected only aws_vpc.main to remain after pruning, removed %d: %+v", removed, pruned.Violations)
+	}
+}
@@ -0,0 +1,304 @@
+package validator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/toozej/terranotate/internal/parser"
+)
+
+// CoverageReport summarizes how completely resources are annotated, per prefix and per field.
+// Prefixes and fields only count towards resources whose schema rules declare them.
+type CoverageReport struct {
+	Resources int              `json:"resources"`
+	Annotated int              `json:"annotated"` // Resources with every required prefix
+	Percent   float64          `json:"percent"`   // Annotated resources as a percentage of all resources
+	Prefixes  []PrefixCoverage `json:"prefixes"`
+}
+
+// PrefixCoverage is the coverage of a single comment prefix
+type PrefixCoverage struct {
+	Prefix    string          `json:"prefix"`
+	Required  bool            `json:"required"`
+	Resources int             `json:"resources"` // Resources the schema declares the prefix for
+	Present   int             `json:"present"`   // Resources with an annotation using the prefix, written or inherited
+	Percent   float64         `json:"percent"`
+	Fields    []FieldCoverage `json:"fields,omitempty"`
+}
+
+// FieldCoverage is the coverage of a single field, using a dotted path for nested fields
+type FieldCoverage struct {
+	Field         string  `json:"field"`
+	Required      bool    `json:"required"`
+	Resources     int     `json:"resources"` // Resources the schema declares the field for
+	Present       int     `json:"present"`   // Resources that set the field
+	Filled        int     `json:"filled"`    // Resources that set the field to a non-placeholder value
+	Percent       float64 `json:"percent"`
+	FilledPercent float64 `json:"filled_percent"`
+}
+
+// coverageField is a field declared by a prefix rule
+type coverageField struct {
+	path     string
+	required bool
+}
+
+// Coverage computes annotation coverage for the given resources
+func (sv *SchemaValidator) Coverage(resources []parser.TerraformResource) CoverageReport {
+	report := CoverageReport{Prefixes: sv.coverageSkeleton()}
+
+	prefixIndex := make(map[string]int)
+	fieldIndex := make(map[string]int)
+	for i, pc := range report.Prefixes {
+		prefixIndex[pc.Prefix] = i
+		for j, fc := range pc.Fields {
+			fieldIndex[pc.Prefix+":"+fc.Field] = j
+		}
+	}
+
+	for _, resource := range resources {
+		rules := sv.getApplicableRules(resource.Type)
+		report.Resources++
+
+		annotated := true
+		for _, prefix := range rulePrefixes(rules) {
+			pc := &report.Prefixes[prefixIndex[prefix]]
+			// Inherited annotations count, as they do for validation
+			annotation, present := resource.GetAnnotation(prefix)
+
+			pc.Resources++
+			if present {
+				pc.Present++
+			} else if sv.isPrefixRequired(prefix, rules) {
+				annotated = false
+			}
+
+			for _, field := range ruleFields(rules.PrefixRules[prefix]) {
+				fc := &pc.Fields[fieldIndex[prefix+":"+field.path]]
+				fc.Resources++
+
+				value, set := annotationFieldValue(annotation, field.path)
+				if !set {
+					continue
+				}
+				fc.Present++
+				if !sv.containsPlaceholder(value) {
+					fc.Filled++
+				}
+			}
+		}
+		if annotated {
+			report.Annotated++
+		}
+	}
+
+	report.Percent = percentOf(report.Annotated, report.Resources)
+	var prefixes []PrefixCoverage
+	for _, pc := range report.Prefixes {
+		if pc.Resources == 0 {
+			continue
+		}
+		pc.Percent = percentOf(pc.Present, pc.Resources)
+
+		var fields []FieldCoverage
+		for _, fc := range pc.Fields {
+			if fc.Resources == 0 {
+				continue
+			}
+			fc.Percent = percentOf(fc.Present, fc.Resources)
+			fc.FilledPercent = percentOf(fc.Filled, fc.Resources)
+			fields = append(fields, fc)
+		}
+		pc.Fields = fields
+		prefixes = append(prefixes, pc)
+	}
+	report.Prefixes = prefixes
+
+	return report
+}
+
+// coverageSkeleton lists every prefix and field the schema declares, global rules first and then
+// resource types in alphabetical order, each in declaration order
+func (sv *SchemaValidator) coverageSkeleton() []PrefixCoverage {
+	ruleSets := []ResourceRules{sv.getApplicableRules("")}
+	types := make([]string, 0, len(sv.schema.ResourceTypes))
+	for resourceType := range sv.schema.ResourceTypes {
+		types = append(types, resourceType)
+	}
+	sort.Strings(types)
+	for _, resourceType := range types {
+		ruleSets = append(ruleSets, sv.schema.ResourceTypes[resourceType])
+	}
+
+	var skeleton []PrefixCoverage
+	prefixIndex := make(map[string]int)
+	for _, rules := range ruleSets {
+		for _, prefix := range rulePrefixes(rules) {
+			index, exists := prefixIndex[prefix]
+			if !exists {
+				index = len(skeleton)
+				prefixIndex[prefix] = index
+				skeleton = append(skeleton, PrefixCoverage{Prefix: prefix})
+			}
+			pc := &skeleton[index]
+			pc.Required = pc.Required || sv.isPrefixRequired(prefix, rules)
+
+			for _, field := range ruleFields(rules.PrefixRules[prefix]) {
+				found := false
+				for i := range pc.Fields {
+					if pc.Fields[i].Field == field.path {
+						pc.Fields[i].Required = pc.Fields[i].Required || field.required
+						found = true
+						break
+					}
+				}
+				if !found {
+					pc.Fields = append(pc.Fields, FieldCoverage{Field: field.path, Required: field.required})
+				}
+			}
+		}
+	}
+
+	return skeleton
+}
+
+// rulePrefixes returns the required prefixes followed by the other prefixes with rules
+func rulePrefixes(rules ResourceRules) []string {
+	prefixes := append([]string{}, rules.RequiredPrefixes...)
+	for _, prefix := range rules.OrderedPrefixes() {
+		if !containsString(prefixes, prefix) {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return prefixes
+}
+
+// ruleFields returns the required, optional and nested fields declared by a prefix rule
+func ruleFields(rule PrefixRule) []coverageField {
+	var fields []coverageField
+	add := func(path string, required bool) {
+		for _, field := range fields {
+			if field.path == path {
+				return
+			}
+		}
+		fields = append(fields, coverageField{path: path, required: required})
+	}
+
+	for _, field := range rule.RequiredFields {
+		add(field, true)
+	}
+	for _, field := range rule.OptionalFields {
+		add(field, false)
+	}
+	for _, nestedPath := range rule.OrderedNestedPaths() {
+		for _, field := range rule.NestedFields[nestedPath].RequiredFields {
+			add(nestedPath+"."+field, true)
+		}
+		for _, field := range rule.NestedFields[nestedPath].OptionalFields {
+			add(nestedPath+"."+field, false)
+		}
+	}
+	return fields
+}
+
+// annotationFieldValue returns the value at a dotted path in an annotation
+func annotationFieldValue(annotation parser.StructuredComment, path string) (interface{}, bool) {
+	current := annotation.Fields
+	parts := strings.Split(path, ".")
+	for i, part := range parts {
+		value, exists := current[part]
+		if !exists {
+			return nil, false
+		}
+		if i == len(parts)-1 {
+			return value, true
+		}
+		nested, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current = nested
+	}
+	return nil, false
+}
+
+// containsPlaceholder reports whether a value, or any item or nested value in it, is a placeholder
+func (sv *SchemaValidator) containsPlaceholder(value interface{}) bool {
+	switch val := value.(type) {
+	case map[string]interface{}:
+		for _, item := range val {
+			if sv.containsPlaceholder(item) {
+				return true
+			}
+		}
+		return false
+	case []interface{}:
+		for _, item := range val {
+			if sv.containsPlaceholder(item) {
+				return true
+			}
+		}
+		return false
+	}
+	return sv.IsPlaceholder(value)
+}
+
+// CompareCoverage returns a description of every place where current coverage is lower than
+// baseline coverage. Prefixes and fields missing from either report are ignored.
+func CompareCoverage(baseline, current CoverageReport) []string {
+	var drops []string
+	check := func(name string, before, after float64) {
+		if after < before {
+			drops = append(drops, fmt.Sprintf("%s: %.1f%% -> %.1f%%", name, before, after))
+		}
+	}
+
+	check("annotated resources", baseline.Percent, current.Percent)
+
+	for _, pc := range current.Prefixes {
+		var base *PrefixCoverage
+		for i := range baseline.Prefixes {
+			if baseline.Prefixes[i].Prefix == pc.Prefix {
+				base = &baseline.Prefixes[i]
+				break
+			}
+		}
+		if base == nil {
+			continue
+		}
+		check(pc.Prefix, base.Percent, pc.Percent)
+
+		for _, fc := range pc.Fields {
+			for _, baseField := range base.Fields {
+				if baseField.Field != fc.Field {
+					continue
+				}
+				name := pc.Prefix + ":" + fc.Field
+				check(name, baseField.Percent, fc.Percent)
+				check(name+" (non-placeholder)", baseField.FilledPercent, fc.FilledPercent)
+			}
+		}
+	}
+
+	return drops
+}
+
+// percentOf returns n as a percentage of total, or 0 when total is 0
+func percentOf(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) * 100 / float64(total)
+}
+
+// containsString checks if a string slice contains a specific string
+func containsString(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
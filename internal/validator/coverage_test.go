@@ -0,0 +1,141 @@
+package validator
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/toozej/terranotate/internal/parser"
+)
+
+func coverageTestSchema() ValidationSchema {
+	return ValidationSchema{
+		Global: GlobalRules{
+			RequiredPrefixes: []string{"@metadata"},
+			PrefixRules: map[string]PrefixRule{
+				"@metadata": {
+					RequiredFields: []string{"owner"},
+					OptionalFields: []string{"team"},
+					NestedFields: map[string]NestedRule{
+						"contact": {RequiredFields: []string{"email"}},
+					},
+				},
+			},
+		},
+		ResourceTypes: map[string]ResourceRules{
+			"aws_instance": {
+				RequiredPrefixes: []string{"@metadata", "@validation"},
+				PrefixRules: map[string]PrefixRule{
+					"@metadata": {RequiredFields: []string{"owner"}},
+				},
+			},
+		},
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	sv, err := NewSchemaValidatorFromSchema(nil, coverageTestSchema())
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+
+	resources := []parser.TerraformResource{
+		{Type: "aws_vpc", Name: "main", PrecedingComments: []parser.StructuredComment{
+			{Prefix: "@metadata", Fields: map[string]interface{}{
+				"owner":   "net",
+				"contact": map[string]interface{}{"email": "CHANGEME"},
+			}},
+		}},
+		{Type: "aws_vpc", Name: "legacy"},
+		{Type: "aws_instance", Name: "web", PrecedingComments: []parser.StructuredComment{
+			{Prefix: "@metadata", Fields: map[string]interface{}{"owner": "CHANGEME", "team": "web"}},
+		}},
+	}
+
+	report := sv.Coverage(resources)
+
+	if report.Resources != 3 || report.Annotated != 1 {
+		t.Errorf("Expected 1/3 annotated resources, got %d/%d", report.Annotated, report.Resources)
+	}
+
+	expected := []PrefixCoverage{
+		{Prefix: "@metadata", Required: true, Resources: 3, Present: 2, Percent: 200.0 / 3, Fields: []FieldCoverage{
+			{Field: "owner", Required: true, Resources: 3, Present: 2, Filled: 1, Percent: 200.0 / 3, FilledPercent: 100.0 / 3},
+			// team and contact.email are only declared by the global rules, which aws_instance does not use
+			{Field: "team", Resources: 2, Present: 0, Filled: 0, Percent: 0, FilledPercent: 0},
+			{Field: "contact.email", Required: true, Resources: 2, Present: 1, Filled: 0, Percent: 50, FilledPercent: 0},
+		}},
+		{Prefix: "@validation", Required: true, Resources: 1, Present: 0, Percent: 0},
+	}
+	if !reflect.DeepEqual(report.Prefixes, expected) {
+		t.Errorf("Unexpected prefix coverage:\n got: %+v\nwant: %+v", report.Prefixes, expected)
+	}
+}
+
+func TestCoverageCountsInheritedAnnotations(t *testing.T) {
+	sv, err := NewSchemaValidatorFromSchema(nil, coverageTestSchema())
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+
+	// owner comes from the resource, team and contact.email from a module call
+	resources := []parser.TerraformResource{
+		{Type: "aws_vpc", Name: "main",
+			PrecedingComments: []parser.StructuredComment{
+				{Prefix: "@metadata", Fields: map[string]interface{}{"owner": "net"}},
+			},
+			Inherited: []parser.StructuredComment{
+				{Prefix: "@metadata", Fields: map[string]interface{}{
+					"team":    "network",
+					"contact": map[string]interface{}{"email": "net@example.com"},
+				}, Source: "module call module.net (main.tf:2)"},
+			},
+		},
+		{Type: "aws_vpc", Name: "defaults", Inherited: []parser.StructuredComment{
+			{Prefix: "@metadata", Fields: map[string]interface{}{"owner": "platform"}, Source: "directory defaults"},
+		}},
+	}
+
+	report := sv.Coverage(resources)
+
+	if report.Annotated != 2 {
+		t.Errorf("Expected inherited prefixes to count as annotated, got %d/%d", report.Annotated, report.Resources)
+	}
+	expected := []FieldCoverage{
+		{Field: "owner", Required: true, Resources: 2, Present: 2, Filled: 2, Percent: 100, FilledPercent: 100},
+		{Field: "team", Resources: 2, Present: 1, Filled: 1, Percent: 50, FilledPercent: 50},
+		{Field: "contact.email", Required: true, Resources: 2, Present: 1, Filled: 1, Percent: 50, FilledPercent: 50},
+	}
+	if len(report.Prefixes) != 1 || !reflect.DeepEqual(report.Prefixes[0].Fields, expected) {
+		t.Errorf("Unexpected field coverage:\n got: %+v\nwant: %+v", report.Prefixes, expected)
+	}
+}
+
+func TestCompareCoverage(t *testing.T) {
+	baseline := CoverageReport{
+		Percent: 50,
+		Prefixes: []PrefixCoverage{
+			{Prefix: "@metadata", Percent: 80, Fields: []FieldCoverage{
+				{Field: "owner", Percent: 80, FilledPercent: 60},
+			}},
+			{Prefix: "@docs", Percent: 100},
+		},
+	}
+
+	current := baseline
+	current.Prefixes = []PrefixCoverage{
+		{Prefix: "@metadata", Percent: 90, Fields: []FieldCoverage{
+			{Field: "owner", Percent: 80, FilledPercent: 40},
+			{Field: "team", Percent: 0},
+		}},
+		{Prefix: "@config", Percent: 0},
+	}
+
+	drops := CompareCoverage(baseline, current)
+	if !reflect.DeepEqual(drops, []string{"@metadata:owner (non-placeholder): 60.0% -> 40.0%"}) {
+		t.Errorf("Unexpected drops: %v", drops)
+	}
+
+	if drops := CompareCoverage(baseline, baseline); len(drops) != 0 {
+		t.Errorf("Expected no drops against itself, got %v", drops)
+	}
+}
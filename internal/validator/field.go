@@ -0,0 +1,399 @@
+package validator
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/toozej/terranotate/internal/parser"
+)
+
+// FieldValidation defines type and value constraints for fields
+type FieldValidation struct {
+	// Type is one of string, boolean, integer, float, number (integer or float), array, map
+	// or enum (any value from allowed_values), or a format name as shorthand for a string
+	// with that format
+	Type          string   `yaml:"type"`
+	Format        string   `yaml:"format"` // One of Formats
+	AllowedValues []string `yaml:"allowed_values"`
+	Pattern       string   `yaml:"pattern"`
+	MinLength     int      `yaml:"min_length"`
+	MaxLength     int      `yaml:"max_length"`
+	// Numeric bounds are unset when nil, so zero and negative bounds can be expressed
+	Min          *float64 `yaml:"min"`
+	Max          *float64 `yaml:"max"`
+	ExclusiveMin *float64 `yaml:"exclusive_min"`
+	ExclusiveMax *float64 `yaml:"exclusive_max"`
+	// Array and map size, and array element constraints
+	MinItems    int              `yaml:"min_items"`
+	MaxItems    int              `yaml:"max_items"`
+	UniqueItems bool             `yaml:"unique_items"`
+	Items       *FieldValidation `yaml:"items"`
+}
+
+// Built-in string formats
+const (
+	FormatEmail    = "email"
+	FormatURL      = "url"
+	FormatDate     = "date"     // 2006-01-02
+	FormatDateTime = "datetime" // RFC 3339, e.g. 2006-01-02T15:04:05Z
+	FormatDuration = "duration" // Go durations plus days and weeks, e.g. 90s, 1h30m, 7d
+	FormatSemver   = "semver"   // Semantic version, optionally prefixed with "v"
+	FormatSlack    = "slack"    // Slack user (@name) or channel (#name)
+)
+
+// Formats lists the built-in string formats
+var Formats = []string{FormatEmail, FormatURL, FormatDate, FormatDateTime, FormatDuration, FormatSemver, FormatSlack}
+
+var (
+	emailRegex    = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+	durationRegex = regexp.MustCompile(`^(\d+(\.\d+)?(ns|us|µs|ms|s|m|h|d|w))+$`)
+	semverRegex   = regexp.MustCompile(`^v?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(-[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*)?(\+[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*)?$`)
+	slackRegex    = regexp.MustCompile(`^[@#][a-z0-9][a-z0-9._-]*$`)
+)
+
+// FieldValidationFor returns the validation for a field at a dotted path under a prefix. Keys
+// are tried from most to least specific: "prefix:path", "path", "prefix:name" and "name", where
+// name is the last element of the path.
+func (s ValidationSchema) FieldValidationFor(prefix, path string) (FieldValidation, bool) {
+	name := path
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		name = path[i+1:]
+	}
+
+	for _, key := range []string{prefix + ":" + path, path, prefix + ":" + name, name} {
+		if validation, exists := s.FieldValidations[key]; exists {
+			return validation, true
+		}
+	}
+	return FieldValidation{}, false
+}
+
+// Types lists the field types, besides the format names accepted as shorthand
+var Types = []string{"string", "boolean", "integer", "float", "number", "array", "map", "enum"}
+
+// check reports constraints that can never be satisfied or are not understood
+func (validation FieldValidation) check() error {
+	if format := validation.format(); format != "" && !matchesKnownFormat(format) {
+		return fmt.Errorf("unknown format '%s' (supported: %s)", format, strings.Join(Formats, ", "))
+	}
+	if validation.Type != "" && validation.format() != validation.Type && !isKnownType(validation.Type) {
+		return fmt.Errorf("unknown type '%s' (supported: %s, or a format)", validation.Type, strings.Join(Types, ", "))
+	}
+	if validation.Type == "enum" && len(validation.AllowedValues) == 0 {
+		return fmt.Errorf("type 'enum' requires allowed_values")
+	}
+	if validation.Pattern != "" {
+		if _, err := regexp.Compile(validation.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern '%s': %w", validation.Pattern, err)
+		}
+	}
+	if validation.Items != nil {
+		if err := validation.Items.check(); err != nil {
+			return fmt.Errorf("items: %w", err)
+		}
+	}
+	return nil
+}
+
+// isKnownType reports whether typ is one of Types
+func isKnownType(typ string) bool {
+	for _, known := range Types {
+		if typ == known {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesKnownFormat reports whether format is one of Formats
+func matchesKnownFormat(format string) bool {
+	for _, known := range Formats {
+		if format == known {
+			return true
+		}
+	}
+	return false
+}
+
+// FieldType returns the declared type of a field for coercing its value while parsing. It is a
+// parser.TypeResolver; fields without a declared type or with a map type are not coerced.
+func (s ValidationSchema) FieldType(prefix, path string) (parser.FieldType, bool) {
+	validation, exists := s.FieldValidationFor(prefix, path)
+	if !exists {
+		return parser.FieldType{}, false
+	}
+
+	fieldType := parser.FieldType{Type: validation.parserType()}
+	if validation.Items != nil {
+		fieldType.Items = validation.Items.parserType()
+	}
+	return fieldType, fieldType.Type != ""
+}
+
+// parserType returns the type values are coerced to while parsing, or "" to infer it
+func (validation FieldValidation) parserType() string {
+	if validation.format() != "" {
+		return "string"
+	}
+	switch validation.Type {
+	case "string", "boolean", "integer", "float", "number", "array":
+		return validation.Type
+	case "enum":
+		return "string"
+	}
+	return ""
+}
+
+// FieldViolation describes a single constraint a field value failed
+type FieldViolation struct {
+	Rule    string
+	Message string
+}
+
+// format returns the string format the value must have, if any
+func (validation FieldValidation) format() string {
+	if validation.Format != "" {
+		return validation.Format
+	}
+	for _, format := range Formats {
+		if validation.Type == format {
+			return format
+		}
+	}
+	return ""
+}
+
+// CheckValue validates a single value against the field's type and value constraints.
+// Messages name the schema constraint that failed.
+func (validation FieldValidation) CheckValue(fieldName string, fieldValue interface{}) []FieldViolation {
+	var violations []FieldViolation
+	fail := func(rule, format string, args ...interface{}) {
+		violations = append(violations, FieldViolation{
+			Rule:    rule,
+			Message: fmt.Sprintf("Field '%s' ", fieldName) + fmt.Sprintf(format, args...),
+		})
+	}
+
+	format := validation.format()
+	typ := validation.Type
+	if format != "" && typ == format {
+		typ = "string"
+	}
+
+	// Type validation
+	switch typ {
+	case "string":
+		strVal, ok := fieldValue.(string)
+		if !ok {
+			fail(RuleFieldType, "must be a string, got %T", fieldValue)
+			return violations
+		}
+
+		if validation.MinLength > 0 && len(strVal) < validation.MinLength {
+			fail(RuleMinLength, "must be at least %d characters (min_length), got %d", validation.MinLength, len(strVal))
+		}
+		if validation.MaxLength > 0 && len(strVal) > validation.MaxLength {
+			fail(RuleMaxLength, "must be at most %d characters (max_length), got %d", validation.MaxLength, len(strVal))
+		}
+
+	case "boolean":
+		if _, ok := fieldValue.(bool); !ok {
+			fail(RuleFieldType, "must be a boolean, got %T", fieldValue)
+			return violations
+		}
+
+	case "integer":
+		if _, ok := fieldValue.(int); !ok {
+			fail(RuleFieldType, "must be an integer, got %T", fieldValue)
+			return violations
+		}
+
+	case "float":
+		if _, ok := fieldValue.(float64); !ok {
+			fail(RuleFieldType, "must be a float, got %T", fieldValue)
+			return violations
+		}
+
+	case "number":
+		if _, ok := toFloat(fieldValue); !ok {
+			fail(RuleFieldType, "must be a number, got %T", fieldValue)
+			return violations
+		}
+
+	case "array":
+		arrVal, ok := fieldValue.([]interface{})
+		if !ok {
+			fail(RuleFieldType, "must be an array, got %T", fieldValue)
+			return violations
+		}
+		violations = append(violations, validation.checkItems(fieldName, arrVal)...)
+
+	case "map":
+		mapVal, ok := fieldValue.(map[string]interface{})
+		if !ok {
+			fail(RuleFieldType, "must be a map of nested fields, got %T", fieldValue)
+			return violations
+		}
+		violations = append(violations, validation.checkSize(fieldName, len(mapVal), "keys")...)
+	}
+
+	// Pattern, format and allowed values apply to the value's text
+	if strVal, ok := fieldValue.(string); ok {
+		if validation.Pattern != "" {
+			matched, err := regexp.MatchString(validation.Pattern, strVal)
+			if err == nil && !matched {
+				fail(RulePattern, "value '%s' does not match required pattern '%s'", strVal, validation.Pattern)
+			}
+		}
+		if format != "" && !matchesFormat(format, strVal) {
+			fail(RuleFormat, "value '%s' is not a valid %s (format)", strVal, format)
+		}
+	}
+
+	if len(validation.AllowedValues) > 0 && !isCollection(fieldValue) {
+		text := fmt.Sprintf("%v", fieldValue)
+		found := false
+		for _, allowed := range validation.AllowedValues {
+			if text == allowed {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail(RuleAllowedValues, "value '%s' not in allowed values: %v", text, validation.AllowedValues)
+		}
+	}
+
+	if number, ok := toFloat(fieldValue); ok {
+		violations = append(violations, validation.checkBounds(fieldName, number)...)
+	}
+
+	return violations
+}
+
+// checkBounds validates a number against the inclusive and exclusive bounds
+func (validation FieldValidation) checkBounds(fieldName string, value float64) []FieldViolation {
+	var violations []FieldViolation
+	check := func(bound *float64, failed func(float64) bool, format string) {
+		if bound != nil && failed(*bound) {
+			violations = append(violations, FieldViolation{
+				Rule:    RuleRange,
+				Message: fmt.Sprintf("Field '%s' value %v "+format, fieldName, value, *bound),
+			})
+		}
+	}
+
+	check(validation.Min, func(bound float64) bool { return value < bound }, "is below minimum %v (min)")
+	check(validation.Max, func(bound float64) bool { return value > bound }, "exceeds maximum %v (max)")
+	check(validation.ExclusiveMin, func(bound float64) bool { return value <= bound }, "must be greater than %v (exclusive_min)")
+	check(validation.ExclusiveMax, func(bound float64) bool { return value >= bound }, "must be less than %v (exclusive_max)")
+
+	return violations
+}
+
+// checkSize validates the number of items of an array or keys of a map
+func (validation FieldValidation) checkSize(fieldName string, size int, noun string) []FieldViolation {
+	var violations []FieldViolation
+	if validation.MinItems > 0 && size < validation.MinItems {
+		violations = append(violations, FieldViolation{
+			Rule:    RuleMinItems,
+			Message: fmt.Sprintf("Field '%s' must have at least %d %s (min_items), got %d", fieldName, validation.MinItems, noun, size),
+		})
+	}
+	if validation.MaxItems > 0 && size > validation.MaxItems {
+		violations = append(violations, FieldViolation{
+			Rule:    RuleMaxItems,
+			Message: fmt.Sprintf("Field '%s' must have at most %d %s (max_items), got %d", fieldName, validation.MaxItems, noun, size),
+		})
+	}
+	return violations
+}
+
+// checkItems validates the size, uniqueness and elements of an array
+func (validation FieldValidation) checkItems(fieldName string, items []interface{}) []FieldViolation {
+	violations := validation.checkSize(fieldName, len(items), "items")
+
+	if validation.UniqueItems {
+		seen := make(map[string]bool)
+		for _, item := range items {
+			text := fmt.Sprintf("%v", item)
+			if seen[text] {
+				violations = append(violations, FieldViolation{
+					Rule:    RuleUniqueItems,
+					Message: fmt.Sprintf("Field '%s' has duplicate item '%s' (unique_items)", fieldName, text),
+				})
+				break
+			}
+			seen[text] = true
+		}
+	}
+
+	if validation.Items != nil {
+		for i, item := range items {
+			// Items given as text, e.g. quoted or built in code, are converted when typed
+			if text, ok := item.(string); ok && validation.Items.isNonString() {
+				item = parser.CoerceValue(text, parser.FieldType{Type: validation.Items.Type})
+			}
+			violations = append(violations, validation.Items.CheckValue(fmt.Sprintf("%s[%d]", fieldName, i), item)...)
+		}
+	}
+
+	return violations
+}
+
+// isNonString reports whether the validation expects booleans or numbers
+func (validation FieldValidation) isNonString() bool {
+	switch validation.Type {
+	case "boolean", "integer", "float", "number":
+		return true
+	}
+	return false
+}
+
+// matchesFormat reports whether a value has the given built-in format
+func matchesFormat(format, value string) bool {
+	switch format {
+	case FormatEmail:
+		return emailRegex.MatchString(value)
+	case FormatURL:
+		u, err := url.Parse(value)
+		return err == nil && u.Scheme != "" && u.Host != ""
+	case FormatDate:
+		_, err := time.Parse("2006-01-02", value)
+		return err == nil
+	case FormatDateTime:
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	case FormatDuration:
+		return durationRegex.MatchString(value)
+	case FormatSemver:
+		return semverRegex.MatchString(value)
+	case FormatSlack:
+		return slackRegex.MatchString(value)
+	}
+	// Unknown formats are rejected when the schema is loaded
+	return true
+}
+
+// toFloat converts integers and floats to float64
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// isCollection reports whether a value is an array or map, which allowed_values does not apply to
+func isCollection(value interface{}) bool {
+	switch value.(type) {
+	case []interface{}, map[string]interface{}:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func bound(value float64) *float64 {
+	return &value
+}
+
+func TestCheckValue(t *testing.T) {
+	tests := []struct {
+		name       string
+		validation FieldValidation
+		value      interface{}
+		rules      []string // Expected violation rules, in order
+	}{
+		{"string ok", FieldValidation{Type: "string", MinLength: 2, MaxLength: 5}, "abc", nil},
+		{"string too long", FieldValidation{Type: "string", MaxLength: 2}, "abc", []string{RuleMaxLength}},
+		{"string wrong type", FieldValidation{Type: "string"}, 3, []string{RuleFieldType}},
+		{"enum", FieldValidation{Type: "enum", AllowedValues: []string{"1", "2"}}, 3, []string{RuleAllowedValues}},
+		{"enum ok", FieldValidation{Type: "enum", AllowedValues: []string{"true"}}, true, nil},
+
+		{"min zero", FieldValidation{Type: "integer", Min: bound(0)}, -1, []string{RuleRange}},
+		{"negative max", FieldValidation{Type: "integer", Max: bound(-10)}, -5, []string{RuleRange}},
+		{"exclusive min", FieldValidation{Type: "float", ExclusiveMin: bound(0)}, 0.0, []string{RuleRange}},
+		{"exclusive max", FieldValidation{Type: "number", ExclusiveMax: bound(100)}, 100, []string{RuleRange}},
+		{"number in range", FieldValidation{Type: "number", Min: bound(0), ExclusiveMax: bound(100)}, 99.9, nil},
+
+		{"email", FieldValidation{Type: "email"}, "ops@example.com", nil},
+		{"email invalid", FieldValidation{Type: "email"}, "ops", []string{RuleFormat}},
+		{"url", FieldValidation{Type: "string", Format: "url"}, "https://example.com/runbook", nil},
+		{"url invalid", FieldValidation{Format: "url"}, "example.com", []string{RuleFormat}},
+		{"date", FieldValidation{Type: "date"}, "2024-02-29", nil},
+		{"date invalid", FieldValidation{Type: "date"}, "2023-02-29", []string{RuleFormat}},
+		{"datetime", FieldValidation{Type: "datetime"}, "2024-01-15T10:00:00Z", nil},
+		{"datetime invalid", FieldValidation{Type: "datetime"}, "2024-01-15 10:00", []string{RuleFormat}},
+		{"duration", FieldValidation{Type: "duration"}, "1h30m", nil},
+		{"duration days", FieldValidation{Type: "duration"}, "7d", nil},
+		{"duration invalid", FieldValidation{Type: "duration"}, "soon", []string{RuleFormat}},
+		{"semver", FieldValidation{Type: "semver"}, "v1.2.3-rc.1+build.5", nil},
+		{"semver invalid", FieldValidation{Type: "semver"}, "1.2", []string{RuleFormat}},
+		{"slack", FieldValidation{Type: "slack"}, "#devops-alerts", nil},
+		{"slack invalid", FieldValidation{Type: "slack"}, "devops", []string{RuleFormat}},
+		{"format wrong type", FieldValidation{Type: "email"}, 42, []string{RuleFieldType}},
+
+		{"array max items", FieldValidation{Type: "array", MaxItems: 1}, []interface{}{"a", "b"}, []string{RuleMaxItems}},
+		{"array unique", FieldValidation{Type: "array", UniqueItems: true}, []interface{}{"a", "b", "a"}, []string{RuleUniqueItems}},
+		{"array items", FieldValidation{Type: "array", Items: &FieldValidation{Type: "integer", Min: bound(1)}}, []interface{}{"1", "0", "x"}, []string{RuleRange, RuleFieldType}},
+		{"array item formats", FieldValidation{Type: "array", Items: &FieldValidation{Type: "email"}}, []interface{}{"a@example.com"}, nil},
+		{"map", FieldValidation{Type: "map", MinItems: 2}, map[string]interface{}{"email": "a@example.com"}, []string{RuleMinItems}},
+		{"map wrong type", FieldValidation{Type: "map"}, "x", []string{RuleFieldType}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := tt.validation.CheckValue("field", tt.value)
+			var rules []string
+			for _, violation := range violations {
+				rules = append(rules, violation.Rule)
+			}
+			if strings.Join(rules, ",") != strings.Join(tt.rules, ",") {
+				t.Errorf("CheckValue(%v) rules = %v, expected %v (%v)", tt.value, rules, tt.rules, violations)
+			}
+		})
+	}
+}
+
+func TestCheckValue_MessagesNameConstraint(t *testing.T) {
+	validation := FieldValidation{Type: "integer", ExclusiveMin: bound(0), Max: bound(10)}
+	violations := validation.CheckValue("replicas", 0)
+	if len(violations) != 1 || !strings.Contains(violations[0].Message, "exclusive_min") {
+		t.Errorf("Expected message naming exclusive_min, got %v", violations)
+	}
+
+	violations = FieldValidation{Type: "string", MaxLength: 3}.CheckValue("team", "platform")
+	if len(violations) != 1 || !strings.Contains(violations[0].Message, "max_length") {
+		t.Errorf("Expected message naming max_length, got %v", violations)
+	}
+}
+
+func TestNewSchemaValidator_InvalidFieldValidation(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	tests := map[string]string{
+		"unknown format":       "field_validations:\n  contact:\n    format: phone\n",
+		"invalid item pattern": "field_validations:\n  tags:\n    type: array\n    items:\n      pattern: \"[\"\n",
+		"unknown type":         "field_validations:\n  owner:\n    type: strnig\n",
+		"unknown item type":    "field_validations:\n  tags:\n    type: array\n    items:\n      type: bool\n",
+		"enum without values":  "field_validations:\n  env:\n    type: enum\n",
+	}
+	for name, schema := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := afero.WriteFile(fs, "/schema.yaml", []byte(schema), 0644); err != nil {
+				t.Fatalf("failed to write schema file: %v", err)
+			}
+			if _, err := NewSchemaValidator(fs, "/schema.yaml"); err == nil {
+				t.Error("Expected error for invalid field validation")
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package validator
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Migration describes how to rewrite annotations written for the previous schema
+// version so they conform to Version. Within a migration, renames run first, then
+// moves, splits and finally value mappings (which use the migrated field names).
+type Migration struct {
+	Version     int                          `yaml:"version"`     // Schema version this migration upgrades to
+	Description string                       `yaml:"description"` // Human-readable summary
+	Prefix      string                       `yaml:"prefix"`      // Comment prefix to rewrite (default "@metadata")
+	Rename      map[string]string            `yaml:"rename"`      // Old field name -> new field name
+	Move        map[string]string            `yaml:"move"`        // Old dotted path -> new dotted path
+	Split       []SplitRule                  `yaml:"split"`       // Fields whose value is split into several fields
+	MapValues   map[string]map[string]string `yaml:"map_values"`  // Field -> old value -> new value
+}
+
+// SplitRule splits a field's value on a separator into several new fields
+type SplitRule struct {
+	Field     string   `yaml:"field"`
+	Separator string   `yaml:"separator"`
+	Into      []string `yaml:"into"`
+}
+
+// DefaultMigrationPrefix is the comment prefix migrations rewrite when none is given
+const DefaultMigrationPrefix = "@metadata"
+
+// GetPrefix returns the migration's comment prefix, defaulting to @metadata
+func (m Migration) GetPrefix() string {
+	if m.Prefix == "" {
+		return DefaultMigrationPrefix
+	}
+	return m.Prefix
+}
+
+// PendingMigrations returns the migrations needed to bring annotations written for
+// schema version from up to the schema's version, in the order they must be applied
+func (s ValidationSchema) PendingMigrations(from int) ([]Migration, error) {
+	seen := make(map[int]bool)
+	for _, m := range s.Migrations {
+		if m.Version <= 0 {
+			return nil, fmt.Errorf("migration '%s' must declare a positive version", m.Description)
+		}
+		if seen[m.Version] {
+			return nil, fmt.Errorf("duplicate migration for version %d", m.Version)
+		}
+		seen[m.Version] = true
+		if s.Version > 0 && m.Version > s.Version {
+			return nil, fmt.Errorf("migration for version %d is newer than schema version %d", m.Version, s.Version)
+		}
+		for _, rule := range m.Split {
+			if rule.Field == "" || rule.Separator == "" || len(rule.Into) < 2 {
+				return nil, fmt.Errorf("migration %d: split of '%s' needs a field, a separator and at least two target fields", m.Version, rule.Field)
+			}
+		}
+	}
+
+	if s.Version > 0 && from > s.Version {
+		return nil, fmt.Errorf("annotations at version %d are newer than schema version %d", from, s.Version)
+	}
+
+	var pending []Migration
+	for _, m := range s.Migrations {
+		if m.Version > from {
+			pending = append(pending, m)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].Version < pending[j].Version })
+
+	return pending, nil
+}
@@ -0,0 +1,45 @@
+package validator
+
+import "testing"
+
+func TestPendingMigrations(t *testing.T) {
+	schema := ValidationSchema{
+		Version: 3,
+		Migrations: []Migration{
+			{Version: 3, Description: "rename cost center", Rename: map[string]string{"cost_center": "costcenter"}},
+			{Version: 2, Description: "move contact email", Move: map[string]string{"contact.email": "contact.primary.email"}},
+		},
+	}
+
+	pending, err := schema.PendingMigrations(0)
+	if err != nil {
+		t.Fatalf("PendingMigrations() failed: %v", err)
+	}
+	if len(pending) != 2 || pending[0].Version != 2 || pending[1].Version != 3 {
+		t.Errorf("expected migrations 2 and 3 in order, got %v", pending)
+	}
+	if pending[0].GetPrefix() != "@metadata" {
+		t.Errorf("expected default prefix @metadata, got %s", pending[0].GetPrefix())
+	}
+
+	pending, err = schema.PendingMigrations(2)
+	if err != nil || len(pending) != 1 || pending[0].Version != 3 {
+		t.Errorf("expected only migration 3 from version 2, got %v (err: %v)", pending, err)
+	}
+
+	if _, err := schema.PendingMigrations(4); err == nil {
+		t.Error("expected error when annotations are newer than the schema")
+	}
+
+	invalid := []ValidationSchema{
+		{Version: 2, Migrations: []Migration{{Version: 2}, {Version: 2}}},
+		{Version: 2, Migrations: []Migration{{Version: 3}}},
+		{Version: 2, Migrations: []Migration{{Version: 0}}},
+		{Version: 2, Migrations: []Migration{{Version: 2, Split: []SplitRule{{Field: "owner", Separator: ".", Into: []string{"first"}}}}}},
+	}
+	for i, s := range invalid {
+		if _, err := s.PendingMigrations(0); err == nil {
+			t.Errorf("case %d: expected error for invalid migrations", i)
+		}
+	}
+}
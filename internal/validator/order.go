@@ -0,0 +1,127 @@
+package validator
+
+import (
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UnmarshalYAML decodes global rules, recording the declaration order of prefix_rules
+func (g *GlobalRules) UnmarshalYAML(value *yaml.Node) error {
+	type plain GlobalRules
+	if err := value.Decode((*plain)(g)); err != nil {
+		return err
+	}
+	g.PrefixOrder = mappingKeys(value, "prefix_rules")
+	return nil
+}
+
+// UnmarshalYAML decodes resource rules, recording the declaration order of prefix_rules
+func (r *ResourceRules) UnmarshalYAML(value *yaml.Node) error {
+	type plain ResourceRules
+	if err := value.Decode((*plain)(r)); err != nil {
+		return err
+	}
+	r.PrefixOrder = mappingKeys(value, "prefix_rules")
+	return nil
+}
+
+// UnmarshalYAML decodes a prefix rule, recording the declaration order of nested_fields
+func (p *PrefixRule) UnmarshalYAML(value *yaml.Node) error {
+	type plain PrefixRule
+	if err := value.Decode((*plain)(p)); err != nil {
+		return err
+	}
+	p.NestedOrder = mappingKeys(value, "nested_fields")
+	return nil
+}
+
+// UnmarshalYAML decodes an attribute rule, recording the declaration order of prefix_rules
+func (a *AttributeRule) UnmarshalYAML(value *yaml.Node) error {
+	type plain AttributeRule
+	if err := value.Decode((*plain)(a)); err != nil {
+		return err
+	}
+	a.PrefixOrder = mappingKeys(value, "prefix_rules")
+	return nil
+}
+
+// OrderedPrefixes returns the prefixes of PrefixRules in schema declaration order
+func (g GlobalRules) OrderedPrefixes() []string {
+	keys := make([]string, 0, len(g.PrefixRules))
+	for prefix := range g.PrefixRules {
+		keys = append(keys, prefix)
+	}
+	return orderKeys(g.PrefixOrder, keys)
+}
+
+// OrderedPrefixes returns the prefixes of PrefixRules in schema declaration order
+func (r ResourceRules) OrderedPrefixes() []string {
+	keys := make([]string, 0, len(r.PrefixRules))
+	for prefix := range r.PrefixRules {
+		keys = append(keys, prefix)
+	}
+	return orderKeys(r.PrefixOrder, keys)
+}
+
+// OrderedPrefixes returns the prefixes of PrefixRules in schema declaration order
+func (a AttributeRule) OrderedPrefixes() []string {
+	keys := make([]string, 0, len(a.PrefixRules))
+	for prefix := range a.PrefixRules {
+		keys = append(keys, prefix)
+	}
+	return orderKeys(a.PrefixOrder, keys)
+}
+
+// OrderedNestedPaths returns the paths of NestedFields in schema declaration order
+func (p PrefixRule) OrderedNestedPaths() []string {
+	keys := make([]string, 0, len(p.NestedFields))
+	for path := range p.NestedFields {
+		keys = append(keys, path)
+	}
+	return orderKeys(p.NestedOrder, keys)
+}
+
+// mappingKeys returns the keys of the mapping stored under key in a YAML mapping node, in
+// document order
+func mappingKeys(node *yaml.Node, key string) []string {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != key || node.Content[i+1].Kind != yaml.MappingNode {
+			continue
+		}
+		child := node.Content[i+1]
+		keys := make([]string, 0, len(child.Content)/2)
+		for j := 0; j+1 < len(child.Content); j += 2 {
+			keys = append(keys, child.Content[j].Value)
+		}
+		return keys
+	}
+	return nil
+}
+
+// orderKeys sorts keys by their position in order. Keys without a recorded position, such as
+// those of schemas built in code, follow in alphabetical order.
+func orderKeys(order, keys []string) []string {
+	position := make(map[string]int, len(order))
+	for i, key := range order {
+		if _, exists := position[key]; !exists {
+			position[key] = i
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		pi, iok := position[keys[i]]
+		pj, jok := position[keys[j]]
+		switch {
+		case iok && jok:
+			return pi < pj
+		case iok != jok:
+			return iok
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
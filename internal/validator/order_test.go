@@ -0,0 +1,72 @@
+package validator
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestSchemaDeclarationOrder(t *testing.T) {
+	data := `
+global:
+  prefix_rules:
+    "@validation":
+      required_fields: [environment]
+    "@metadata":
+      required_fields: [owner]
+      nested_fields:
+        sla: {required_fields: [uptime]}
+        contact: {required_fields: [email]}
+resource_types:
+  aws_instance:
+    prefix_rules:
+      "@metadata": {required_fields: [owner]}
+      "@docs": {optional_fields: [description]}
+    docs:
+      columns: ["@metadata:owner"]
+docs:
+  columns: ["@metadata:team"]
+`
+	var schema ValidationSchema
+	if err := yaml.Unmarshal([]byte(data), &schema); err != nil {
+		t.Fatalf("failed to unmarshal schema: %v", err)
+	}
+
+	// Decode repeatedly: map iteration order must not leak into the result
+	for i := 0; i < 20; i++ {
+		if got := schema.Global.OrderedPrefixes(); !reflect.DeepEqual(got, []string{"@validation", "@metadata"}) {
+			t.Fatalf("Global.OrderedPrefixes() = %v", got)
+		}
+		if got := schema.Global.PrefixRules["@metadata"].OrderedNestedPaths(); !reflect.DeepEqual(got, []string{"sla", "contact"}) {
+			t.Fatalf("OrderedNestedPaths() = %v", got)
+		}
+		if got := schema.ResourceTypes["aws_instance"].OrderedPrefixes(); !reflect.DeepEqual(got, []string{"@metadata", "@docs"}) {
+			t.Fatalf("ResourceTypes.OrderedPrefixes() = %v", got)
+		}
+	}
+
+	if !reflect.DeepEqual(schema.Docs.Columns, []string{"@metadata:team"}) {
+		t.Errorf("Docs.Columns = %v", schema.Docs.Columns)
+	}
+	if !reflect.DeepEqual(schema.ResourceTypes["aws_instance"].Docs.Columns, []string{"@metadata:owner"}) {
+		t.Errorf("ResourceTypes docs.columns = %v", schema.ResourceTypes["aws_instance"].Docs.Columns)
+	}
+	if got := schema.Global.PrefixRules["@validation"].RequiredFields; !reflect.DeepEqual(got, []string{"environment"}) {
+		t.Errorf("Prefix rule fields not decoded: %v", got)
+	}
+}
+
+func TestOrderedPrefixesWithoutDeclarationOrder(t *testing.T) {
+	// Schemas built in code have no recorded order and fall back to sorted keys
+	rules := GlobalRules{PrefixRules: map[string]PrefixRule{"@metadata": {}, "@config": {}, "@docs": {}}}
+	if got := rules.OrderedPrefixes(); !reflect.DeepEqual(got, []string{"@config", "@docs", "@metadata"}) {
+		t.Errorf("OrderedPrefixes() = %v", got)
+	}
+
+	// Keys missing from a partial order follow the ordered ones
+	rules.PrefixOrder = []string{"@metadata"}
+	if got := rules.OrderedPrefixes(); !reflect.DeepEqual(got, []string{"@metadata", "@config", "@docs"}) {
+		t.Errorf("OrderedPrefixes() = %v", got)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/spf13/afero"
@@ -13,21 +14,84 @@ import (
 
 // ValidationSchema represents the complete validation schema
 type ValidationSchema struct {
+	Version          int                        `yaml:"version"`
 	Global           GlobalRules                `yaml:"global"`
 	ResourceTypes    map[string]ResourceRules   `yaml:"resource_types"`
+	AttributeRules   []AttributeRule            `yaml:"attribute_rules"`
 	FieldValidations map[string]FieldValidation `yaml:"field_validations"`
+	Placeholders     PlaceholderRules           `yaml:"placeholders"`
+	Migrations       []Migration                `yaml:"migrations"`
+	Docs             DocsConfig                 `yaml:"docs"`
+	Owners           OwnersConfig               `yaml:"owners"`
+	Fix              FixConfig                  `yaml:"fix"`
+}
+
+// Fix targets
+const (
+	FixTargetComments = "comments"
+	FixTargetSidecar  = "sidecar"
+)
+
+// FixConfig configures the fix command
+type FixConfig struct {
+	// Target is where missing annotations are written: "comments" (default) above the resource
+	// or "sidecar" in the sidecar file of the resource's directory
+	Target string `yaml:"target"`
+}
+
+// DocsConfig configures generated documentation
+type DocsConfig struct {
+	// Columns lists the fields shown as table columns ("prefix:field", dotted paths for
+	// nested fields). When empty, the required fields are shown.
+	Columns []string `yaml:"columns"`
+}
+
+// OwnersConfig configures ownership export to CODEOWNERS and ownership maps
+type OwnersConfig struct {
+	Field    string `yaml:"field"`    // @metadata field naming the owning team (default "team")
+	Org      string `yaml:"org"`      // GitHub organization teams belong to, e.g. teams become @org/team
+	Strategy string `yaml:"strategy"` // How conflicting owners are resolved: "majority" (default) or "all"
+	// Overrides assigns owners to module directories or files (relative to the repository root),
+	// replacing the owners derived from annotations there and below
+	Overrides map[string][]string `yaml:"overrides"`
+}
+
+// PlaceholderRules configures detection of placeholder values such as the
+// "CHANGEME" defaults inserted by the fixer
+type PlaceholderRules struct {
+	Values   []string `yaml:"values"`   // Exact values treated as placeholders
+	Patterns []string `yaml:"patterns"` // Regular expressions treated as placeholders
+	Severity string   `yaml:"severity"` // "error", "warning" (default) or "off"
+}
+
+// DefaultPlaceholderPatterns are used when the schema defines no placeholder patterns
+var DefaultPlaceholderPatterns = []string{
+	`(?i)changeme`,
+	`(?i)^(todo|tbd|fixme|xxx)$`,
 }
 
 // GlobalRules defines rules that apply to all resources
 type GlobalRules struct {
 	RequiredPrefixes []string              `yaml:"required_prefixes"`
 	PrefixRules      map[string]PrefixRule `yaml:"prefix_rules"`
+	PrefixOrder      []string              `yaml:"-"` // Declaration order of PrefixRules, recorded when decoding
+	// RepeatedPrefixes decides what happens when a resource has several comments with the same
+	// prefix: RepeatedMerge (default) combines them, RepeatedError reports every repeat
+	RepeatedPrefixes string `yaml:"repeated_prefixes"`
 }
 
+// Values of GlobalRules.RepeatedPrefixes
+const (
+	RepeatedMerge = "merge"
+	RepeatedError = "error"
+)
+
 // ResourceRules defines rules for a specific resource type
 type ResourceRules struct {
 	RequiredPrefixes []string              `yaml:"required_prefixes"`
 	PrefixRules      map[string]PrefixRule `yaml:"prefix_rules"`
+	PrefixOrder      []string              `yaml:"-"` // Declaration order of PrefixRules, recorded when decoding
+	Docs             DocsConfig            `yaml:"docs"`
 }
 
 // PrefixRule defines validation rules for a comment prefix
@@ -35,6 +99,7 @@ type PrefixRule struct {
 	RequiredFields []string              `yaml:"required_fields"`
 	OptionalFields []string              `yaml:"optional_fields"`
 	NestedFields   map[string]NestedRule `yaml:"nested_fields"`
+	NestedOrder    []string              `yaml:"-"` // Declaration order of NestedFields, recorded when decoding
 }
 
 // NestedRule defines validation for nested field structures
@@ -43,24 +108,50 @@ type NestedRule struct {
 	OptionalFields []string `yaml:"optional_fields"`
 }
 
-// FieldValidation defines type and value constraints for fields
-type FieldValidation struct {
-	Type          string   `yaml:"type"`
-	AllowedValues []string `yaml:"allowed_values"`
-	Pattern       string   `yaml:"pattern"`
-	MinLength     int      `yaml:"min_length"`
-	Min           float64  `yaml:"min"`
-	Max           float64  `yaml:"max"`
-	MinItems      int      `yaml:"min_items"`
-}
+// Rule identifiers reported on ValidationError.Rule
+const (
+	RuleMissingPrefix  = "missing-prefix"
+	RuleMissingField   = "missing-field"
+	RuleMissingNested  = "missing-nested"
+	RuleFieldType      = "field-type"
+	RulePattern        = "pattern"
+	RuleAllowedValues  = "allowed-values"
+	RuleMinLength      = "min-length"
+	RuleRange          = "range"
+	RuleMinItems       = "min-items"
+	RulePlaceholder    = "placeholder"
+	RuleFormat         = "format"
+	RuleMaxLength      = "max-length"
+	RuleMaxItems       = "max-items"
+	RuleUniqueItems    = "unique-items"
+	RuleDuplicateKey   = "duplicate-key"
+	RuleKeyConflict    = "key-conflict"
+	RuleRepeatedPrefix = "repeated-prefix"
+)
 
 // ValidationError represents a validation failure
 type ValidationError struct {
+	File         string // File the resource is defined in, when known
+	Module       string // Module address of the resource, e.g. "module.vpc"; "" in the root module
 	ResourceType string
 	ResourceName string
 	Line         int
 	Severity     string // "error" or "warning"
+	Rule         string // Stable rule identifier, e.g. "missing-field"
+	Field        string // Prefix, or "prefix:field" with a dotted path for nested fields
 	Message      string
+	// Sources explains the error: the sidecar file or inherited annotation an invalid value came
+	// from, or the sidecar and inherited annotations that were searched for a missing field
+	Sources []string
+}
+
+// Address returns the full address of the resource the error was found on, e.g.
+// "module.vpc.aws_subnet.private"
+func (e ValidationError) Address() string {
+	if e.Module == "" {
+		return e.ResourceType + "." + e.ResourceName
+	}
+	return e.Module + "." + e.ResourceType + "." + e.ResourceName
 }
 
 // ValidationResult contains all validation errors
@@ -72,8 +163,9 @@ type ValidationResult struct {
 
 // SchemaValidator handles schema-based validation
 type SchemaValidator struct {
-	fs     afero.Fs
-	schema ValidationSchema
+	fs           afero.Fs
+	schema       ValidationSchema
+	placeholders []*regexp.Regexp
 }
 
 // NewSchemaValidator creates a new validator from a schema file
@@ -99,7 +191,64 @@ func NewSchemaValidator(fs afero.Fs, schemaFile string) (*SchemaValidator, error
 		return nil, fmt.Errorf("failed to parse schema: %w", err)
 	}
 
-	return &SchemaValidator{fs: fs, schema: schema}, nil
+	return NewSchemaValidatorFromSchema(fs, schema)
+}
+
+// NewSchemaValidatorFromSchema creates a new validator from an already loaded schema
+func NewSchemaValidatorFromSchema(fs afero.Fs, schema ValidationSchema) (*SchemaValidator, error) {
+	if fs == nil {
+		fs = afero.NewOsFs()
+	}
+
+	patterns := schema.Placeholders.Patterns
+	if len(patterns) == 0 {
+		patterns = DefaultPlaceholderPatterns
+	}
+
+	var placeholders []*regexp.Regexp
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid placeholder pattern '%s': %w", pattern, err)
+		}
+		placeholders = append(placeholders, re)
+	}
+
+	switch schema.Placeholders.Severity {
+	case "", "error", "warning", "off":
+	default:
+		return nil, fmt.Errorf("invalid placeholders severity '%s' (expected 'error', 'warning' or 'off')", schema.Placeholders.Severity)
+	}
+
+	switch schema.Global.RepeatedPrefixes {
+	case "", RepeatedMerge, RepeatedError:
+	default:
+		return nil, fmt.Errorf("invalid repeated_prefixes '%s' (expected '%s' or '%s')", schema.Global.RepeatedPrefixes, RepeatedMerge, RepeatedError)
+	}
+
+	for i, rule := range schema.AttributeRules {
+		if err := rule.check(); err != nil {
+			return nil, fmt.Errorf("invalid attribute rule %d: %w", i+1, err)
+		}
+	}
+
+	keys := make([]string, 0, len(schema.FieldValidations))
+	for key := range schema.FieldValidations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := schema.FieldValidations[key].check(); err != nil {
+			return nil, fmt.Errorf("invalid field validation '%s': %w", key, err)
+		}
+	}
+
+	return &SchemaValidator{fs: fs, schema: schema, placeholders: placeholders}, nil
+}
+
+// Schema returns the schema the validator checks against
+func (sv *SchemaValidator) Schema() ValidationSchema {
+	return sv.schema
 }
 
 // ValidateResources validates all resources against the schema
@@ -109,9 +258,14 @@ func (sv *SchemaValidator) ValidateResources(resources []parser.TerraformResourc
 	}
 
 	for _, resource := range resources {
-		errors := sv.validateResource(resource)
-		result.Errors = append(result.Errors, errors...)
-		if len(errors) > 0 {
+		for _, err := range sv.validateResource(resource) {
+			err.File = resource.File
+			err.Module = resource.Module
+			if err.Severity == "warning" {
+				result.Warnings = append(result.Warnings, err)
+				continue
+			}
+			result.Errors = append(result.Errors, err)
 			result.Passed = false
 		}
 	}
@@ -129,25 +283,223 @@ func (sv *SchemaValidator) validateResource(resource parser.TerraformResource) [
 	// Check required prefixes
 	errors = append(errors, sv.checkRequiredPrefixes(resource, rules)...)
 
-	// Validate each prefix's fields
-	for prefix, prefixRule := range rules.PrefixRules {
+	// Validate each prefix's fields, with repeated comments of a prefix merged
+	for _, prefix := range rules.OrderedPrefixes() {
+		comment, exists := resource.GetAnnotation(prefix)
+		if !exists {
+			continue // Reported in checkRequiredPrefixes if the prefix is required
+		}
+		for _, err := range sv.validatePrefixFields(resource, comment, prefix, rules.PrefixRules[prefix]) {
+			if err.Rule == RuleMissingField || err.Rule == RuleMissingNested {
+				err.Sources = annotationSources(resource, prefix)
+			}
+			errors = append(errors, err)
+		}
+	}
+
+	// Report duplicate and conflicting keys, and repeated prefixes if they aren't merged
+	errors = append(errors, sv.checkKeys(resource)...)
+
+	// Validate annotations on attributes and nested blocks
+	errors = append(errors, sv.validateAttributes(resource)...)
+
+	// Validate field values of every annotation, including prefixes without rules. Values from
+	// sidecar files and inherited annotations are validated where they take effect.
+	for _, comment := range append(append([]parser.StructuredComment{}, resource.PrecedingComments...), resource.InlineComments...) {
+		errors = append(errors, sv.validateFieldValues(resource, comment, comment.Prefix)...)
+	}
+	errors = append(errors, sv.validateSourcedValues(resource)...)
+
+	// Report placeholder values left in any annotation
+	errors = append(errors, sv.checkPlaceholders(resource)...)
+
+	return errors
+}
+
+// checkKeys reports keys that were set twice or collide with a nested structure. Repeated
+// comments of a prefix are checked as one unless the schema forbids repeating prefixes, in which
+// case every repeat is reported.
+func (sv *SchemaValidator) checkKeys(resource parser.TerraformResource) []ValidationError {
+	var errors []ValidationError
+
+	var prefixes []string
+	seen := make(map[string]bool)
+	for _, comment := range resource.Comments() {
+		if !seen[comment.Prefix] {
+			seen[comment.Prefix] = true
+			prefixes = append(prefixes, comment.Prefix)
+		}
+	}
+
+	for _, prefix := range prefixes {
 		comments := resource.GetCommentsByPrefix(prefix)
-		if len(comments) == 0 {
-			// Only error if this prefix is required
-			if sv.isPrefixRequired(prefix, rules) {
-				continue // Already reported in checkRequiredPrefixes
+
+		var conflicts []parser.FieldConflict
+		if sv.schema.Global.RepeatedPrefixes == RepeatedError {
+			for i, comment := range comments {
+				conflicts = append(conflicts, comment.Conflicts...)
+				if i == 0 {
+					continue
+				}
+				errors = append(errors, ValidationError{
+					ResourceType: resource.Type,
+					ResourceName: resource.Name,
+					Line:         comment.Line,
+					Severity:     "error",
+					Rule:         RuleRepeatedPrefix,
+					Field:        prefix,
+					Message:      fmt.Sprintf("%s: Repeated annotation (first on line %d)", prefix, comments[0].Line),
+				})
 			}
-			continue
+		} else {
+			conflicts = parser.MergeComments(comments).Conflicts
 		}
 
-		for _, comment := range comments {
-			errors = append(errors, sv.validatePrefixFields(resource, comment, prefix, prefixRule)...)
+		for _, conflict := range conflicts {
+			rule := RuleDuplicateKey
+			if conflict.Kind == parser.ConflictNesting {
+				rule = RuleKeyConflict
+			}
+			errors = append(errors, ValidationError{
+				ResourceType: resource.Type,
+				ResourceName: resource.Name,
+				Line:         conflict.Line,
+				Severity:     "error",
+				Rule:         rule,
+				Field:        prefix + ":" + conflict.Field,
+				Message:      fmt.Sprintf("%s: %s", prefix, conflict.Message),
+			})
 		}
 	}
 
 	return errors
 }
 
+// PlaceholderFinding describes a single placeholder value found in an annotation
+type PlaceholderFinding struct {
+	Prefix string
+	Field  string // Dotted field path, e.g. "contact.email"
+	Value  string
+	Line   int
+	Source string // Sidecar file or inherited annotation the value comes from; "" for comments on the resource
+}
+
+// IsPlaceholder reports whether a field value matches the configured placeholder values or patterns
+func (sv *SchemaValidator) IsPlaceholder(value interface{}) bool {
+	strVal, ok := value.(string)
+	if !ok {
+		return false
+	}
+
+	for _, placeholder := range sv.schema.Placeholders.Values {
+		if strVal == placeholder {
+			return true
+		}
+	}
+
+	for _, re := range sv.placeholders {
+		if re.MatchString(strVal) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FindPlaceholders returns every placeholder value in a resource's annotations, including the
+// inherited values it doesn't override
+func (sv *SchemaValidator) FindPlaceholders(resource parser.TerraformResource) []PlaceholderFinding {
+	var findings []PlaceholderFinding
+
+	for _, comment := range resource.Comments() {
+		if comment.Source != "" {
+			// Sidecar lines are in another file, so findings point at the resource instead
+			comment.Line = resource.StartLine
+		}
+		findings = append(findings, sv.findPlaceholdersInFields(comment, "", comment.Fields)...)
+	}
+
+	for _, comment := range resource.Inherited {
+		annotation, _ := resource.GetAnnotation(comment.Prefix)
+		comment.Line = resource.StartLine
+		for _, finding := range sv.findPlaceholdersInFields(comment, "", comment.Fields) {
+			if annotation.Sources[finding.Field] == comment.Source {
+				findings = append(findings, finding)
+			}
+		}
+	}
+
+	return findings
+}
+
+// findPlaceholdersInFields walks nested fields and arrays looking for placeholder values
+func (sv *SchemaValidator) findPlaceholdersInFields(comment parser.StructuredComment, parentPath string, fields map[string]interface{}) []PlaceholderFinding {
+	var findings []PlaceholderFinding
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		if key != "_content" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		path := key
+		if parentPath != "" {
+			path = parentPath + "." + key
+		}
+
+		switch val := fields[key].(type) {
+		case map[string]interface{}:
+			findings = append(findings, sv.findPlaceholdersInFields(comment, path, val)...)
+		case []interface{}:
+			for _, item := range val {
+				if sv.IsPlaceholder(item) {
+					findings = append(findings, PlaceholderFinding{Prefix: comment.Prefix, Field: path, Value: fmt.Sprintf("%v", item), Line: comment.Line, Source: comment.Source})
+				}
+			}
+		default:
+			if sv.IsPlaceholder(val) {
+				findings = append(findings, PlaceholderFinding{Prefix: comment.Prefix, Field: path, Value: fmt.Sprintf("%v", val), Line: comment.Line, Source: comment.Source})
+			}
+		}
+	}
+
+	return findings
+}
+
+// checkPlaceholders reports placeholder values using the severity configured in the schema
+func (sv *SchemaValidator) checkPlaceholders(resource parser.TerraformResource) []ValidationError {
+	severity := sv.schema.Placeholders.Severity
+	if severity == "" {
+		severity = "warning"
+	}
+	if severity == "off" {
+		return nil
+	}
+
+	var errors []ValidationError
+	for _, finding := range sv.FindPlaceholders(resource) {
+		var sources []string
+		if finding.Source != "" {
+			sources = []string{finding.Source}
+		}
+		errors = append(errors, ValidationError{
+			ResourceType: resource.Type,
+			ResourceName: resource.Name,
+			Line:         finding.Line,
+			Severity:     severity,
+			Rule:         RulePlaceholder,
+			Field:        finding.Prefix + ":" + finding.Field,
+			Message:      fmt.Sprintf("%s: Field '%s' still has placeholder value '%s'", finding.Prefix, finding.Field, finding.Value),
+			Sources:      sources,
+		})
+	}
+
+	return errors
+}
+
 // getApplicableRules returns resource-specific rules or falls back to global
 func (sv *SchemaValidator) getApplicableRules(resourceType string) ResourceRules {
 	if rules, exists := sv.schema.ResourceTypes[resourceType]; exists {
@@ -158,6 +510,7 @@ func (sv *SchemaValidator) getApplicableRules(resourceType string) ResourceRules
 	return ResourceRules{
 		RequiredPrefixes: sv.schema.Global.RequiredPrefixes,
 		PrefixRules:      sv.schema.Global.PrefixRules,
+		PrefixOrder:      sv.schema.Global.PrefixOrder,
 	}
 }
 
@@ -176,13 +529,14 @@ func (sv *SchemaValidator) checkRequiredPrefixes(resource parser.TerraformResour
 	var errors []ValidationError
 
 	for _, requiredPrefix := range rules.RequiredPrefixes {
-		comments := resource.GetCommentsByPrefix(requiredPrefix)
-		if len(comments) == 0 {
+		if _, exists := resource.GetAnnotation(requiredPrefix); !exists {
 			errors = append(errors, ValidationError{
 				ResourceType: resource.Type,
 				ResourceName: resource.Name,
 				Line:         resource.StartLine,
 				Severity:     "error",
+				Rule:         RuleMissingPrefix,
+				Field:        requiredPrefix,
 				Message:      fmt.Sprintf("Missing required comment prefix: %s", requiredPrefix),
 			})
 		}
@@ -203,19 +557,18 @@ func (sv *SchemaValidator) validatePrefixFields(resource parser.TerraformResourc
 				ResourceName: resource.Name,
 				Line:         comment.Line,
 				Severity:     "error",
+				Rule:         RuleMissingField,
+				Field:        prefix + ":" + requiredField,
 				Message:      fmt.Sprintf("%s: Missing required field '%s'", prefix, requiredField),
 			})
 		}
 	}
 
 	// Validate nested fields
-	for nestedPath, nestedRule := range rule.NestedFields {
-		errors = append(errors, sv.validateNestedFields(resource, comment, prefix, nestedPath, nestedRule)...)
+	for _, nestedPath := range rule.OrderedNestedPaths() {
+		errors = append(errors, sv.validateNestedFields(resource, comment, prefix, nestedPath, rule.NestedFields[nestedPath])...)
 	}
 
-	// Validate field values
-	errors = append(errors, sv.validateFieldValues(resource, comment, prefix)...)
-
 	return errors
 }
 
@@ -243,6 +596,8 @@ func (sv *SchemaValidator) validateNestedFields(resource parser.TerraformResourc
 					ResourceName: resource.Name,
 					Line:         comment.Line,
 					Severity:     "error",
+					Rule:         RuleMissingNested,
+					Field:        prefix + ":" + nestedPath,
 					Message:      fmt.Sprintf("%s: Missing nested structure '%s'", prefix, nestedPath),
 				})
 			}
@@ -261,6 +616,8 @@ func (sv *SchemaValidator) validateNestedFields(resource parser.TerraformResourc
 					ResourceName: resource.Name,
 					Line:         comment.Line,
 					Severity:     "error",
+					Rule:         RuleMissingField,
+					Field:        prefix + ":" + fullPath,
 					Message:      fmt.Sprintf("%s: Missing required nested field '%s'", prefix, fullPath),
 				})
 			}
@@ -271,6 +628,8 @@ func (sv *SchemaValidator) validateNestedFields(resource parser.TerraformResourc
 					ResourceName: resource.Name,
 					Line:         comment.Line,
 					Severity:     "error",
+					Rule:         RuleMissingField,
+					Field:        prefix + ":" + nestedPath + "." + requiredField,
 					Message:      fmt.Sprintf("%s: Missing required field '%s.%s'", prefix, nestedPath, requiredField),
 				})
 			}
@@ -307,212 +666,181 @@ func (sv *SchemaValidator) fieldExists(fields map[string]interface{}, fieldPath
 
 // validateFieldValues validates field value constraints
 func (sv *SchemaValidator) validateFieldValues(resource parser.TerraformResource, comment parser.StructuredComment, prefix string) []ValidationError {
+	return sv.validateNestedFieldValues(resource, comment, prefix, "", comment.Fields)
+}
+
+// validateNestedFieldValues validates the values under a nested map, recursing into nested
+// maps so that every leaf is checked by its full path
+func (sv *SchemaValidator) validateNestedFieldValues(resource parser.TerraformResource, comment parser.StructuredComment, prefix, parentPath string, fields map[string]interface{}) []ValidationError {
 	var errors []ValidationError
 
-	for fieldName, fieldValue := range comment.Fields {
-		if fieldName == "_content" {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if parentPath == "" && key == "_content" {
 			continue
 		}
 
-		// Get validation rules for this field
-		validation, exists := sv.schema.FieldValidations[fieldName]
-		if !exists {
-			continue // No validation rules defined
+		path := key
+		if parentPath != "" {
+			path = parentPath + "." + key
+		}
+
+		// Get validation rules for this field, which apply to maps as a whole too
+		validation, exists := sv.schema.FieldValidationFor(prefix, path)
+		if exists {
+			errors = append(errors, sv.validateFieldValue(resource, comment, prefix, path, fields[key], validation)...)
 		}
 
-		errors = append(errors, sv.validateFieldValue(resource, comment, prefix, fieldName, fieldValue, validation)...)
+		if nested, ok := fields[key].(map[string]interface{}); ok {
+			errors = append(errors, sv.validateNestedFieldValues(resource, comment, prefix, path, nested)...)
+		}
 	}
 
 	return errors
 }
 
-// validateFieldValue validates a single field value
-func (sv *SchemaValidator) validateFieldValue(resource parser.TerraformResource, comment parser.StructuredComment, prefix, fieldName string, fieldValue interface{}, validation FieldValidation) []ValidationError {
+// validateSourcedValues validates the field values from sidecar files and inherited annotations
+// that take effect on a resource, reporting them on the resource along with their source
+func (sv *SchemaValidator) validateSourcedValues(resource parser.TerraformResource) []ValidationError {
 	var errors []ValidationError
 
-	// Type validation
-	switch validation.Type {
-	case "string":
-		strVal, ok := fieldValue.(string)
-		if !ok {
-			errors = append(errors, ValidationError{
-				ResourceType: resource.Type,
-				ResourceName: resource.Name,
-				Line:         comment.Line,
-				Severity:     "error",
-				Message:      fmt.Sprintf("%s: Field '%s' must be a string, got %T", prefix, fieldName, fieldValue),
-			})
-			return errors
+	var prefixes []string
+	seen := make(map[string]bool)
+	for _, comment := range append(append([]parser.StructuredComment{}, resource.SidecarComments...), resource.Inherited...) {
+		if !seen[comment.Prefix] {
+			seen[comment.Prefix] = true
+			prefixes = append(prefixes, comment.Prefix)
 		}
+	}
 
-		// Pattern validation
-		if validation.Pattern != "" {
-			matched, err := regexp.MatchString(validation.Pattern, strVal)
-			if err == nil && !matched {
-				errors = append(errors, ValidationError{
-					ResourceType: resource.Type,
-					ResourceName: resource.Name,
-					Line:         comment.Line,
-					Severity:     "error",
-					Message:      fmt.Sprintf("%s: Field '%s' value '%s' does not match required pattern '%s'", prefix, fieldName, strVal, validation.Pattern),
-				})
-			}
+	for _, prefix := range prefixes {
+		annotation, _ := resource.GetAnnotation(prefix)
+
+		paths := make([]string, 0, len(annotation.Sources))
+		for path := range annotation.Sources {
+			paths = append(paths, path)
 		}
+		sort.Strings(paths)
 
-		// Allowed values
-		if len(validation.AllowedValues) > 0 {
-			found := false
-			for _, allowed := range validation.AllowedValues {
-				if strVal == allowed {
-					found = true
-					break
-				}
+		comment := parser.StructuredComment{Prefix: prefix, Line: resource.StartLine}
+		for _, path := range paths {
+			validation, exists := sv.schema.FieldValidationFor(prefix, path)
+			if !exists {
+				continue
 			}
-			if !found {
-				errors = append(errors, ValidationError{
-					ResourceType: resource.Type,
-					ResourceName: resource.Name,
-					Line:         comment.Line,
-					Severity:     "error",
-					Message:      fmt.Sprintf("%s: Field '%s' value '%s' not in allowed values: %v", prefix, fieldName, strVal, validation.AllowedValues),
-				})
+			for _, err := range sv.validateFieldValue(resource, comment, prefix, path, resource.GetNestedField(prefix, path), validation) {
+				err.Sources = []string{annotation.Sources[path]}
+				errors = append(errors, err)
 			}
 		}
+	}
 
-		// Min length
-		if validation.MinLength > 0 && len(strVal) < validation.MinLength {
-			errors = append(errors, ValidationError{
-				ResourceType: resource.Type,
-				ResourceName: resource.Name,
-				Line:         comment.Line,
-				Severity:     "error",
-				Message:      fmt.Sprintf("%s: Field '%s' must be at least %d characters, got %d", prefix, fieldName, validation.MinLength, len(strVal)),
-			})
-		}
-
-	case "boolean":
-		if _, ok := fieldValue.(bool); !ok {
-			errors = append(errors, ValidationError{
-				ResourceType: resource.Type,
-				ResourceName: resource.Name,
-				Line:         comment.Line,
-				Severity:     "error",
-				Message:      fmt.Sprintf("%s: Field '%s' must be a boolean, got %T", prefix, fieldName, fieldValue),
-			})
-		}
-
-	case "integer":
-		intVal, ok := fieldValue.(int)
-		if !ok {
-			errors = append(errors, ValidationError{
-				ResourceType: resource.Type,
-				ResourceName: resource.Name,
-				Line:         comment.Line,
-				Severity:     "error",
-				Message:      fmt.Sprintf("%s: Field '%s' must be an integer, got %T", prefix, fieldName, fieldValue),
-			})
-			return errors
-		}
-
-		if validation.Min != 0 && float64(intVal) < validation.Min {
-			errors = append(errors, ValidationError{
-				ResourceType: resource.Type,
-				ResourceName: resource.Name,
-				Line:         comment.Line,
-				Severity:     "error",
-				Message:      fmt.Sprintf("%s: Field '%s' value %d is below minimum %v", prefix, fieldName, intVal, validation.Min),
-			})
-		}
-
-		if validation.Max != 0 && float64(intVal) > validation.Max {
-			errors = append(errors, ValidationError{
-				ResourceType: resource.Type,
-				ResourceName: resource.Name,
-				Line:         comment.Line,
-				Severity:     "error",
-				Message:      fmt.Sprintf("%s: Field '%s' value %d exceeds maximum %v", prefix, fieldName, intVal, validation.Max),
-			})
-		}
-
-	case "float":
-		floatVal, ok := fieldValue.(float64)
-		if !ok {
-			errors = append(errors, ValidationError{
-				ResourceType: resource.Type,
-				ResourceName: resource.Name,
-				Line:         comment.Line,
-				Severity:     "error",
-				Message:      fmt.Sprintf("%s: Field '%s' must be a float, got %T", prefix, fieldName, fieldValue),
-			})
-			return errors
-		}
-
-		if validation.Min != 0 && floatVal < validation.Min {
-			errors = append(errors, ValidationError{
-				ResourceType: resource.Type,
-				ResourceName: resource.Name,
-				Line:         comment.Line,
-				Severity:     "error",
-				Message:      fmt.Sprintf("%s: Field '%s' value %.2f is below minimum %.2f", prefix, fieldName, floatVal, validation.Min),
-			})
-		}
+	return errors
+}
 
-		if validation.Max != 0 && floatVal > validation.Max {
-			errors = append(errors, ValidationError{
-				ResourceType: resource.Type,
-				ResourceName: resource.Name,
-				Line:         comment.Line,
-				Severity:     "error",
-				Message:      fmt.Sprintf("%s: Field '%s' value %.2f exceeds maximum %.2f", prefix, fieldName, floatVal, validation.Max),
-			})
+// annotationSources returns the sources of a resource's sidecar and inherited annotations for a
+// prefix, in precedence order
+func annotationSources(resource parser.TerraformResource, prefix string) []string {
+	var sources []string
+	for _, comment := range append(append([]parser.StructuredComment{}, resource.SidecarComments...), resource.Inherited...) {
+		if comment.Prefix == prefix {
+			sources = append(sources, comment.Source)
 		}
+	}
+	return sources
+}
 
-	case "array":
-		arrVal, ok := fieldValue.([]interface{})
-		if !ok {
-			errors = append(errors, ValidationError{
-				ResourceType: resource.Type,
-				ResourceName: resource.Name,
-				Line:         comment.Line,
-				Severity:     "error",
-				Message:      fmt.Sprintf("%s: Field '%s' must be an array, got %T", prefix, fieldName, fieldValue),
-			})
-			return errors
-		}
+// validateFieldValue validates a single field value
+func (sv *SchemaValidator) validateFieldValue(resource parser.TerraformResource, comment parser.StructuredComment, prefix, fieldName string, fieldValue interface{}, validation FieldValidation) []ValidationError {
+	var errors []ValidationError
 
-		if validation.MinItems > 0 && len(arrVal) < validation.MinItems {
-			errors = append(errors, ValidationError{
-				ResourceType: resource.Type,
-				ResourceName: resource.Name,
-				Line:         comment.Line,
-				Severity:     "error",
-				Message:      fmt.Sprintf("%s: Field '%s' must have at least %d items, got %d", prefix, fieldName, validation.MinItems, len(arrVal)),
-			})
-		}
+	for _, violation := range validation.CheckValue(fieldName, fieldValue) {
+		errors = append(errors, ValidationError{
+			ResourceType: resource.Type,
+			ResourceName: resource.Name,
+			Line:         comment.Line,
+			Severity:     "error",
+			Rule:         violation.Rule,
+			Field:        prefix + ":" + fieldName,
+			Message:      fmt.Sprintf("%s: %s", prefix, violation.Message),
+		})
 	}
 
 	return errors
 }
 
+// PrintOptions configures how validation results are printed
+type PrintOptions struct {
+	Explain bool // Show where inherited values come from and which inherited annotations were searched
+}
+
 // PrintValidationResults prints validation results in a user-friendly format
 func PrintValidationResults(result ValidationResult) {
+	PrintValidationResultsWithOptions(result, PrintOptions{})
+}
+
+// PrintValidationResultsWithOptions prints validation results with the given options
+func PrintValidationResultsWithOptions(result ValidationResult, opts PrintOptions) {
 	if result.Passed {
 		fmt.Println("\n✅ All validation checks passed!")
+		PrintWarnings(result.Warnings, opts)
 		return
 	}
 
 	fmt.Println("\n❌ Validation failed with the following errors:")
 	fmt.Println(strings.Repeat("=", 80))
 
+	printGroupedByResource(append(append([]ValidationError{}, result.Errors...), result.Warnings...), opts)
+
+	fmt.Println(strings.Repeat("=", 80))
+	fmt.Printf("\nTotal errors: %d\n", len(result.Errors))
+	if len(result.Warnings) > 0 {
+		fmt.Printf("Total warnings: %d\n", len(result.Warnings))
+	}
+}
+
+// PrintWarnings prints the warnings of a validation that passed, grouped by resource
+func PrintWarnings(warnings []ValidationError, opts PrintOptions) {
+	if len(warnings) == 0 {
+		return
+	}
+	fmt.Printf("\n⚠️  %d warning(s):\n", len(warnings))
+	fmt.Println(strings.Repeat("=", 80))
+	printGroupedByResource(warnings, opts)
+	fmt.Println(strings.Repeat("=", 80))
+}
+
+// printGroupedByResource prints validation errors grouped by resource
+func printGroupedByResource(validationErrors []ValidationError, opts PrintOptions) {
 	// Group errors by resource
 	resourceErrors := make(map[string][]ValidationError)
-	for _, err := range result.Errors {
-		key := fmt.Sprintf("%s.%s", err.ResourceType, err.ResourceName)
+	var keys []string
+	for _, err := range validationErrors {
+		key := err.Address()
+		if err.File != "" {
+			key = fmt.Sprintf("%s (%s)", key, err.File)
+		}
+		if _, exists := resourceErrors[key]; !exists {
+			keys = append(keys, key)
+		}
 		resourceErrors[key] = append(resourceErrors[key], err)
 	}
 
-	// Print errors grouped by resource
-	for resource, errors := range resourceErrors {
+	// Print resources by file, then address
+	sort.SliceStable(keys, func(i, j int) bool {
+		a, b := resourceErrors[keys[i]][0], resourceErrors[keys[j]][0]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Address() < b.Address()
+	})
+	for _, resource := range keys {
+		errors := resourceErrors[resource]
 		fmt.Printf("\n🔴 %s\n", resource)
 		fmt.Println(strings.Repeat("-", 80))
 
@@ -525,10 +853,29 @@ func PrintValidationResults(result ValidationResult) {
 			}
 
 			fmt.Printf("  %s [%s] Line %d\n", icon, severity, err.Line)
-			fmt.Printf("     %s\n\n", err.Message)
+			fmt.Printf("     %s\n", err.Message)
+			if opts.Explain {
+				PrintExplanation(err)
+			}
+			fmt.Println()
 		}
 	}
+}
 
-	fmt.Println(strings.Repeat("=", 80))
-	fmt.Printf("\nTotal errors: %d\n", len(result.Errors))
+// PrintExplanation prints where the value an error is about came from, or for missing fields,
+// the sidecar and inherited annotations that were searched
+func PrintExplanation(err ValidationError) {
+	switch {
+	case err.Rule == RuleMissingField || err.Rule == RuleMissingNested:
+		if len(err.Sources) == 0 {
+			fmt.Println("     ↳ Not set on the resource, in a sidecar file or by an inherited annotation")
+			return
+		}
+		fmt.Println("     ↳ Not set on the resource or in:")
+		for _, source := range err.Sources {
+			fmt.Printf("         - %s\n", source)
+		}
+	case len(err.Sources) > 0:
+		fmt.Printf("     ↳ Value from %s\n", err.Sources[0])
+	}
 }
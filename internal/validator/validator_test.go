@@ -1,6 +1,9 @@
 package validator
 
 import (
+	"io"
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/spf13/afero"
@@ -162,6 +165,9 @@ func TestValidateResources_MissingPrefix(t *testing.T) {
 			if !contains(err.Message, "Missing required comment prefix") {
 				t.Errorf("Expected missing prefix error, got: %s", err.Message)
 			}
+			if err.Rule != RuleMissingPrefix || err.Field != "@metadata" {
+				t.Errorf("Expected rule %q for field @metadata, got %q for %q", RuleMissingPrefix, err.Rule, err.Field)
+			}
 		}
 	}
 
@@ -227,6 +233,9 @@ func TestValidateResources_MissingField(t *testing.T) {
 	for _, err := range result.Errors {
 		if contains(err.Message, "Missing required field") && contains(err.Message, "team") {
 			found = true
+			if err.Field != "@metadata:team" {
+				t.Errorf("Expected field @metadata:team, got %q", err.Field)
+			}
 		}
 	}
 
@@ -458,6 +467,416 @@ func TestValidateResources_NestedFields(t *testing.T) {
 	}
 }
 
+func TestValidateResources_NestedFieldValidations(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	schemaContent := `field_validations:
+  email:
+    type: string
+    pattern: "^[^@]+@example\\.com$"
+  "@config:monitoring.enabled":
+    type: boolean
+  "@validation:enabled":
+    type: string
+    allowed_values: ["yes", "no"]
+`
+	if err := afero.WriteFile(fs, "/schema.yaml", []byte(schemaContent), 0644); err != nil {
+		t.Fatalf("failed to write schema file: %v", err)
+	}
+
+	validator, err := NewSchemaValidator(fs, "/schema.yaml")
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+
+	resources := []parser.TerraformResource{
+		{
+			Type: "aws_vpc",
+			Name: "main",
+			PrecedingComments: []parser.StructuredComment{
+				{
+					Prefix: "@metadata",
+					Fields: map[string]interface{}{
+						"contact": map[string]interface{}{
+							"email": "ops@example.com",
+							"primary": map[string]interface{}{
+								"email": "not-an-email",
+							},
+						},
+					},
+				},
+				{
+					Prefix: "@config",
+					Fields: map[string]interface{}{
+						"monitoring": map[string]interface{}{"enabled": "sometimes"},
+					},
+				},
+				{
+					Prefix: "@validation",
+					Fields: map[string]interface{}{"enabled": "yes"},
+				},
+			},
+		},
+	}
+
+	result := validator.ValidateResources(resources)
+
+	fields := make(map[string]string)
+	for _, err := range result.Errors {
+		fields[err.Field] = err.Rule
+	}
+	if len(result.Errors) != 2 {
+		t.Errorf("Expected 2 errors, got %d: %v", len(result.Errors), result.Errors)
+	}
+	// The bare "email" rule applies at any depth
+	if fields["@metadata:contact.primary.email"] != RulePattern {
+		t.Errorf("Expected pattern error for @metadata:contact.primary.email, got %v", fields)
+	}
+	// Prefix-scoped rules only apply under their prefix
+	if fields["@config:monitoring.enabled"] != RuleFieldType {
+		t.Errorf("Expected type error for @config:monitoring.enabled, got %v", fields)
+	}
+}
+
+func TestFieldValidationFor(t *testing.T) {
+	schema := ValidationSchema{FieldValidations: map[string]FieldValidation{
+		"@metadata:contact.email": {Pattern: "scoped-path"},
+		"contact.email":           {Pattern: "path"},
+		"@docs:email":             {Pattern: "scoped-name"},
+		"email":                   {Pattern: "name"},
+	}}
+
+	tests := []struct {
+		prefix, path, expected string
+	}{
+		{"@metadata", "contact.email", "scoped-path"},
+		{"@config", "contact.email", "path"},
+		{"@docs", "owner.email", "scoped-name"},
+		{"@config", "email", "name"},
+	}
+	for _, tt := range tests {
+		validation, ok := schema.FieldValidationFor(tt.prefix, tt.path)
+		if !ok || validation.Pattern != tt.expected {
+			t.Errorf("FieldValidationFor(%q, %q) = %q, expected %q", tt.prefix, tt.path, validation.Pattern, tt.expected)
+		}
+	}
+
+	if _, ok := schema.FieldValidationFor("@metadata", "owner"); ok {
+		t.Error("Expected no validation for owner")
+	}
+}
+
+func TestValidateResources_Placeholders(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	schemaContent := `global:
+  required_prefixes:
+    - "@metadata"
+  prefix_rules:
+    "@metadata":
+      required_fields:
+        - owner
+`
+	err := afero.WriteFile(fs, "/schema.yaml", []byte(schemaContent), 0644)
+	if err != nil {
+		t.Fatalf("failed to write schema file: %v", err)
+	}
+
+	validator, err := NewSchemaValidator(fs, "/schema.yaml")
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+
+	resources := []parser.TerraformResource{
+		{
+			Type:      "aws_vpc",
+			Name:      "main",
+			StartLine: 2,
+			PrecedingComments: []parser.StructuredComment{
+				{
+					Prefix: "@metadata",
+					Line:   1,
+					Fields: map[string]interface{}{
+						"owner": "CHANGEME",
+						"contact": map[string]interface{}{
+							"email": "changeme@example.com",
+						},
+						"tags": []interface{}{"prod", "TODO"},
+					},
+				},
+			},
+		},
+	}
+
+	// Placeholders default to warnings and do not fail validation
+	result := validator.ValidateResources(resources)
+	if !result.Passed {
+		t.Errorf("Expected validation to pass with placeholder warnings, got errors: %v", result.Errors)
+	}
+	if len(result.Warnings) != 3 {
+		t.Fatalf("Expected 3 placeholder warnings, got %d: %v", len(result.Warnings), result.Warnings)
+	}
+	for _, w := range result.Warnings {
+		if w.Rule != RulePlaceholder {
+			t.Errorf("Expected rule %q, got %q", RulePlaceholder, w.Rule)
+		}
+	}
+
+	// Custom values, patterns and severity from the schema
+	schemaContent += `placeholders:
+  severity: error
+  values:
+    - TBD-OWNER
+  patterns:
+    - "^example"
+`
+	err = afero.WriteFile(fs, "/schema.yaml", []byte(schemaContent), 0644)
+	if err != nil {
+		t.Fatalf("failed to write schema file: %v", err)
+	}
+
+	validator, err = NewSchemaValidator(fs, "/schema.yaml")
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+
+	resources[0].PrecedingComments[0].Fields = map[string]interface{}{
+		"owner": "TBD-OWNER",
+		"team":  "example-team",
+		"other": "CHANGEME",
+	}
+
+	result = validator.ValidateResources(resources)
+	if result.Passed {
+		t.Error("Expected validation to fail with placeholder severity error")
+	}
+	if len(result.Errors) != 2 {
+		t.Errorf("Expected 2 placeholder errors, got %d: %v", len(result.Errors), result.Errors)
+	}
+
+	// Invalid placeholder pattern
+	err = afero.WriteFile(fs, "/bad.yaml", []byte("placeholders:\n  patterns: [\"(\"]\n"), 0644)
+	if err != nil {
+		t.Fatalf("failed to write schema file: %v", err)
+	}
+	if _, err := NewSchemaValidator(fs, "/bad.yaml"); err == nil {
+		t.Error("Expected error for invalid placeholder pattern")
+	}
+
+	// Unknown placeholder severity
+	err = afero.WriteFile(fs, "/bad.yaml", []byte("placeholders:\n  severity: warnn\n"), 0644)
+	if err != nil {
+		t.Fatalf("failed to write schema file: %v", err)
+	}
+	if _, err := NewSchemaValidator(fs, "/bad.yaml"); err == nil {
+		t.Error("Expected error for unknown placeholder severity")
+	}
+
+	// Custom values alone keep the default patterns
+	validator, err = NewSchemaValidatorFromSchema(nil, ValidationSchema{Placeholders: PlaceholderRules{Values: []string{"TBD-OWNER"}}})
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+	if !validator.IsPlaceholder("TBD-OWNER") || !validator.IsPlaceholder("CHANGEME") {
+		t.Error("Expected custom values to be added to the default patterns")
+	}
+}
+
+func TestFindPlaceholders_Inherited(t *testing.T) {
+	src := []byte(`# @metadata(file) team:CHANGEME env:TODO
+
+# @metadata owner:alice
+resource "aws_vpc" "main" {}
+
+# @metadata env:prod
+resource "aws_subnet" "a" {}
+`)
+	p := parser.NewCommentParser(afero.NewMemMapFs(), []string{"@metadata"})
+	resources, err := p.ParseSource("main.tf", src)
+	if err != nil {
+		t.Fatalf("ParseSource failed: %v", err)
+	}
+
+	validator, err := NewSchemaValidatorFromSchema(nil, ValidationSchema{})
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+
+	// Inherited placeholders are reported on each resource that doesn't override them, at the
+	// resource and with the file header as their source
+	expected := map[string][]string{
+		"main": {"env", "team"},
+		"a":    {"team"},
+	}
+	for _, resource := range resources {
+		findings := validator.FindPlaceholders(resource)
+		var fields []string
+		for _, finding := range findings {
+			fields = append(fields, finding.Field)
+			if finding.Source != "file header (main.tf:1)" || finding.Line != resource.StartLine {
+				t.Errorf("%s: %s found at line %d from %q, expected line %d from the file header", resource.Name, finding.Field, finding.Line, finding.Source, resource.StartLine)
+			}
+		}
+		if strings.Join(fields, ",") != strings.Join(expected[resource.Name], ",") {
+			t.Errorf("%s: placeholders %v, expected %v", resource.Name, fields, expected[resource.Name])
+		}
+	}
+
+	result := validator.ValidateResources(resources)
+	if len(result.Warnings) != 3 || len(result.Warnings[0].Sources) != 1 {
+		t.Errorf("Expected 3 placeholder warnings with their source, got %+v", result.Warnings)
+	}
+}
+
+func TestValidateResources_DuplicateKeysAndRepeatedPrefixes(t *testing.T) {
+	src := []byte(`
+# @metadata owner:a contact:x contact.email:y
+resource "aws_vpc" "main" {
+  # @metadata team:net owner:b
+  cidr_block = "10.0.0.0/16"
+}
+`)
+	p := parser.NewCommentParser(afero.NewMemMapFs(), []string{"@metadata"})
+	resources, err := p.ParseSource("main.tf", src)
+	if err != nil {
+		t.Fatalf("ParseSource failed: %v", err)
+	}
+
+	schema := ValidationSchema{
+		Global: GlobalRules{
+			PrefixRules: map[string]PrefixRule{
+				"@metadata": {RequiredFields: []string{"owner", "team"}},
+			},
+		},
+	}
+
+	// Repeated prefixes merge by default: team comes from the inline comment
+	validator, err := NewSchemaValidatorFromSchema(nil, schema)
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+	result := validator.ValidateResources(resources)
+	expected := []struct {
+		rule string
+		line int
+	}{
+		{RuleKeyConflict, 2},
+		{RuleDuplicateKey, 4},
+	}
+	if len(result.Errors) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(expected), len(result.Errors), result.Errors)
+	}
+	for i, want := range expected {
+		if got := result.Errors[i]; got.Rule != want.rule || got.Line != want.line {
+			t.Errorf("Error %d = %s on line %d, expected %s on line %d", i, got.Rule, got.Line, want.rule, want.line)
+		}
+	}
+
+	// Repeated prefixes can be forbidden instead
+	schema.Global.RepeatedPrefixes = RepeatedError
+	validator, err = NewSchemaValidatorFromSchema(nil, schema)
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+	result = validator.ValidateResources(resources)
+	var rules []string
+	for _, e := range result.Errors {
+		rules = append(rules, e.Rule)
+	}
+	if len(rules) != 2 || rules[0] != RuleRepeatedPrefix || rules[1] != RuleKeyConflict || result.Errors[0].Line != 4 {
+		t.Errorf("Expected a repeated-prefix error on line 4 and a key conflict, got %v", result.Errors)
+	}
+
+	schema.Global.RepeatedPrefixes = "ignore"
+	if _, err := NewSchemaValidatorFromSchema(nil, schema); err == nil {
+		t.Error("Expected error for invalid repeated_prefixes")
+	}
+}
+
+func TestValidateResources_InheritedAnnotations(t *testing.T) {
+	src := []byte(`# @metadata(file) team:net env:qa
+
+resource "aws_vpc" "main" {}
+
+# @metadata owner:alice env:prod
+resource "aws_subnet" "a" {}
+`)
+	p := parser.NewCommentParser(afero.NewMemMapFs(), []string{"@metadata"})
+	p.SetInheritanceResolver(func(filename string) []parser.StructuredComment {
+		return []parser.StructuredComment{{Prefix: "@metadata", Fields: map[string]interface{}{"cost": "x"}, Source: "directory defaults"}}
+	})
+	resources, err := p.ParseSource("main.tf", src)
+	if err != nil {
+		t.Fatalf("ParseSource failed: %v", err)
+	}
+
+	schema := ValidationSchema{
+		Global: GlobalRules{
+			RequiredPrefixes: []string{"@metadata"},
+			PrefixRules: map[string]PrefixRule{
+				"@metadata": {RequiredFields: []string{"owner", "team"}},
+			},
+		},
+		FieldValidations: map[string]FieldValidation{
+			"@metadata:env": {AllowedValues: []string{"prod", "staging"}},
+		},
+	}
+	validator, err := NewSchemaValidatorFromSchema(nil, schema)
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+	result := validator.ValidateResources(resources)
+
+	// The prefix is inherited, owner is missing everywhere, and the inherited env is invalid
+	// only where the resource doesn't override it
+	expected := []struct {
+		resource, rule string
+		sources        []string
+	}{
+		{"main", RuleMissingField, []string{"file header (main.tf:1)", "directory defaults"}},
+		{"main", RuleAllowedValues, []string{"file header (main.tf:1)"}},
+	}
+	if len(result.Errors) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d: %+v", len(expected), len(result.Errors), result.Errors)
+	}
+	for i, want := range expected {
+		got := result.Errors[i]
+		if got.ResourceName != want.resource || got.Rule != want.rule || strings.Join(got.Sources, ";") != strings.Join(want.sources, ";") {
+			t.Errorf("Error %d = %s %s from %v, expected %s %s from %v", i, got.ResourceName, got.Rule, got.Sources, want.resource, want.rule, want.sources)
+		}
+	}
+}
+
+func TestValidateResources_MapFieldValidation(t *testing.T) {
+	src := []byte(`# @metadata owner:alice contact.email:alice@example.com
+resource "aws_vpc" "main" {}
+
+# @metadata owner:bob contact.email:bob@example.com contact.slack:@bob
+resource "aws_subnet" "a" {}
+`)
+	p := parser.NewCommentParser(afero.NewMemMapFs(), []string{"@metadata"})
+	resources, err := p.ParseSource("main.tf", src)
+	if err != nil {
+		t.Fatalf("ParseSource failed: %v", err)
+	}
+
+	validator, err := NewSchemaValidatorFromSchema(nil, ValidationSchema{
+		FieldValidations: map[string]FieldValidation{
+			"contact":       {Type: "map", MinItems: 2},
+			"contact.email": {Type: "email"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+
+	// The map's own constraints apply, as well as those of its nested fields
+	result := validator.ValidateResources(resources)
+	if len(result.Errors) != 1 || result.Errors[0].ResourceName != "main" || result.Errors[0].Rule != RuleMinItems || result.Errors[0].Field != "@metadata:contact" {
+		t.Errorf("Expected one min_items error on main's contact map, got %+v", result.Errors)
+	}
+}
+
 func TestPrintValidationResults(t *testing.T) {
 	// This test just ensures the function doesn't panic
 	result := ValidationResult{
@@ -499,3 +918,38 @@ func findSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestPrintWarnings_SortedByFileAndAddress(t *testing.T) {
+	warnings := []ValidationError{
+		{ResourceType: "aws_vpc", ResourceName: "main", File: "b.tf", Severity: "warning", Message: "w1"},
+		{ResourceType: "aws_vpc", ResourceName: "main", File: "a.tf", Severity: "warning", Message: "w2"},
+		{ResourceType: "aws_subnet", ResourceName: "private", File: "a.tf", Severity: "warning", Message: "w3"},
+		{ResourceType: "aws_vpc", ResourceName: "main", Module: "module.network", File: "a.tf", Severity: "warning", Message: "w4"},
+	}
+
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+	os.Stdout = w
+	PrintWarnings(warnings, PrintOptions{})
+	os.Stdout = stdout
+	_ = w.Close()
+	out, _ := io.ReadAll(r)
+
+	want := []string{
+		"aws_subnet.private (a.tf)",
+		"aws_vpc.main (a.tf)",
+		"module.network.aws_vpc.main (a.tf)",
+		"aws_vpc.main (b.tf)",
+	}
+	last := -1
+	for _, header := range want {
+		index := strings.Index(string(out), "🔴 "+header+"\n")
+		if index <= last {
+			t.Fatalf("Expected %q after the previous resource:\n%s", header, out)
+		}
+		last = index
+	}
+}